@@ -19,6 +19,7 @@ import (
 	"gym_app/internal/app"
 	"gym_app/internal/config"
 	"gym_app/internal/lib/logger/handlers/slogpretty"
+	"gym_app/internal/lib/logger/sl"
 	"log/slog"
 	"os"
 	"os/signal"
@@ -32,33 +33,25 @@ const (
 )
 
 func main() {
-	ctx := context.Background()
-
 	cfg := config.MustLoad()
 
 	log := setupLogger(cfg.Env)
 
-	log.Info("starting application")
-
-	application := app.New(ctx, log, cfg)
-
-	application.Cron.Start(ctx)
-
-	log.Info("cron jobs started")
-	defer application.Cron.Stop()
-
-	go application.HTTPSrv.Run()
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
 
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
-
-	sign := <-stop
-
-	log.Info("stopping application", slog.String("signal", sign.String()))
+	log.Info("starting application")
 
-	application.HTTPSrv.Stop()
+	application, err := app.New(ctx, log, cfg)
+	if err != nil {
+		log.Error("failed to start application", sl.Error(err))
+		os.Exit(1)
+	}
 
-	log.Info("application stopped")
+	if err := application.Run(ctx); err != nil {
+		log.Error("application stopped with error", sl.Error(err))
+		os.Exit(1)
+	}
 }
 
 func setupLogger(env string) *slog.Logger {
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"gym_app/internal/models"
+	personService "gym_app/internal/services/person"
+	"strconv"
+)
+
+func peopleDuplicates(ctx context.Context, c *cli, args []string) error {
+	fs := newFlagSet("people duplicates")
+	gymID := gymFlag(fs)
+	similarity := fs.Float64("similarity", personService.DefaultSimilarity, "lowest name similarity from 0 to 1")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireGym(*gymID); err != nil {
+		return err
+	}
+	if *similarity < 0 || *similarity > 1 {
+		return fmt.Errorf("-similarity must be from 0 to 1")
+	}
+
+	groups, err := c.people.FindDuplicates(ctx, *gymID, *similarity)
+	if err != nil {
+		return err
+	}
+
+	var rows [][]string
+	for i, g := range groups {
+		for _, p := range g.People {
+			rows = append(rows, []string{
+				strconv.Itoa(i + 1),
+				g.Phone,
+				strconv.FormatFloat(g.Similarity, 'f', 2, 64),
+				strconv.Itoa(p.Id),
+				p.Name,
+			})
+		}
+	}
+
+	return c.out.print(groups, []string{"GROUP", "PHONE", "SIMILARITY", "ID", "NAME"}, rows)
+}
+
+func peopleMerge(ctx context.Context, c *cli, args []string) error {
+	fs := newFlagSet("people merge")
+	gymID := gymFlag(fs)
+
+	var merge models.PersonMerge
+	fs.IntVar(&merge.FromID, "from", 0, "ID of the duplicate, deleted after the merge (required)")
+	fs.IntVar(&merge.ToID, "to", 0, "ID of the person to keep (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireGym(*gymID); err != nil {
+		return err
+	}
+
+	if errs := merge.Validate(); errs != nil {
+		return errs
+	}
+
+	result, err := c.people.MergePeople(ctx, *gymID, merge.FromID, merge.ToID)
+	if err != nil {
+		return err
+	}
+
+	rows := [][]string{{
+		strconv.Itoa(result.PersonID),
+		strconv.Itoa(result.Memberships),
+		strconv.Itoa(result.Bookings),
+		strconv.Itoa(result.SkippedBookings),
+		strconv.Itoa(result.TrainingPackages),
+	}}
+
+	return c.out.print(result, []string{"PERSON ID", "MEMBERSHIPS", "BOOKINGS", "SKIPPED BOOKINGS", "TRAINING PACKAGES"}, rows)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"gym_app/internal/lib/spreadsheet"
+	"gym_app/internal/models"
+	"gym_app/internal/services/export"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func exportPeople(ctx context.Context, c *cli, args []string) error {
+	return exportTable(ctx, c, exportService.TablePeople, args)
+}
+
+func exportMemberships(ctx context.Context, c *cli, args []string) error {
+	return exportTable(ctx, c, exportService.TableMemberships, args)
+}
+
+func exportTrainingSales(ctx context.Context, c *cli, args []string) error {
+	return exportTable(ctx, c, exportService.TableTrainingSales, args)
+}
+
+// exportTable writes a table the same way as the export endpoints. The
+// format comes from -format, else from the file extension.
+func exportTable(ctx context.Context, c *cli, table string, args []string) error {
+	fs := newFlagSet("export " + table)
+	gymID := gymFlag(fs)
+	file := fs.String("file", "-", "output file, - for stdout")
+	format := fs.String("format", "", "csv or xlsx, by default the file extension or csv")
+
+	var filter models.ExportFilter
+	fs.StringVar(&filter.Name, "name", "", "only the person with this full name")
+	fs.Int64Var(&filter.PersonID, "person-id", 0, "only this person (training_sales)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireGym(*gymID); err != nil {
+		return err
+	}
+
+	if *format == "" {
+		*format = strings.TrimPrefix(filepath.Ext(*file), ".")
+	}
+	if *format == "" {
+		*format = spreadsheet.FormatCSV
+	}
+
+	sheetFormat, err := spreadsheet.Format(*format)
+	if err != nil {
+		return err
+	}
+
+	if *file == "-" {
+		return c.exports.Export(ctx, *gymID, table, sheetFormat, c.cfg.Locale.Default, filter, os.Stdout)
+	}
+
+	f, err := os.Create(*file)
+	if err != nil {
+		return err
+	}
+
+	if err := c.exports.Export(ctx, *gymID, table, sheetFormat, c.cfg.Locale.Default, filter, f); err != nil {
+		f.Close()
+		os.Remove(*file)
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,177 @@
+// Command gymctl runs bulk and maintenance operations directly through the
+// service layer, without the HTTP API and its admin cookie.
+//
+// Usage:
+//
+//	gymctl [-config path] [-o table|json] [-v] <command> <action> [flags] [args]
+//
+// The config is the one of the server: -config or CONFIG_PATH, with
+// environment overrides.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"gym_app/internal/config"
+	"gym_app/internal/lib/cardnumber"
+	"gym_app/internal/services/export"
+	"gym_app/internal/services/import"
+	personService "gym_app/internal/services/person"
+	personSubService "gym_app/internal/services/person_sub"
+	subscriptionService "gym_app/internal/services/subscription"
+	"gym_app/internal/storage/postgres"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// cli holds what the commands work with.
+type cli struct {
+	cfg     *config.Config
+	out     printer
+	storage *postgres.Storage
+
+	people      *personService.PersonService
+	plans       *subscriptionService.SubscriptionService
+	memberships *personSubService.PersonSubService
+	imports     *importService.ImportService
+	exports     *exportService.ExportService
+}
+
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(ctx context.Context, c *cli, args []string) error
+}
+
+var commands = []command{
+	{"people export", "-gym ID", "list the people of a gym", peopleExport},
+	{"people import", "-gym ID [-file people.json|.csv|.xlsx] [-mapping field=column,...] [-dry-run] [-report errors.csv]", "add people from a JSON array, stdin by default, or people and memberships from a spreadsheet", peopleImport},
+	{"people duplicates", "-gym ID [-similarity 0.8]", "list people with the same phone and similar names", peopleDuplicates},
+	{"people merge", "-gym ID -from ID -to ID", "move memberships, bookings and training packages to another person and delete the duplicate", peopleMerge},
+	{"export people", "-gym ID [-file people.xlsx] [-format csv|xlsx] [-name N]", "export people as CSV or XLSX, stdout by default", exportPeople},
+	{"export memberships", "-gym ID [-file memberships.xlsx] [-format csv|xlsx] [-name N]", "export memberships with their plans", exportMemberships},
+	{"export training_sales", "-gym ID [-file sales.xlsx] [-format csv|xlsx] [-person-id N]", "export sold personal training packages", exportTrainingSales},
+	{"plans list", "-gym ID", "list the subscription plans of a gym", plansList},
+	{"plans create", "-gym ID -title T -days N [-price P] [-freeze-days N] [-all-gyms]", "create a subscription plan", plansCreate},
+	{"memberships show", "-gym ID [-history] NUMBER...", "show memberships by card number", membershipsShow},
+	{"statuses recalc", "", "recalculate membership statuses of all gyms", statusesRecalc},
+	{"migrate up", "[-steps N]", "apply pending migrations", migrateUp},
+	{"migrate down", "-steps N | -all", "roll back migrations", migrateDown},
+	{"migrate version", "", "print the current schema version", migrateVersion},
+}
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	output := flag.String("o", "table", "output format: table or json")
+	verbose := flag.Bool("v", false, "log service calls to stderr")
+	flag.Usage = usage
+
+	cfg := config.MustLoad()
+
+	args := flag.Args()
+	if len(args) < 2 {
+		usage()
+		return 2
+	}
+
+	cmd, ok := findCommand(args[0], args[1])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "gymctl: unknown command %q\n", args[0]+" "+args[1])
+		usage()
+		return 2
+	}
+
+	out, err := newPrinter(os.Stdout, *output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gymctl: %s\n", err)
+		return 2
+	}
+
+	level := slog.LevelWarn
+	if *verbose {
+		level = slog.LevelDebug
+	}
+	log := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	storage, err := postgres.New(ctx, cfg.DB)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gymctl: connect to database: %s\n", err)
+		return 1
+	}
+	defer storage.Close()
+
+	c := &cli{
+		cfg:         cfg,
+		out:         out,
+		storage:     storage,
+		people:      personService.New(log, storage),
+		plans:       subscriptionService.New(log, storage),
+		memberships: personSubService.New(log, storage, cardnumber.New(cfg.Cards.Prefix, cfg.Cards.Digits)),
+	}
+	c.imports = importService.New(log, c.people, c.plans, c.memberships)
+	c.exports = exportService.New(log, storage)
+
+	if err := cmd.run(ctx, c, args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "gymctl %s: %s\n", cmd.name, err)
+		return 1
+	}
+
+	return 0
+}
+
+func findCommand(group, action string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == group+" "+action {
+			return cmd, true
+		}
+	}
+
+	return command{}, false
+}
+
+func usage() {
+	w := flag.CommandLine.Output()
+
+	fmt.Fprintf(w, "Usage: gymctl [flags] <command> <action> [flags] [args]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-22s %s\n", cmd.name, cmd.summary)
+		if cmd.args != "" {
+			fmt.Fprintf(w, "  %-22s   %s\n", "", cmd.args)
+		}
+	}
+
+	fmt.Fprintf(w, "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+// newFlagSet returns the flag set of a command. Parse errors are returned
+// instead of exiting.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("gymctl "+name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	return fs
+}
+
+// gymFlag adds the required -gym flag to fs.
+func gymFlag(fs *flag.FlagSet) *int64 {
+	return fs.Int64("gym", 0, "gym ID (required)")
+}
+
+func requireGym(gymID int64) error {
+	if gymID <= 0 {
+		return fmt.Errorf("-gym is required")
+	}
+
+	return nil
+}
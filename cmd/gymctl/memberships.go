@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gym_app/internal/models"
+	"strconv"
+	"time"
+)
+
+// membership is a membership with its card history, as shown by
+// memberships show -history.
+type membership struct {
+	models.PersonSubStrDate
+	History []models.CardNumberChange `json:"history,omitempty"`
+}
+
+func membershipsShow(ctx context.Context, c *cli, args []string) error {
+	fs := newFlagSet("memberships show")
+	gymID := gymFlag(fs)
+	history := fs.Bool("history", false, "include revoked card numbers")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireGym(*gymID); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("at least one card number is required")
+	}
+
+	var (
+		found []membership
+		errs  []error
+	)
+
+	for _, number := range fs.Args() {
+		sub, err := c.memberships.GetPersonSubByNumber(ctx, *gymID, number)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", number, err))
+			continue
+		}
+
+		m := membership{PersonSubStrDate: sub}
+		if *history {
+			m.History, err = c.memberships.FindCardHistory(ctx, *gymID, sub.Number)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", number, err))
+			}
+		}
+
+		found = append(found, m)
+	}
+
+	rows := make([][]string, 0, len(found))
+	for _, m := range found {
+		rows = append(rows, []string{
+			m.Number,
+			strconv.FormatInt(m.PersonID, 10),
+			strconv.FormatInt(m.SubscriptionID, 10),
+			m.StartDate,
+			m.EndDate,
+			m.Status,
+		})
+
+		for _, h := range m.History {
+			rows = append(rows, []string{
+				"  " + h.Number, "", "", "", "",
+				"revoked " + h.RevokedAt.Local().Format(time.DateTime) + ": " + h.Reason,
+			})
+		}
+	}
+
+	if err := c.out.print(found, []string{"NUMBER", "PERSON", "PLAN", "START", "END", "STATUS"}, rows); err != nil {
+		return err
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"gym_app/internal/storage/postgres"
+	"gym_app/migrations"
+	"strconv"
+)
+
+// migrationResult is a migration applied or rolled back by a command.
+type migrationResult struct {
+	Version int64  `json:"version"`
+	Name    string `json:"name"`
+}
+
+func migrateUp(ctx context.Context, c *cli, args []string) error {
+	fs := newFlagSet("migrate up")
+	steps := fs.Int("steps", 0, "apply at most N migrations, 0 for all")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	all, err := postgres.LoadMigrations(migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	applied, err := c.storage.MigrateUp(ctx, all, *steps)
+	if printErr := printMigrations(c, applied); printErr != nil {
+		return printErr
+	}
+
+	return err
+}
+
+func migrateDown(ctx context.Context, c *cli, args []string) error {
+	fs := newFlagSet("migrate down")
+	steps := fs.Int("steps", 0, "roll back N migrations")
+	all := fs.Bool("all", false, "roll back all migrations")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// Rolling back everything drops all data, so it has to be asked for
+	if (*steps > 0) == *all {
+		return errors.New("either -steps N or -all is required")
+	}
+
+	loaded, err := postgres.LoadMigrations(migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	reverted, err := c.storage.MigrateDown(ctx, loaded, *steps)
+	if printErr := printMigrations(c, reverted); printErr != nil {
+		return printErr
+	}
+
+	return err
+}
+
+func migrateVersion(ctx context.Context, c *cli, args []string) error {
+	fs := newFlagSet("migrate version")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	version, dirty, err := c.storage.MigrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	result := struct {
+		Version int64 `json:"version"`
+		Dirty   bool  `json:"dirty"`
+	}{version, dirty}
+
+	return c.out.print(result, []string{"VERSION", "DIRTY"}, [][]string{{
+		strconv.FormatInt(version, 10), strconv.FormatBool(dirty),
+	}})
+}
+
+func printMigrations(c *cli, done []postgres.Migration) error {
+	results := make([]migrationResult, 0, len(done))
+	rows := make([][]string, 0, len(done))
+
+	for _, m := range done {
+		results = append(results, migrationResult{Version: m.Version, Name: m.Name})
+		rows = append(rows, []string{strconv.FormatInt(m.Version, 10), m.Name})
+	}
+
+	return c.out.print(results, []string{"VERSION", "NAME"}, rows)
+}
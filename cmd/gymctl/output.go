@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+// printer writes command results either as an aligned table or as indented
+// JSON of the original values.
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+func newPrinter(w io.Writer, format string) (printer, error) {
+	if format != formatTable && format != formatJSON {
+		return printer{}, fmt.Errorf("unknown output format %q, use table or json", format)
+	}
+
+	return printer{w: w, format: format}, nil
+}
+
+// print writes v as JSON or header and rows as a table.
+func (p printer) print(v any, header []string, rows [][]string) error {
+	if p.format == formatJSON {
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(v)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gym_app/internal/lib/spreadsheet"
+	"gym_app/internal/lib/validation"
+	"gym_app/internal/models"
+	"gym_app/internal/services/import"
+	personService "gym_app/internal/services/person"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+func peopleExport(ctx context.Context, c *cli, args []string) error {
+	fs := newFlagSet("people export")
+	gymID := gymFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireGym(*gymID); err != nil {
+		return err
+	}
+
+	people, err := c.people.FindAllPeople(ctx, *gymID)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(people))
+	for _, p := range people {
+		rows = append(rows, []string{strconv.Itoa(p.Id), p.Name, p.Phone})
+	}
+
+	return c.out.print(people, []string{"ID", "NAME", "PHONE"}, rows)
+}
+
+// importResult is the outcome of one imported person.
+type importResult struct {
+	Index  int    `json:"index"`
+	Name   string `json:"name"`
+	Phone  string `json:"phone"`
+	ID     int    `json:"id,omitempty"`
+	Status string `json:"status"` // created, exists, invalid, failed or valid on a dry run
+	Error  string `json:"error,omitempty"`
+}
+
+func peopleImport(ctx context.Context, c *cli, args []string) error {
+	fs := newFlagSet("people import")
+	gymID := gymFlag(fs)
+	file := fs.String("file", "-", "JSON, CSV or XLSX file, - for stdin")
+	format := fs.String("format", "", "json, csv or xlsx, by default the file extension or json")
+	mapping := fs.String("mapping", "", "spreadsheet columns of the fields, e.g. name=ФИО,phone=Телефон")
+	report := fs.String("report", "", "write the spreadsheet rows that were not imported to this csv or xlsx file")
+	dryRun := fs.Bool("dry-run", false, "only validate the people")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireGym(*gymID); err != nil {
+		return err
+	}
+
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*file)), ".")
+	}
+	if *format == "" || *format == "json" {
+		return importJSON(ctx, c, *gymID, *file, *dryRun)
+	}
+
+	sheetFormat, err := spreadsheet.Format(*format)
+	if err != nil {
+		return err
+	}
+
+	opts := models.ImportOptions{DryRun: *dryRun}
+	if opts.Mapping, err = parseMapping(*mapping); err != nil {
+		return err
+	}
+
+	return importSpreadsheet(ctx, c, *gymID, *file, sheetFormat, opts, *report)
+}
+
+// importJSON adds people from a JSON array, as written by people export.
+func importJSON(ctx context.Context, c *cli, gymID int64, file string, dryRun bool) error {
+	people, err := readPeople(file)
+	if err != nil {
+		return err
+	}
+
+	results := make([]importResult, 0, len(people))
+	failed := 0
+
+	for i, person := range people {
+		res := importResult{Index: i + 1}
+
+		errs := person.Validate()
+		res.Name, res.Phone = person.Name, person.Phone
+
+		switch {
+		case errs != nil:
+			res.Status, res.Error = "invalid", errs.Error()
+		case dryRun:
+			res.Status = "valid"
+		default:
+			res.ID, err = c.people.AddPerson(ctx, gymID, person)
+			switch {
+			case errors.Is(err, personService.ErrPersonExists):
+				res.Status = "exists"
+			case err != nil:
+				res.Status, res.Error = "failed", err.Error()
+			default:
+				res.Status = "created"
+			}
+		}
+
+		if res.Status == "invalid" || res.Status == "failed" {
+			failed++
+		}
+
+		results = append(results, res)
+	}
+
+	rows := make([][]string, 0, len(results))
+	for _, res := range results {
+		id := ""
+		if res.ID != 0 {
+			id = strconv.Itoa(res.ID)
+		}
+		rows = append(rows, []string{strconv.Itoa(res.Index), res.Name, res.Phone, res.Status, id, res.Error})
+	}
+
+	if err := c.out.print(results, []string{"#", "NAME", "PHONE", "STATUS", "ID", "ERROR"}, rows); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d people were not imported", failed, len(people))
+	}
+
+	return nil
+}
+
+// importSpreadsheet adds people and their memberships from a CSV or XLSX
+// file the same way as the import endpoint.
+func importSpreadsheet(
+	ctx context.Context,
+	c *cli,
+	gymID int64,
+	file, format string,
+	opts models.ImportOptions,
+	report string,
+) error {
+	r, closeFile, err := openInput(file)
+	if err != nil {
+		return err
+	}
+	defer closeFile()
+
+	result, err := c.imports.Import(ctx, gymID, r, format, opts)
+	if err != nil {
+		var errs validation.Errors
+		if errors.As(err, &errs) {
+			return fmt.Errorf("%w: %s", importService.ErrInvalidMapping, fieldMessages(errs, c.cfg.Locale.Default))
+		}
+
+		return err
+	}
+
+	rows := make([][]string, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		id := ""
+		if row.PersonID != 0 {
+			id = strconv.Itoa(row.PersonID)
+		}
+		rows = append(rows, []string{
+			strconv.Itoa(row.Row), row.Status, id, row.Number, fieldMessages(row.Errors, c.cfg.Locale.Default),
+		})
+	}
+
+	if err := c.out.print(result, []string{"ROW", "STATUS", "PERSON", "NUMBER", "ERRORS"}, rows); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "total %d, created %d, existing %d, duplicates %d, memberships %d, failed %d\n",
+		result.Total, result.Created, result.Existing, result.Duplicates, result.Memberships, result.Failed)
+
+	if report != "" {
+		if err := writeReport(c, report, result); err != nil {
+			return err
+		}
+	}
+
+	if result.Failed > 0 {
+		return fmt.Errorf("%d of %d rows were not imported", result.Failed, result.Total)
+	}
+
+	return nil
+}
+
+func writeReport(c *cli, path string, result models.ImportResult) error {
+	format, err := spreadsheet.FormatOf(path)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := c.imports.ErrorReport(f, format, c.cfg.Locale.Default, result); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// parseMapping reads field=column pairs separated by commas.
+func parseMapping(s string) (models.ImportMapping, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	mapping := make(models.ImportMapping)
+	for _, pair := range strings.Split(s, ",") {
+		field, column, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("mapping %q: want field=column", pair)
+		}
+		mapping[strings.TrimSpace(field)] = strings.TrimSpace(column)
+	}
+
+	return mapping, nil
+}
+
+func fieldMessages(errs validation.Errors, lang string) string {
+	msgs := make([]string, 0, len(errs))
+	for _, fe := range errs.Localize(lang) {
+		msgs = append(msgs, fe.Field+": "+fe.Message)
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// openInput opens a file, or stdin for "-".
+func openInput(file string) (io.Reader, func(), error) {
+	if file == "-" {
+		return os.Stdin, func() {}, nil
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return f, func() { f.Close() }, nil
+}
+
+func readPeople(file string) ([]models.Person, error) {
+	r, closeFile, err := openInput(file)
+	if err != nil {
+		return nil, err
+	}
+	defer closeFile()
+
+	var people []models.Person
+	if err := json.NewDecoder(r).Decode(&people); err != nil {
+		return nil, fmt.Errorf("decode people: %w", err)
+	}
+
+	return people, nil
+}
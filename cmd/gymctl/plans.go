@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"gym_app/internal/models"
+	"strconv"
+)
+
+func plansList(ctx context.Context, c *cli, args []string) error {
+	fs := newFlagSet("plans list")
+	gymID := gymFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireGym(*gymID); err != nil {
+		return err
+	}
+
+	plans, err := c.plans.FindAllSubscriptions(ctx, *gymID)
+	if err != nil {
+		return err
+	}
+
+	return printPlans(c, plans, plans)
+}
+
+func plansCreate(ctx context.Context, c *cli, args []string) error {
+	fs := newFlagSet("plans create")
+	gymID := gymFlag(fs)
+
+	var plan models.Subscription
+	fs.StringVar(&plan.Title, "title", "", "plan title (required)")
+	fs.Float64Var(&plan.Price, "price", 0, "price")
+	fs.IntVar(&plan.DurationDays, "days", 0, "duration in days (required)")
+	fs.IntVar(&plan.FreezeDays, "freeze-days", 0, "allowed freeze days")
+	fs.BoolVar(&plan.AllGyms, "all-gyms", false, "valid in all gyms")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireGym(*gymID); err != nil {
+		return err
+	}
+
+	if errs := plan.Validate(); errs != nil {
+		return errs
+	}
+
+	id, err := c.plans.AddSubscription(ctx, *gymID, plan)
+	if err != nil {
+		return err
+	}
+
+	plan.ID = strconv.Itoa(id)
+	plan.GymID = *gymID
+
+	return printPlans(c, plan, []models.Subscription{plan})
+}
+
+func printPlans(c *cli, v any, plans []models.Subscription) error {
+	rows := make([][]string, 0, len(plans))
+	for _, p := range plans {
+		rows = append(rows, []string{
+			p.ID,
+			p.Title,
+			strconv.FormatFloat(p.Price, 'f', 2, 64),
+			strconv.Itoa(p.DurationDays),
+			strconv.Itoa(p.FreezeDays),
+			fmt.Sprint(p.AllGyms),
+		})
+	}
+
+	return c.out.print(v, []string{"ID", "TITLE", "PRICE", "DAYS", "FREEZE DAYS", "ALL GYMS"}, rows)
+}
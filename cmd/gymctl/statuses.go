@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// statusesRecalc runs the same recalculation as the nightly cron job.
+func statusesRecalc(ctx context.Context, c *cli, args []string) error {
+	fs := newFlagSet("statuses recalc")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	start := time.Now()
+
+	if err := c.memberships.UpdateStatuses(ctx); err != nil {
+		return err
+	}
+
+	result := struct {
+		Duration string `json:"duration"`
+	}{time.Since(start).Round(time.Millisecond).String()}
+
+	return c.out.print(result, []string{"RESULT", "DURATION"}, [][]string{{"statuses updated", result.Duration}})
+}
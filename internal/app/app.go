@@ -2,15 +2,31 @@ package app
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"github.com/prometheus/client_golang/prometheus"
 	"gym_app/internal/app/http"
 	"gym_app/internal/clients/sso/grpc"
 	"gym_app/internal/config"
 	"gym_app/internal/cron"
+	healthHandler "gym_app/internal/http/handlers/health"
+	"gym_app/internal/lib/cardnumber"
 	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/metrics"
+	"gym_app/internal/lib/tlsconfig"
+	"gym_app/internal/lib/tracing"
 	authService "gym_app/internal/services/auth"
+	"gym_app/internal/services/class"
+	"gym_app/internal/services/export"
+	"gym_app/internal/services/gym"
+	"gym_app/internal/services/import"
 	"gym_app/internal/services/person"
 	personSubService "gym_app/internal/services/person_sub"
+	"gym_app/internal/services/staff"
 	"gym_app/internal/services/subscription"
+	"gym_app/internal/services/trainer"
+	"gym_app/internal/services/training"
 	"gym_app/internal/storage/postgres"
 	"log/slog"
 )
@@ -18,41 +34,204 @@ import (
 type App struct {
 	HTTPSrv *httpApp.HttpApp
 	Cron    *cron.CronJobs
+	Tracing *tracing.Tracing
+
+	log       *slog.Logger
+	cfg       *config.Config
+	storage   *postgres.Storage
+	ssoClient *grpc.SSOClient
 }
 
+// New builds all components of the app. If one of them fails to start,
+// the ones built before it are closed and the error is returned.
 func New(
 	ctx context.Context,
 	log *slog.Logger,
 	cfg *config.Config,
-) *App {
-	storage, err := postgres.New(ctx, cfg.DB)
+) (_ *App, err error) {
+	const op = "app.New"
+
+	a := &App{
+		log: log,
+		cfg: cfg,
+	}
+
+	defer func() {
+		if err != nil {
+			a.close(context.Background())
+		}
+	}()
+
+	a.Tracing, err = tracing.New(ctx, cfg.Tracing)
+	if err != nil {
+		return nil, fmt.Errorf("%s: init tracing: %w", op, err)
+	}
+
+	a.storage, err = postgres.New(ctx, cfg.DB)
 	if err != nil {
-		log.Error("failed to init storage", sl.Error(err))
-		panic(err)
+		return nil, fmt.Errorf("%s: init storage: %w", op, err)
+	}
+
+	var ssoTLS *tls.Config
+	if cfg.Clients.SSO.TLS.Enabled {
+		ssoTLS, err = tlsconfig.Client(cfg.Clients.SSO.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("%s: load sso tls config: %w", op, err)
+		}
 	}
 
-	ssoClient, err := grpc.NewSSOClient(
+	a.ssoClient, err = grpc.NewSSOClient(
 		log,
 		cfg.Clients.SSO.Address,
 		cfg.Clients.SSO.Timeout,
 		cfg.Clients.SSO.RetriesCount,
+		ssoTLS,
 	)
 	if err != nil {
-		log.Error("failed to init sso client", sl.Error(err))
-		panic(err)
+		return nil, fmt.Errorf("%s: init sso client: %w", op, err)
 	}
 
+	storage := a.storage
+	ssoClient := a.ssoClient
+
 	personSrv := personService.New(log, storage)
 	subscriptionSrv := subscriptionService.New(log, storage)
-	personSubSrv := personSubService.New(log, storage)
+	personSubSrv := personSubService.New(log, storage, cardnumber.New(cfg.Cards.Prefix, cfg.Cards.Digits))
 	authSrv := authService.New(log, ssoClient, cfg.AppID)
+	gymSrv := gymService.New(log, storage)
+	staffSrv := staffService.New(log, storage)
+	trainerSrv := trainerService.New(log, storage)
+	classSrv := classService.New(log, storage)
+	trainingSrv := trainingService.New(log, storage)
+	importSrv := importService.New(log, personSrv, subscriptionSrv, personSubSrv)
+	exportSrv := exportService.New(log, storage)
+
+	if err = registerCollectors(
+		metrics.NewPoolCollector(storage),
+		metrics.NewMembershipCollector(log, storage, cfg.Timeout),
+	); err != nil {
+		return nil, fmt.Errorf("%s: register metrics: %w", op, err)
+	}
+
+	a.Cron = cron.New(log, personSubSrv)
+
+	healthChecks := map[string]healthHandler.Check{
+		"postgres": storage.Ping,
+		"sso":      ssoClient.Ping,
+		"cron":     a.Cron.Ping,
+	}
+
+	a.HTTPSrv = httpApp.New(log, *cfg, ssoClient, authSrv, personSrv, subscriptionSrv, personSubSrv, gymSrv, staffSrv, staffSrv, trainerSrv, classSrv, trainingSrv, importSrv, exportSrv, healthChecks)
+
+	return a, nil
+}
+
+// Run starts the cron scheduler and the HTTP server and blocks until ctx is
+// canceled or the server fails. Then it stops the app within
+// lifecycle.shutdown_timeout.
+func (a *App) Run(ctx context.Context) error {
+	const op = "app.Run"
+
+	log := a.log.With(slog.String("op", op))
+
+	// Cron jobs must be drained on shutdown, not canceled with ctx
+	a.Cron.Start(context.WithoutCancel(ctx))
+	log.Info("cron jobs started")
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- a.HTTPSrv.Run()
+	}()
+
+	var runErr error
+
+	select {
+	case <-ctx.Done():
+		log.Info("stopping application")
+	case runErr = <-serverErr:
+		if runErr == nil {
+			runErr = errors.New("http server stopped unexpectedly")
+		}
+		log.Error("http server failed, stopping application", sl.Error(runErr))
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), a.cfg.Lifecycle.ShutdownTimeout)
+	defer cancel()
 
-	cr := cron.New(personSubSrv)
+	if err := a.Stop(stopCtx); err != nil {
+		runErr = errors.Join(runErr, err)
+	}
+
+	if runErr != nil {
+		return fmt.Errorf("%s: %w", op, runErr)
+	}
+
+	log.Info("application stopped")
+
+	return nil
+}
+
+// Stop shuts the components down in reverse order of their dependencies:
+// the HTTP server drains in-flight requests, the scheduler waits for running
+// jobs, and only then the SSO connection, the database pool and the trace
+// exporter are closed.
+func (a *App) Stop(ctx context.Context) error {
+	const op = "app.Stop"
 
-	httpApplication := httpApp.New(ctx, log, *cfg, ssoClient, authSrv, personSrv, subscriptionSrv, personSubSrv)
+	var errs []error
 
-	return &App{
-		HTTPSrv: httpApplication,
-		Cron:    cr,
+	if a.HTTPSrv != nil {
+		if err := a.HTTPSrv.Stop(ctx); err != nil {
+			errs = append(errs, err)
+		}
 	}
+
+	if a.Cron != nil {
+		if err := a.Cron.Stop(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := a.close(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// close releases the clients of external services.
+func (a *App) close(ctx context.Context) error {
+	var errs []error
+
+	if a.ssoClient != nil {
+		if err := a.ssoClient.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close sso client: %w", err))
+		}
+	}
+
+	if a.storage != nil {
+		a.storage.Close()
+	}
+
+	if a.Tracing != nil {
+		if err := a.Tracing.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func registerCollectors(cs ...prometheus.Collector) error {
+	for _, c := range cs {
+		if err := metrics.Registry.Register(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
@@ -6,37 +6,53 @@ import (
 	"fmt"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"gym_app/internal/clients/sso/grpc"
 	"gym_app/internal/config"
 	authHandler "gym_app/internal/http/handlers/auth"
+	classHandler "gym_app/internal/http/handlers/class"
+	exportHandler "gym_app/internal/http/handlers/export"
+	gymHandler "gym_app/internal/http/handlers/gym"
+	healthHandler "gym_app/internal/http/handlers/health"
+	importHandler "gym_app/internal/http/handlers/import"
 	"gym_app/internal/http/handlers/person"
 	personSubHandler "gym_app/internal/http/handlers/person_sub"
+	staffHandler "gym_app/internal/http/handlers/staff"
 	subscriptionHandler "gym_app/internal/http/handlers/subscription"
+	trainerHandler "gym_app/internal/http/handlers/trainer"
+	trainingHandler "gym_app/internal/http/handlers/training"
 	"gym_app/internal/http/middleware/auth"
+	i18nMiddleware "gym_app/internal/http/middleware/i18n"
 	loggerMiddleware "gym_app/internal/http/middleware/logger"
+	metricsMiddleware "gym_app/internal/http/middleware/metrics"
+	rateLimitMiddleware "gym_app/internal/http/middleware/ratelimit"
+	requestCtxMiddleware "gym_app/internal/http/middleware/requestctx"
+	requestIDMiddleware "gym_app/internal/http/middleware/requestid"
+	securityMiddleware "gym_app/internal/http/middleware/security"
+	tenantMiddleware "gym_app/internal/http/middleware/tenant"
 	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/metrics"
+	"gym_app/internal/lib/permission"
+	"gym_app/internal/lib/ratelimit"
+	"gym_app/internal/lib/tlsconfig"
 	"log/slog"
 	"net/http"
+	"slices"
 	"time"
 )
 
-const (
-	userRole  = "user"
-	adminRole = "admin"
-)
-
 type HttpApp struct {
 	HTTPServer *http.Server
 	engine     *gin.Engine
-	ctx        context.Context
+	health     *healthHandler.HealthHandler
 	log        *slog.Logger
 	cfg        config.Config
 }
 
 func New(
-	ctx context.Context,
 	log *slog.Logger,
 	cfg config.Config,
 	ssoClient *grpc.SSOClient,
@@ -44,68 +60,190 @@ func New(
 	personService personHandler.PersonService,
 	subscriptionService subscriptionHandler.SubscriptionService,
 	personSubService personSubHandler.PersonSubService,
+	gymService gymHandler.GymService,
+	staffService staffHandler.StaffService,
+	staffResolver tenantMiddleware.StaffResolver,
+	trainerService trainerHandler.TrainerService,
+	classService classHandler.ClassService,
+	trainingService trainingHandler.TrainingService,
+	importService importHandler.ImportService,
+	exportService exportHandler.ExportService,
+	healthChecks map[string]healthHandler.Check,
 ) *HttpApp {
 
-	personHandle := personHandler.New(ctx, log, personService)
-	subscriptionHandle := subscriptionHandler.New(ctx, log, subscriptionService)
-	personSubHandle := personSubHandler.New(ctx, log, personSubService)
-	authHandle := authHandler.New(ctx, log, authService)
+	personHandle := personHandler.New(log, personService)
+	subscriptionHandle := subscriptionHandler.New(log, subscriptionService)
+	personSubHandle := personSubHandler.New(log, personSubService)
+	authHandle := authHandler.New(log, authService, authHandler.CookieOptions{
+		MaxAge:   cfg.TokenTTL,
+		Domain:   cfg.HTTPServer.Cookie.Domain,
+		Secure:   cfg.HTTPServer.Cookie.Secure || cfg.HTTPServer.TLS.Enabled,
+		SameSite: sameSite(cfg.HTTPServer.Cookie.SameSite),
+	})
+	gymHandle := gymHandler.New(log, gymService)
+	staffHandle := staffHandler.New(log, staffService)
+	trainerHandle := trainerHandler.New(log, trainerService)
+	classHandle := classHandler.New(log, classService)
+	trainingHandle := trainingHandler.New(log, trainingService)
+	importHandle := importHandler.New(log, importService)
+	exportHandle := exportHandler.New(log, exportService)
+	healthHandle := healthHandler.New(log, cfg.HTTPServer.HealthTimeout, healthChecks)
 
 	gin.SetMode(gin.ReleaseMode)
 	engine := gin.New()
+	if err := engine.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Error("invalid trusted proxies, trusting none", sl.Error(err))
+		_ = engine.SetTrustedProxies(nil)
+	}
 
 	setupMiddleware(engine, log, cfg)
 
-	userMiddleware := authMiddleware.AuthMiddleware(log, ssoClient, cfg.AppID, userRole)
-	adminMiddleware := authMiddleware.AuthMiddleware(log, ssoClient, cfg.AppID, adminRole)
+	policy := permission.New(cfg.Access.Roles)
+
+	authenticate := authMiddleware.AuthMiddleware(log, ssoClient, cfg.AppID)
+	can := func(perm string) gin.HandlerFunc {
+		return authMiddleware.RequirePermission(log, policy, perm)
+	}
+
+	engine.GET("/swagger/*any", securityMiddleware.CSP(securityMiddleware.SwaggerCSP), ginSwagger.WrapHandler(swaggerFiles.Handler))
+	engine.GET("/healthz", healthHandle.Liveness)
+	engine.GET("/readyz", healthHandle.Readiness)
 
-	engine.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	if cfg.Metrics.Enabled {
+		engine.GET(cfg.Metrics.Path, gin.WrapH(promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{})))
+	}
 
 	api := engine.Group("/api/v1")
 
 	auth := api.Group("/auth")
 	{
-		auth.POST("/register", authHandle.RegisterNewUser)
-		auth.POST("/login", authHandle.Login)
+		limit := rateLimits(log, cfg.RateLimit)
+
+		auth.POST("/register", append(limit("register", false), authHandle.RegisterNewUser)...)
+		auth.POST("/login", append(limit("login", true), authHandle.Login)...)
+	}
+
+	api.Use(authenticate)
+
+	gyms := api.Group("/gyms")
+	gyms.Use(can(permission.GymsManage))
+	{
+		gyms.GET("", gymHandle.FindAllGyms)
+		gyms.POST("/add", gymHandle.AddGym)
+		gyms.PUT("update/:id", gymHandle.UpdateGym)
+		gyms.DELETE("delete/:id", gymHandle.DeleteGym)
 	}
 
-	api.Use(userMiddleware)
+	staff := api.Group("/staff")
+	staff.Use(can(permission.StaffManage))
 	{
-		people := api.Group("/people")
+		staff.GET("", staffHandle.FindAllStaff)
+		staff.POST("/add", staffHandle.AddStaff)
+		staff.PUT("update/:id", staffHandle.UpdateStaff)
+		staff.DELETE("delete/:id", staffHandle.DeleteStaff)
+	}
+
+	// Everything below works with the data of the user's own gym
+	branch := api.Group("")
+	branch.Use(tenantMiddleware.New(log, staffResolver))
+	{
+		people := branch.Group("/people")
 		{
-			people.GET("", personHandle.FindAllPeople)
-			people.GET("/find", personHandle.FindPersonByName)
+			people.GET("", can(permission.PeopleRead), personHandle.FindAllPeople)
+			people.GET("/find", can(permission.PeopleRead), personHandle.FindPersonByName)
+			people.GET("/duplicates", can(permission.PeopleRead), personHandle.FindDuplicates)
+			people.POST("/merge", can(permission.PeopleMerge), personHandle.MergePeople)
+			people.POST("/add", can(permission.PeopleWrite), personHandle.AddPerson)
+			people.PUT("update/:id", can(permission.PeopleWrite), personHandle.UpdatePerson)
+			people.DELETE("delete/:id", can(permission.PeopleWrite), personHandle.DeletePerson)
+		}
 
-			adminPeople := people.Group("")
-			adminPeople.Use(adminMiddleware)
-			adminPeople.POST("/add", personHandle.AddPerson)
-			adminPeople.PUT("update/:id", personHandle.UpdatePerson)
-			adminPeople.DELETE("delete/:id", personHandle.DeletePerson)
+		subscription := branch.Group("/subscription")
+		{
+			subscription.GET("", can(permission.SubscriptionsRead), subscriptionHandle.FindAllSubscriptions)
+			subscription.POST("/add", can(permission.SubscriptionsWrite), subscriptionHandle.AddSubscription)
+			subscription.PUT("update/:id", can(permission.SubscriptionsWrite), subscriptionHandle.UpdateSubscription)
+			subscription.DELETE("delete/:id", can(permission.SubscriptionsWrite), subscriptionHandle.DeleteSubscription)
+
+			// Tariffs valid in every branch, changed only by those who manage gyms
+			allGyms := subscription.Group("/all_gyms")
+			allGyms.Use(can(permission.SubscriptionsWrite), can(permission.GymsManage))
+			{
+				allGyms.POST("/add", subscriptionHandle.AddAllGymsSubscription)
+				allGyms.PUT("update/:id", subscriptionHandle.UpdateAllGymsSubscription)
+				allGyms.DELETE("delete/:id", subscriptionHandle.DeleteAllGymsSubscription)
+			}
+		}
 
+		personSub := branch.Group("/person_sub")
+		{
+			personSub.GET("find/:number", can(permission.MembershipsRead), personSubHandle.FindPersonSubByNumber)
+			personSub.GET("", can(permission.MembershipsRead), personSubHandle.FindAllPersonSubs)
+			personSub.GET("/find", can(permission.MembershipsRead), personSubHandle.FindPersonSubByPersonName)
+			personSub.POST("/add", can(permission.MembershipsWrite), personSubHandle.AddPersonSub)
+			personSub.PUT("update/:number", can(permission.MembershipsWrite), personSubHandle.UpdatePersonSub)
+			personSub.PATCH("update/:number", can(permission.MembershipsWrite), personSubHandle.UpdatePersonSub)
+			personSub.DELETE("delete/:number", can(permission.MembershipsWrite), personSubHandle.DeletePersonSub)
+			personSub.POST("replace_card/:number", can(permission.MembershipsWrite), personSubHandle.ReplaceCard)
+			personSub.GET("history/:number", can(permission.MembershipsRead), personSubHandle.FindCardHistory)
+			personSub.POST("check_in/:number", can(permission.MembershipsWrite), personSubHandle.CheckIn)
+			personSub.GET("card/:number", can(permission.MembershipsRead), personSubHandle.CardImage)
 		}
 
-		subscription := api.Group("/subscription")
+		trainers := branch.Group("/trainers")
 		{
-			subscription.GET("", subscriptionHandle.FindAllSubscriptions)
+			trainers.GET("", can(permission.ClassesRead), trainerHandle.FindAllTrainers)
+			trainers.POST("/add", can(permission.ClassesManage), trainerHandle.AddTrainer)
+			trainers.PUT("update/:id", can(permission.ClassesManage), trainerHandle.UpdateTrainer)
+			trainers.DELETE("delete/:id", can(permission.ClassesManage), trainerHandle.DeleteTrainer)
+		}
 
-			adminSubscription := subscription.Group("")
-			adminSubscription.Use(adminMiddleware)
-			adminSubscription.POST("/add", subscriptionHandle.AddSubscription)
-			adminSubscription.PUT("update/:id", subscriptionHandle.UpdateSubscription)
-			adminSubscription.DELETE("delete/:id", subscriptionHandle.DeleteSubscription)
+		classes := branch.Group("/classes")
+		{
+			classes.GET("/types", can(permission.ClassesRead), classHandle.FindAllClassTypes)
+			classes.POST("/types/add", can(permission.ClassesManage), classHandle.AddClassType)
+			classes.PUT("/types/update/:id", can(permission.ClassesManage), classHandle.UpdateClassType)
+			classes.DELETE("/types/delete/:id", can(permission.ClassesManage), classHandle.DeleteClassType)
+
+			classes.GET("/schedule", can(permission.ClassesRead), classHandle.FindClassSchedule)
+			classes.POST("/schedule/add", can(permission.ClassesManage), classHandle.AddClassSchedule)
+			classes.PUT("/schedule/update/:id", can(permission.ClassesManage), classHandle.UpdateClassSchedule)
+			classes.DELETE("/schedule/delete/:id", can(permission.ClassesManage), classHandle.DeleteClassSchedule)
+
+			classes.GET("/timetable", can(permission.ClassesRead), classHandle.FindTimetable)
+			classes.POST("/book", can(permission.ClassesBook), classHandle.BookClass)
+			classes.GET("/bookings", can(permission.ClassesRead), classHandle.FindClassBookings)
+			classes.POST("/bookings/cancel/:id", can(permission.ClassesBook), classHandle.CancelBooking)
+			classes.POST("/bookings/attend/:id", can(permission.ClassesAttend), classHandle.MarkAttendance)
 		}
 
-		personSub := api.Group("/person_sub")
+		training := branch.Group("/training")
 		{
+			training.GET("/packages", can(permission.TrainingRead), trainingHandle.FindAllPackages)
+			training.POST("/packages/add", can(permission.TrainingManage), trainingHandle.AddPackage)
+			training.PUT("/packages/update/:id", can(permission.TrainingManage), trainingHandle.UpdatePackage)
+			training.DELETE("/packages/delete/:id", can(permission.TrainingManage), trainingHandle.DeletePackage)
+
+			training.GET("/person_packages", can(permission.TrainingRead), trainingHandle.FindPersonPackages)
+			training.POST("/person_packages/sell", can(permission.TrainingSell), trainingHandle.SellPackage)
+
+			training.POST("/sessions/book", can(permission.TrainingSell), trainingHandle.BookSession)
+			training.POST("/sessions/cancel/:id", can(permission.TrainingSell), trainingHandle.CancelSession)
+			training.POST("/sessions/complete/:id", can(permission.TrainingComplete), trainingHandle.CompleteSession)
+			training.GET("/sessions/upcoming", can(permission.TrainingRead), trainingHandle.FindUpcomingSessions)
+			training.GET("/sessions/my", can(permission.TrainingRead), trainingHandle.FindMySessions)
+		}
 
-			personSub.GET("find/:number", personSubHandle.FindPersonSubByNumber)
-			personSub.GET("", personSubHandle.FindAllPersonSubs)
-			personSub.GET("/find", personSubHandle.FindPersonSubByPersonName)
+		imports := branch.Group("/import")
+		{
+			imports.POST("/people", can(permission.PeopleWrite), can(permission.MembershipsWrite), importHandle.ImportPeople)
+		}
 
-			adminPersonSub := personSub.Group("")
-			adminPersonSub.Use(adminMiddleware)
-			adminPersonSub.POST("/add", personSubHandle.AddPersonSub)
-			adminPersonSub.DELETE("delete/:number", personSubHandle.DeletePersonSub)
+		exports := branch.Group("/export")
+		{
+			exports.GET("/people", can(permission.PeopleRead), exportHandle.ExportPeople)
+			exports.GET("/memberships", can(permission.MembershipsRead), exportHandle.ExportMemberships)
+			exports.GET("/training_sales", can(permission.TrainingRead), exportHandle.ExportTrainingSales)
 		}
 	}
 
@@ -120,7 +258,7 @@ func New(
 	return &HttpApp{
 		HTTPServer: srv,
 		engine:     engine,
-		ctx:        ctx,
+		health:     healthHandle,
 		log:        log,
 		cfg:        cfg,
 	}
@@ -134,9 +272,24 @@ func (a *HttpApp) Run() error {
 		slog.String("addr", a.cfg.Address),
 	)
 
-	log.Info("HTTP server is starting", slog.String("addr", a.cfg.Address))
+	log.Info("HTTP server is starting", slog.String("addr", a.cfg.Address), slog.Bool("tls", a.cfg.TLS.Enabled))
+
+	var err error
+
+	if a.cfg.TLS.Enabled {
+		a.HTTPServer.TLSConfig, err = tlsconfig.Server(a.cfg.TLS)
+		if err != nil {
+			log.Error("failed to load TLS certificate", sl.Error(err))
+			return fmt.Errorf("%s: %w", op, err)
+		}
 
-	if err := a.HTTPServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		// The certificate comes from TLSConfig.GetCertificate
+		err = a.HTTPServer.ListenAndServeTLS("", "")
+	} else {
+		err = a.HTTPServer.ListenAndServe()
+	}
+
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Error("failed to run http server", sl.Error(err))
 		return fmt.Errorf("%s: %w", op, err)
 	}
@@ -144,14 +297,28 @@ func (a *HttpApp) Run() error {
 	return nil
 }
 
-func (a *HttpApp) Stop() error {
+// Stop fails the readiness probe, then waits for in-flight requests for at
+// most http_server.shutdown_timeout (or until ctx is done).
+func (a *HttpApp) Stop(ctx context.Context) error {
 	const op = "httpApp.Stop"
 
-	ctx, cancel := context.WithTimeout(a.ctx, 5*time.Second)
+	log := a.log.With(slog.String("op", op))
+
+	// Fail readiness first and give the balancer time to notice
+	a.health.Drain()
+	if delay := a.cfg.ShutdownDelay; delay > 0 {
+		log.Info("draining before shutdown", slog.Duration("delay", delay))
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, a.cfg.ShutdownTimeout)
 	defer cancel()
 
-	a.log.With(slog.String("op", op)).
-		Info("stopping HTTP server", slog.String("addr", a.HTTPServer.Addr))
+	log.Info("stopping HTTP server", slog.String("addr", a.HTTPServer.Addr))
 
 	if err := a.HTTPServer.Shutdown(ctx); err != nil {
 		a.log.Error("failed to gracefully shutdown server", sl.Error(err))
@@ -161,15 +328,96 @@ func (a *HttpApp) Stop() error {
 	return nil
 }
 
+// corsConfig converts the CORS settings. It reports false if no origin is
+// allowed, then no CORS headers are sent at all.
+func corsConfig(log *slog.Logger, cfg config.CORS) (cors.Config, bool) {
+	if len(cfg.AllowOrigins) == 0 {
+		return cors.Config{}, false
+	}
+
+	corsCfg := cors.Config{
+		AllowOrigins:     cfg.AllowOrigins,
+		AllowMethods:     cfg.AllowMethods,
+		AllowHeaders:     cfg.AllowHeaders,
+		ExposeHeaders:    cfg.ExposeHeaders,
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           cfg.MaxAge,
+	}
+
+	if slices.Contains(cfg.AllowOrigins, "*") {
+		if cfg.AllowCredentials {
+			log.Warn("CORS: credentials are not allowed with the \"*\" origin, disabling them")
+			corsCfg.AllowCredentials = false
+		}
+
+		corsCfg.AllowOrigins = nil
+		corsCfg.AllowAllOrigins = true
+	}
+
+	return corsCfg, true
+}
+
+// sameSite converts the validated same_site setting of the token cookie.
+func sameSite(mode string) http.SameSite {
+	switch mode {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
+
+// rateLimits builds the limiters of an auth endpoint: per IP, per email and
+// IP and, for login, lockout after failed attempts.
+func rateLimits(log *slog.Logger, cfg config.RateLimit) func(scope string, lockout bool) []gin.HandlerFunc {
+	limiter := rateLimitMiddleware.New(log, ratelimit.NewMemoryStore())
+
+	return func(scope string, lockout bool) []gin.HandlerFunc {
+		if !cfg.Enabled {
+			return nil
+		}
+
+		handlers := []gin.HandlerFunc{
+			limiter.ByIP(scope, ratelimit.PerMinute(cfg.IPRate, cfg.IPBurst)),
+			limiter.ByEmail(scope, ratelimit.PerMinute(cfg.EmailRate, cfg.EmailBurst)),
+		}
+
+		if lockout && cfg.MaxFailures > 0 {
+			handlers = append(handlers, limiter.Lockout(ratelimit.Lockout{
+				MaxFailures: cfg.MaxFailures,
+				Window:      cfg.FailureWindow,
+				Duration:    cfg.LockoutDuration,
+			}))
+		}
+
+		return handlers
+	}
+}
+
+// bulkRoutes are the routes that read or write whole files. They get
+// http_server.bulk_timeout instead of the usual timeout.
+func bulkRoutes(cfg config.Config) map[string]time.Duration {
+	return map[string]time.Duration{
+		"/api/v1/import/people":         cfg.BulkTimeout,
+		"/api/v1/export/people":         cfg.BulkTimeout,
+		"/api/v1/export/memberships":    cfg.BulkTimeout,
+		"/api/v1/export/training_sales": cfg.BulkTimeout,
+	}
+}
+
 func setupMiddleware(engine *gin.Engine, log *slog.Logger, cfg config.Config) {
-	engine.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"}, // Или cfg.AllowedOrigins
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Authorization", "Content-Type"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-	}))
+	if corsCfg, ok := corsConfig(log, cfg.CORS); ok {
+		engine.Use(cors.New(corsCfg))
+	}
 
+	engine.Use(securityMiddleware.New(cfg.SecurityHeaders))
 	engine.Use(gin.Recovery())
+	engine.Use(requestIDMiddleware.New())
+	engine.Use(otelgin.Middleware(cfg.Tracing.ServiceName))
+	engine.Use(metricsMiddleware.New())
+	engine.Use(requestCtxMiddleware.New(cfg.Timeout, bulkRoutes(cfg)))
 	engine.Use(loggerMiddleware.New(log))
+	engine.Use(i18nMiddleware.New(cfg.Locale.Default))
 }
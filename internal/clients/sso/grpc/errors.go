@@ -0,0 +1,41 @@
+package grpc
+
+import (
+	"errors"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Errors returned by the SSO client. They wrap the original gRPC status, so
+// field violations can still be read with grpcerrors.
+var (
+	ErrInvalidArgument = errors.New("sso: invalid argument")
+	ErrNotFound        = errors.New("sso: not found")
+	ErrAlreadyExists   = errors.New("sso: already exists")
+	ErrUnauthenticated = errors.New("sso: unauthenticated")
+	ErrUnavailable     = errors.New("sso: service unavailable")
+)
+
+// mapError attaches a typed error to a gRPC status error.
+// Codes without a typed counterpart are returned as is.
+func mapError(op string, err error) error {
+	var target error
+
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		target = ErrInvalidArgument
+	case codes.NotFound:
+		target = ErrNotFound
+	case codes.AlreadyExists:
+		target = ErrAlreadyExists
+	case codes.Unauthenticated, codes.PermissionDenied:
+		target = ErrUnauthenticated
+	case codes.Unavailable, codes.DeadlineExceeded:
+		target = ErrUnavailable
+	default:
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return fmt.Errorf("%s: %w: %w", op, target, err)
+}
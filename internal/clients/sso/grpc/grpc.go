@@ -2,14 +2,19 @@ package grpc
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	ssov1 "github.com/Muaz717/protos_sso/gen/go/sso"
 	grpclog "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
 	grpcretry "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/retry"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/requestctx"
 	"log/slog"
 
 	"time"
@@ -26,11 +31,12 @@ func NewSSOClient(
 	addr string,
 	timeout time.Duration,
 	retriesCount int,
+	tlsConfig *tls.Config,
 ) (*SSOClient, error) {
 	const op = "sso.grpc.NewClient"
 
 	retryOpts := []grpcretry.CallOption{
-		grpcretry.WithCodes(codes.NotFound, codes.Aborted, codes.DeadlineExceeded),
+		grpcretry.WithCodes(codes.Unavailable, codes.Aborted, codes.DeadlineExceeded),
 		grpcretry.WithMax(uint(retriesCount)),
 		grpcretry.WithPerRetryTimeout(timeout),
 	}
@@ -39,9 +45,18 @@ func NewSSOClient(
 		//grpclog.WithLogOnEvents(grpclog.PayloadReceived, grpclog.PayloadSent),
 	}
 
+	// Without a TLS config the connection is plaintext
+	creds := insecure.NewCredentials()
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
 	cc, err := grpc.NewClient(addr,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(creds),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
 		grpc.WithChainUnaryInterceptor(
+			metricsInterceptor,
+			requestIDInterceptor,
 			grpcretry.UnaryClientInterceptor(retryOpts...),
 			grpclog.UnaryClientInterceptor(InterceptorLogger(log), logOpts...),
 		),
@@ -59,6 +74,29 @@ func NewSSOClient(
 	}, nil
 }
 
+// Ping reports whether the connection to SSO is usable. An idle connection
+// is asked to connect and Ping waits for the result until ctx is done.
+func (c *SSOClient) Ping(ctx context.Context) error {
+	const op = "sso.grpc.Ping"
+
+	for {
+		state := c.conn.GetState()
+
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			return fmt.Errorf("%s: connection is %s", op, state)
+		case connectivity.Idle:
+			c.conn.Connect()
+		}
+
+		if !c.conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("%s: connection is %s: %w", op, state, ctx.Err())
+		}
+	}
+}
+
 func (c *SSOClient) Close() error {
 	return c.conn.Close()
 }
@@ -66,7 +104,7 @@ func (c *SSOClient) Close() error {
 func (c *SSOClient) RegisterNewUser(ctx context.Context, email, password string) (int64, error) {
 	const op = "sso.grpc.RegisterNewUser"
 
-	log := c.log.With(
+	log := requestctx.Logger(ctx, c.log).With(
 		slog.String("op", op),
 		slog.String("email", email),
 	)
@@ -80,7 +118,7 @@ func (c *SSOClient) RegisterNewUser(ctx context.Context, email, password string)
 
 	if err != nil {
 		log.Error("failed to register new user", sl.Error(err))
-		return 0, fmt.Errorf("%s: %w", op, err)
+		return 0, mapError(op, err)
 	}
 
 	if resp.GetUserId() == 0 {
@@ -94,7 +132,7 @@ func (c *SSOClient) RegisterNewUser(ctx context.Context, email, password string)
 func (c *SSOClient) Login(ctx context.Context, appID int32, email, password string) (string, error) {
 	const op = "sso.grpc.Login"
 
-	log := c.log.With(
+	log := requestctx.Logger(ctx, c.log).With(
 		slog.String("op", op),
 		slog.String("login", email),
 	)
@@ -109,7 +147,7 @@ func (c *SSOClient) Login(ctx context.Context, appID int32, email, password stri
 
 	if err != nil {
 		log.Error("failed to login", sl.Error(err))
-		return "", err
+		return "", mapError(op, err)
 	}
 
 	if resp.GetToken() == "" {
@@ -123,7 +161,7 @@ func (c *SSOClient) Login(ctx context.Context, appID int32, email, password stri
 func (c *SSOClient) CheckToken(ctx context.Context, appID int32, token string) (*ssov1.CheckTokenResponse, error) {
 	const op = "sso.grpc.CheckToken"
 
-	log := c.log.With(
+	log := requestctx.Logger(ctx, c.log).With(
 		slog.String("op", op),
 		slog.String("token", token),
 	)
@@ -136,7 +174,7 @@ func (c *SSOClient) CheckToken(ctx context.Context, appID int32, token string) (
 	})
 	if err != nil {
 		log.Error("failed to check token", sl.Error(err))
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return nil, mapError(op, err)
 	}
 
 	return resp, nil
@@ -0,0 +1,28 @@
+package grpc
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"gym_app/internal/lib/requestctx"
+)
+
+// requestIDKey is the metadata key of the request ID sent to SSO.
+const requestIDKey = "x-request-id"
+
+// requestIDInterceptor passes the request ID from the context to SSO, so
+// its logs can be matched with ours.
+func requestIDInterceptor(
+	ctx context.Context,
+	method string,
+	req, reply any,
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	if id, ok := requestctx.RequestID(ctx); ok {
+		ctx = metadata.AppendToOutgoingContext(ctx, requestIDKey, id)
+	}
+
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
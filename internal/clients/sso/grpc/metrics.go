@@ -0,0 +1,29 @@
+package grpc
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"gym_app/internal/lib/metrics"
+	"time"
+)
+
+// metricsInterceptor records the latency and result code of SSO calls.
+// It runs before the retry interceptor, so retries count as one call.
+func metricsInterceptor(
+	ctx context.Context,
+	method string,
+	req, reply any,
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	start := time.Now()
+
+	err := invoker(ctx, method, req, reply, cc, opts...)
+
+	metrics.SSODuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	metrics.SSORequests.WithLabelValues(method, status.Code(err).String()).Inc()
+
+	return err
+}
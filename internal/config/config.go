@@ -1,86 +1,345 @@
 package config
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/joho/godotenv"
+	"gym_app/internal/lib/i18n"
+	"io/fs"
 	"log"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type Config struct {
-	Env        string        `yaml:"env" env-default:"local"`
-	TokenTTL   time.Duration `yaml:"token_ttl" env-required:"true"`
-	AppID      int32         `yaml:"app_id" env-required:"true"`
-	HTTPServer `yaml:"http_server"`
-	DB         `yaml:"db"`
-	Clients    ClientConfig `yaml:"clients"`
+	Env        string        `yaml:"env" env:"ENV" env-default:"local"`
+	TokenTTL   time.Duration `yaml:"token_ttl" env:"TOKEN_TTL" env-default:"1h"`
+	AppID      int32         `yaml:"app_id" env:"APP_ID" env-required:"true"`
+	HTTPServer `yaml:"http_server" env-prefix:"HTTP_SERVER_"`
+	DB         `yaml:"db" env-prefix:"DB_"`
+	Clients    ClientConfig `yaml:"clients" env-prefix:"CLIENTS_"`
+	Access     Access       `yaml:"access"`
+	Cards      Cards        `yaml:"cards" env-prefix:"CARDS_"`
+	Locale     Locale       `yaml:"locale" env-prefix:"LOCALE_"`
+	Tracing    Tracing      `yaml:"tracing" env-prefix:"TRACING_"`
+	Metrics    Metrics      `yaml:"metrics" env-prefix:"METRICS_"`
+	Lifecycle  Lifecycle    `yaml:"lifecycle" env-prefix:"LIFECYCLE_"`
+	RateLimit  RateLimit    `yaml:"rate_limit" env-prefix:"RATE_LIMIT_"`
 }
 
 type HTTPServer struct {
-	Address     string        `yaml:"address" env-default:"localhost:8080"`
-	Timeout     time.Duration `yaml:"timeout" env-default:"local"`
-	IdleTimeout time.Duration `yaml:"idle_timeout" env-default:"60s"`
+	Address     string        `yaml:"address" env:"ADDRESS" env-default:"localhost:8080"`
+	Timeout     time.Duration `yaml:"timeout" env:"TIMEOUT" env-default:"4s"`
+	IdleTimeout time.Duration `yaml:"idle_timeout" env:"IDLE_TIMEOUT" env-default:"60s"`
+	// HealthTimeout bounds the dependency checks of the readiness probe
+	HealthTimeout time.Duration `yaml:"health_timeout" env:"HEALTH_TIMEOUT" env-default:"2s"`
+	// ShutdownDelay is how long readiness fails before the server stops
+	// accepting connections
+	ShutdownDelay time.Duration `yaml:"shutdown_delay" env:"SHUTDOWN_DELAY" env-default:"0s"`
+	// ShutdownTimeout is how long in-flight requests may take to finish
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" env-default:"10s"`
+	// BulkTimeout replaces Timeout for file imports and exports, which may
+	// take minutes
+	BulkTimeout time.Duration `yaml:"bulk_timeout" env:"BULK_TIMEOUT" env-default:"5m"`
+	// TrustedProxies may set X-Forwarded-For. Empty means the client IP is
+	// the peer address
+	TrustedProxies []string `yaml:"trusted_proxies" env:"TRUSTED_PROXIES"`
+
+	CORS            CORS            `yaml:"cors" env-prefix:"CORS_"`
+	SecurityHeaders SecurityHeaders `yaml:"security_headers" env-prefix:"SECURITY_HEADERS_"`
+	TLS             ServerTLS       `yaml:"tls" env-prefix:"TLS_"`
+	Cookie          Cookie          `yaml:"cookie" env-prefix:"COOKIE_"`
+}
+
+// Cookie configures the token cookie. An empty Domain makes it a host-only
+// cookie. The cookie is Secure whenever TLS is enabled; Secure also covers
+// TLS terminated by a proxy. SameSite is lax, strict or none, none needs
+// Secure and is what a frontend on another site needs.
+type Cookie struct {
+	Domain   string `yaml:"domain" env:"DOMAIN"`
+	Secure   bool   `yaml:"secure" env:"SECURE" env-default:"false"`
+	SameSite string `yaml:"same_site" env:"SAME_SITE" env-default:"lax"`
+}
+
+// ServerTLS enables HTTPS on the HTTP listener. The key pair is re-read when
+// the files change, checked at most once per ReloadInterval.
+type ServerTLS struct {
+	Enabled        bool          `yaml:"enabled" env:"ENABLED" env-default:"false"`
+	CertFile       string        `yaml:"cert_file" env:"CERT_FILE"`
+	KeyFile        string        `yaml:"key_file" env:"KEY_FILE"`
+	ReloadInterval time.Duration `yaml:"reload_interval" env:"RELOAD_INTERVAL" env-default:"1m"`
+}
+
+// ClientTLS secures a gRPC connection. Without CAFile the system roots are
+// used; CertFile and KeyFile enable mutual TLS.
+type ClientTLS struct {
+	Enabled        bool          `yaml:"enabled" env:"ENABLED" env-default:"false"`
+	CAFile         string        `yaml:"ca_file" env:"CA_FILE"`
+	CertFile       string        `yaml:"cert_file" env:"CERT_FILE"`
+	KeyFile        string        `yaml:"key_file" env:"KEY_FILE"`
+	ServerName     string        `yaml:"server_name" env:"SERVER_NAME"`
+	ReloadInterval time.Duration `yaml:"reload_interval" env:"RELOAD_INTERVAL" env-default:"1m"`
+}
+
+// CORS lists the browser origins allowed to call the API. With no origins
+// CORS is off. A "*" origin cannot be combined with credentials.
+type CORS struct {
+	AllowOrigins     []string      `yaml:"allow_origins" env:"ALLOW_ORIGINS" env-separator:","`
+	AllowMethods     []string      `yaml:"allow_methods" env:"ALLOW_METHODS" env-default:"GET,POST,PUT,PATCH,DELETE,OPTIONS" env-separator:","`
+	AllowHeaders     []string      `yaml:"allow_headers" env:"ALLOW_HEADERS" env-default:"Origin,Authorization,Content-Type,Accept-Language,X-Request-ID" env-separator:","`
+	ExposeHeaders    []string      `yaml:"expose_headers" env:"EXPOSE_HEADERS" env-default:"Content-Length,Content-Language,Retry-After,X-Request-ID" env-separator:","`
+	AllowCredentials bool          `yaml:"allow_credentials" env:"ALLOW_CREDENTIALS" env-default:"true"`
+	MaxAge           time.Duration `yaml:"max_age" env:"MAX_AGE" env-default:"12h"`
+}
+
+// SecurityHeaders configures the security headers of all responses.
+// Empty values leave the header out.
+type SecurityHeaders struct {
+	HSTSMaxAge            time.Duration `yaml:"hsts_max_age" env:"HSTS_MAX_AGE" env-default:"8760h"`
+	HSTSIncludeSubdomains bool          `yaml:"hsts_include_subdomains" env:"HSTS_INCLUDE_SUBDOMAINS" env-default:"false"`
+	ContentSecurityPolicy string        `yaml:"content_security_policy" env:"CONTENT_SECURITY_POLICY" env-default:"default-src 'none'; frame-ancestors 'none'"`
+	FrameOptions          string        `yaml:"frame_options" env:"FRAME_OPTIONS" env-default:"DENY"`
+	ReferrerPolicy        string        `yaml:"referrer_policy" env:"REFERRER_POLICY" env-default:"no-referrer"`
 }
 
 type DB struct {
-	Host       string `yaml:"host" env-required:"true"`
-	DBPort     string `yaml:"port" env-required:"true"`
-	Username   string `yaml:"username" env-required:"true"`
-	DBName     string `yaml:"dbname" env-required:"true"`
-	DBPassword string `yaml:"dbpassword" env-required:"true" env:"DB_PASSWORD"`
+	Host       string `yaml:"host" env:"HOST" env-required:"true"`
+	DBPort     string `yaml:"port" env:"PORT" env-required:"true"`
+	Username   string `yaml:"username" env:"USERNAME" env-required:"true"`
+	DBName     string `yaml:"dbname" env:"NAME" env-required:"true"`
+	DBPassword string `yaml:"dbpassword" env:"PASSWORD" env-required:"true"`
 }
 
 type Client struct {
-	Address      string        `yaml:"address" env-default:"localhost:8082"`
-	Timeout      time.Duration `yaml:"timeout" env-default:"5s"`
-	RetriesCount int           `yaml:"retries_count" env-default:"3"`
+	Address      string        `yaml:"address" env:"ADDRESS" env-default:"localhost:8082"`
+	Timeout      time.Duration `yaml:"timeout" env:"TIMEOUT" env-default:"5s"`
+	RetriesCount int           `yaml:"retries_count" env:"RETRIES_COUNT" env-default:"3"`
+	TLS          ClientTLS     `yaml:"tls" env-prefix:"TLS_"`
+}
+
+// Access maps SSO roles to the permissions they grant.
+type Access struct {
+	Roles map[string][]string `yaml:"roles"`
+}
+
+// Cards configures generation of membership card numbers.
+type Cards struct {
+	Prefix string `yaml:"prefix" env:"PREFIX" env-default:""`
+	Digits int    `yaml:"digits" env:"DIGITS" env-default:"8"`
+}
+
+// Locale sets the language used when Accept-Language names none of the
+// supported ones.
+type Locale struct {
+	Default string `yaml:"default" env:"DEFAULT" env-default:"ru"`
+}
+
+// Tracing configures export of OpenTelemetry spans.
+// Exporter is one of "none", "stdout" or "otlp" (gRPC).
+type Tracing struct {
+	Exporter    string  `yaml:"exporter" env:"EXPORTER" env-default:"none"`
+	Endpoint    string  `yaml:"endpoint" env:"ENDPOINT" env-default:"localhost:4317"`
+	Insecure    bool    `yaml:"insecure" env:"INSECURE" env-default:"true"`
+	ServiceName string  `yaml:"service_name" env:"SERVICE_NAME" env-default:"gym-app"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"SAMPLE_RATIO" env-default:"1"`
+}
+
+// Metrics configures the Prometheus endpoint. It is served by the HTTP
+// server without authentication.
+type Metrics struct {
+	Enabled bool   `yaml:"enabled" env:"ENABLED" env-default:"true"`
+	Path    string `yaml:"path" env:"PATH" env-default:"/metrics"`
+}
+
+// Lifecycle bounds the graceful shutdown of the whole app: HTTP draining,
+// running cron jobs and closing of the clients.
+type Lifecycle struct {
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" env-default:"30s"`
+}
+
+// RateLimit configures the limits of the auth endpoints. Rates are requests
+// per minute; a zero rate disables the limit.
+type RateLimit struct {
+	Enabled         bool          `yaml:"enabled" env:"ENABLED" env-default:"true"`
+	IPRate          float64       `yaml:"ip_rate" env:"IP_RATE" env-default:"30"`
+	IPBurst         int           `yaml:"ip_burst" env:"IP_BURST" env-default:"10"`
+	EmailRate       float64       `yaml:"email_rate" env:"EMAIL_RATE" env-default:"5"`
+	EmailBurst      int           `yaml:"email_burst" env:"EMAIL_BURST" env-default:"5"`
+	MaxFailures     int           `yaml:"max_failures" env:"MAX_FAILURES" env-default:"5"`
+	FailureWindow   time.Duration `yaml:"failure_window" env:"FAILURE_WINDOW" env-default:"15m"`
+	LockoutDuration time.Duration `yaml:"lockout_duration" env:"LOCKOUT_DURATION" env-default:"15m"`
 }
 
 type ClientConfig struct {
-	SSO Client `yaml:"sso"`
+	SSO Client `yaml:"sso" env-prefix:"SSO_"`
 }
 
+// MustLoad loads the config from the file named by the --config flag or
+// CONFIG_PATH and exits if it is missing or invalid.
 func MustLoad() *Config {
+	// CONFIG_PATH may come from .env
+	if err := loadDotEnv(); err != nil {
+		log.Fatalf("failed to load config: %s", err.Error())
+	}
 
-	if err := godotenv.Load(); err != nil {
-		log.Fatalf("failed to load .env file: %s", err.Error())
+	cfg, err := Load(fetchConfigPath())
+	if err != nil {
+		log.Fatalf("failed to load config: %s", err.Error())
 	}
 
-	cfgPath := os.Getenv("CONFIG_PATH")
-	if cfgPath == "" {
-		log.Fatal("config path is empty")
+	return cfg
+}
+
+func MustLoadByPath(cfgPath string) *Config {
+	cfg, err := Load(cfgPath)
+	if err != nil {
+		log.Fatalf("failed to load config: %s", err.Error())
 	}
 
-	if _, err := os.Stat(cfgPath); os.IsNotExist(err) {
-		log.Fatalf("config file does not exists: %s", err)
+	return cfg
+}
+
+// Load reads the config file and applies environment overrides on top of it.
+// Variables from a .env file in the working directory are used when the file
+// exists. With an empty path the config comes from the environment only.
+//
+// Every field can be overridden by a variable named after its yaml path,
+// e.g. HTTP_SERVER_ADDRESS or CLIENTS_SSO_TLS_CA_FILE, except access.roles
+// which is read from the file only.
+func Load(cfgPath string) (*Config, error) {
+	const op = "config.Load"
+
+	if err := loadDotEnv(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
 	var cfg Config
 
-	err := cleanenv.ReadConfig(cfgPath, &cfg)
-	if err != nil {
-		log.Fatalf("failed to read config: %s", err.Error())
+	if cfgPath == "" {
+		if err := cleanenv.ReadEnv(&cfg); err != nil {
+			return nil, fmt.Errorf("%s: read env: %w", op, err)
+		}
+	} else {
+		if _, err := os.Stat(cfgPath); err != nil {
+			return nil, fmt.Errorf("%s: config file: %w", op, err)
+		}
+
+		if err := cleanenv.ReadConfig(cfgPath, &cfg); err != nil {
+			return nil, fmt.Errorf("%s: read %s: %w", op, cfgPath, err)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: invalid config:\n%w", op, err)
 	}
 
-	return &cfg
+	return &cfg, nil
 }
 
-func MustLoadByPath(cfgPath string) *Config {
-	if err := godotenv.Load(); err != nil {
-		log.Fatalf("failed to load .env file: %s", err.Error())
+// loadDotEnv sets the variables of a .env file in the working directory
+// that are not set yet. A missing file is not an error.
+func loadDotEnv() error {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("load .env: %w", err)
 	}
 
-	if _, err := os.Stat(cfgPath); os.IsNotExist(err) {
-		log.Fatalf("config file does not exists: %s", err)
+	return nil
+}
+
+// fetchConfigPath returns the path from the --config flag, falling back to
+// CONFIG_PATH.
+func fetchConfigPath() string {
+	var res string
+
+	flag.StringVar(&res, "config", "", "path to config file")
+	flag.Parse()
+
+	if res == "" {
+		res = os.Getenv("CONFIG_PATH")
 	}
 
-	var cfg Config
+	return res
+}
 
-	err := cleanenv.ReadConfig(cfgPath, &cfg)
-	if err != nil {
-		log.Fatalf("failed to read config: %s", err.Error())
+// Validate checks the values that cleanenv cannot. All problems are
+// reported at once, one per line.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
 	}
 
-	return &cfg
+	check(c.Env == "local" || c.Env == "dev" || c.Env == "prod",
+		"env must be one of local, dev, prod, got %q", c.Env)
+	check(c.TokenTTL > 0, "token_ttl must be positive")
+	check(c.AppID > 0, "app_id must be positive")
+
+	check(c.HTTPServer.Address != "", "http_server.address is required")
+	check(c.HTTPServer.Timeout > 0, "http_server.timeout must be positive")
+	check(c.HTTPServer.IdleTimeout > 0, "http_server.idle_timeout must be positive")
+	check(c.HTTPServer.BulkTimeout > 0, "http_server.bulk_timeout must be positive")
+	check(c.HTTPServer.HealthTimeout > 0, "http_server.health_timeout must be positive")
+	check(c.HTTPServer.ShutdownDelay >= 0, "http_server.shutdown_delay must not be negative")
+	check(c.HTTPServer.ShutdownTimeout > 0, "http_server.shutdown_timeout must be positive")
+	for _, origin := range c.HTTPServer.CORS.AllowOrigins {
+		check(origin != "*" || len(c.HTTPServer.CORS.AllowOrigins) == 1,
+			"http_server.cors.allow_origins: \"*\" cannot be combined with other origins")
+	}
+	check(c.HTTPServer.CORS.MaxAge >= 0, "http_server.cors.max_age must not be negative")
+	check(c.HTTPServer.SecurityHeaders.HSTSMaxAge >= 0, "http_server.security_headers.hsts_max_age must not be negative")
+	cookie := c.HTTPServer.Cookie
+	check(slices.Contains([]string{"lax", "strict", "none"}, cookie.SameSite), "http_server.cookie.same_site must be lax, strict or none")
+	check(cookie.SameSite != "none" || cookie.Secure || c.HTTPServer.TLS.Enabled, "http_server.cookie.same_site none requires a secure cookie")
+
+	if tls := c.HTTPServer.TLS; tls.Enabled {
+		check(tls.CertFile != "", "http_server.tls.cert_file is required when tls is enabled")
+		check(tls.KeyFile != "", "http_server.tls.key_file is required when tls is enabled")
+		check(tls.ReloadInterval > 0, "http_server.tls.reload_interval must be positive")
+	}
+
+	check(c.DB.Host != "", "db.host is required")
+	if port, err := strconv.Atoi(c.DB.DBPort); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("db.port must be a port number, got %q", c.DB.DBPort))
+	}
+
+	sso := c.Clients.SSO
+	check(sso.Address != "", "clients.sso.address is required")
+	check(sso.Timeout > 0, "clients.sso.timeout must be positive")
+	check(sso.RetriesCount >= 0, "clients.sso.retries_count must not be negative")
+	if sso.TLS.Enabled {
+		check((sso.TLS.CertFile == "") == (sso.TLS.KeyFile == ""),
+			"clients.sso.tls.cert_file and key_file must be set together")
+		check(sso.TLS.ReloadInterval > 0, "clients.sso.tls.reload_interval must be positive")
+	}
+
+	check(c.Cards.Digits > 0 && c.Cards.Digits <= 18, "cards.digits must be between 1 and 18")
+	check(i18n.Supported(c.Locale.Default), "locale.default %q is not supported", c.Locale.Default)
+
+	check(c.Tracing.Exporter == "none" || c.Tracing.Exporter == "stdout" || c.Tracing.Exporter == "otlp",
+		"tracing.exporter must be one of none, stdout, otlp, got %q", c.Tracing.Exporter)
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio must be between 0 and 1")
+	if c.Tracing.Exporter == "otlp" {
+		check(c.Tracing.Endpoint != "", "tracing.endpoint is required for the otlp exporter")
+	}
+
+	if c.Metrics.Enabled {
+		check(strings.HasPrefix(c.Metrics.Path, "/"), "metrics.path must start with /")
+	}
+
+	check(c.Lifecycle.ShutdownTimeout > 0, "lifecycle.shutdown_timeout must be positive")
+
+	rl := c.RateLimit
+	check(rl.IPRate >= 0 && rl.EmailRate >= 0, "rate_limit rates must not be negative")
+	check(rl.IPBurst >= 0 && rl.EmailBurst >= 0, "rate_limit bursts must not be negative")
+	check(rl.MaxFailures >= 0, "rate_limit.max_failures must not be negative")
+	check(rl.FailureWindow >= 0 && rl.LockoutDuration >= 0, "rate_limit durations must not be negative")
+
+	return errors.Join(errs...)
 }
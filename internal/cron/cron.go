@@ -2,34 +2,110 @@ package cron
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/robfig/cron/v3"
+	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/metrics"
 	personSubService "gym_app/internal/services/person_sub"
+	"log/slog"
+	"sync/atomic"
+	"time"
 )
 
+var ErrNotRunning = errors.New("cron scheduler is not running")
+
+// cancelGrace bounds the wait for canceled jobs to return, so that a job
+// ignoring its context can't hold the shutdown forever.
+const cancelGrace = 5 * time.Second
+
 type CronJobs struct {
+	log              *slog.Logger
 	cronScheduler    *cron.Cron
 	personSubService *personSubService.PersonSubService
+	running          atomic.Bool
+	cancelJobs       context.CancelFunc
 }
 
-func New(personSubService *personSubService.PersonSubService) *CronJobs {
+func New(log *slog.Logger, personSubService *personSubService.PersonSubService) *CronJobs {
 	return &CronJobs{
+		log:              log,
 		cronScheduler:    cron.New(),
 		personSubService: personSubService,
 	}
 }
 
 func (c *CronJobs) Start(ctx context.Context) {
+	ctx, c.cancelJobs = context.WithCancel(ctx)
 
-	c.cronScheduler.AddFunc("@daily", func() {
-		err := c.personSubService.UpdateStatuses(ctx)
-		if err != nil {
-			panic(err)
-		}
-	})
+	c.cronScheduler.AddFunc("@daily", c.job("update_statuses", func() error {
+		return c.personSubService.UpdateStatuses(ctx)
+	}))
 
 	c.cronScheduler.Start()
+	c.running.Store(true)
 }
 
-func (c *CronJobs) Stop() {
-	c.cronScheduler.Stop()
+// Stop stops scheduling new runs and waits for the running ones. When ctx
+// expires first, the running jobs are canceled and Stop waits up to
+// cancelGrace for them to return, so that they don't outlive the storage.
+func (c *CronJobs) Stop(ctx context.Context) error {
+	const op = "cron.Stop"
+
+	if !c.running.Swap(false) {
+		return nil
+	}
+	defer c.cancelJobs()
+
+	done := c.cronScheduler.Stop()
+
+	select {
+	case <-done.Done():
+		return nil
+	case <-ctx.Done():
+	}
+
+	c.cancelJobs()
+
+	select {
+	case <-done.Done():
+		return fmt.Errorf("%s: running jobs were canceled: %w", op, ctx.Err())
+	case <-time.After(cancelGrace):
+		return fmt.Errorf("%s: running jobs did not return after cancel: %w", op, ctx.Err())
+	}
+}
+
+// Ping reports whether the scheduler is running.
+func (c *CronJobs) Ping(ctx context.Context) error {
+	if !c.running.Load() {
+		return ErrNotRunning
+	}
+
+	return nil
+}
+
+// job wraps fn to record its duration and outcome. A failed run is logged,
+// the next run is attempted on schedule.
+func (c *CronJobs) job(name string, fn func() error) func() {
+	return func() {
+		const op = "cron.job"
+
+		log := c.log.With(
+			slog.String("op", op),
+			slog.String("job", name),
+		)
+
+		start := time.Now()
+		err := fn()
+		metrics.CronDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+
+		if err != nil {
+			metrics.CronRuns.WithLabelValues(name, metrics.OutcomeFailure).Inc()
+			log.Error("cron job failed", sl.Error(err))
+			return
+		}
+
+		metrics.CronRuns.WithLabelValues(name, metrics.OutcomeSuccess).Inc()
+		log.Info("cron job finished", slog.Duration("duration", time.Since(start)))
+	}
 }
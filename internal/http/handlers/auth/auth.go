@@ -2,14 +2,19 @@ package authHandler
 
 import (
 	"context"
+	"errors"
 	"github.com/gin-gonic/gin"
+	i18nMiddleware "gym_app/internal/http/middleware/i18n"
 	"gym_app/internal/lib/api/response"
 	"gym_app/internal/lib/grpcerrors"
 	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/requestctx"
 	"gym_app/internal/models"
+	authService "gym_app/internal/services/auth"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 type AuthService interface {
@@ -18,27 +23,35 @@ type AuthService interface {
 }
 
 type AuthHandler struct {
-	ctx         context.Context
 	log         *slog.Logger
 	authService AuthService
+	cookie      CookieOptions
+}
+
+// CookieOptions are the attributes of the token cookie
+type CookieOptions struct {
+	MaxAge   time.Duration // Lifetime of the token
+	Domain   string        // Empty for a host-only cookie
+	Secure   bool
+	SameSite http.SameSite
 }
 
 func New(
-	ctx context.Context,
 	log *slog.Logger,
 	authService AuthService,
+	cookie CookieOptions,
 ) *AuthHandler {
 	return &AuthHandler{
-		ctx:         ctx,
 		log:         log,
 		authService: authService,
+		cookie:      cookie,
 	}
 }
 
 func (h *AuthHandler) Login(c *gin.Context) {
 	const op = "handlers.auth.login"
 
-	log := h.log.With(
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
 		slog.String("op", op),
 	)
 
@@ -46,28 +59,36 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		log.Error("failed to bind json", slog.String("op", op), sl.Error(err))
-		c.JSON(http.StatusBadRequest, response.Error("failed to decode request"))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "failed to decode request")))
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		log.Warn("failed to validate request", sl.Error(errs))
+
+		c.JSON(http.StatusBadRequest, response.ValidationError(i18nMiddleware.T(c, "validation failed"), errs.Localize(i18nMiddleware.Lang(c))))
+		return
 	}
 
-	token, err := h.authService.Login(h.ctx, req.Email, req.Password)
+	token, err := h.authService.Login(c.Request.Context(), req.Email, req.Password)
 	if err != nil {
 		log.Error("failed to login", slog.String("op", op), sl.Error(err))
 
-		prettyErr := grpcerrors.ParseValidationError(err)
-		c.JSON(http.StatusInternalServerError, response.Error(prettyErr))
+		respondSSOError(c, err, "failed to login")
 		return
 	}
 
 	log.Info("login successful")
 
-	c.SetCookie("token", token, 3600, "/", "localhost", false, true)
+	c.SetSameSite(h.cookie.SameSite)
+	c.SetCookie("token", token, int(h.cookie.MaxAge.Seconds()), "/", h.cookie.Domain, h.cookie.Secure, true)
 	c.JSON(http.StatusOK, response.OK("login successful"))
 }
 
 func (h *AuthHandler) RegisterNewUser(c *gin.Context) {
 	const op = "handlers.auth.registerNewUser"
 
-	log := h.log.With(
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
 		slog.String("op", op),
 	)
 
@@ -75,16 +96,22 @@ func (h *AuthHandler) RegisterNewUser(c *gin.Context) {
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		log.Error("failed to bind json", slog.String("op", op), sl.Error(err))
-		c.JSON(http.StatusBadRequest, response.Error("failed to decode request"))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "failed to decode request")))
 		return
 	}
 
-	userID, err := h.authService.RegisterNewUser(h.ctx, req.Email, req.Password)
+	if errs := req.Validate(); errs != nil {
+		log.Warn("failed to validate request", sl.Error(errs))
+
+		c.JSON(http.StatusBadRequest, response.ValidationError(i18nMiddleware.T(c, "validation failed"), errs.Localize(i18nMiddleware.Lang(c))))
+		return
+	}
+
+	userID, err := h.authService.RegisterNewUser(c.Request.Context(), req.Email, req.Password)
 	if err != nil {
 		log.Error("failed to register new user", slog.String("op", op), sl.Error(err))
 
-		prettyErr := grpcerrors.ParseValidationError(err)
-		c.JSON(http.StatusInternalServerError, response.Error(prettyErr))
+		respondSSOError(c, err, "failed to register")
 		return
 	}
 
@@ -92,3 +119,27 @@ func (h *AuthHandler) RegisterNewUser(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response.OK(strconv.Itoa(int(userID))))
 }
+
+// respondSSOError writes the response for an error of the SSO service.
+// Field violations of an invalid request are returned like the API's own
+// validation errors.
+func respondSSOError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, authService.ErrInvalidRequest):
+		lang := i18nMiddleware.Lang(c)
+		if errs := grpcerrors.FieldErrors(err, lang); errs != nil {
+			c.JSON(http.StatusBadRequest, response.ValidationError(i18nMiddleware.T(c, "validation failed"), errs))
+			return
+		}
+
+		c.JSON(http.StatusBadRequest, response.Error(grpcerrors.ParseValidationError(err, lang)))
+	case errors.Is(err, authService.ErrInvalidCredentials):
+		c.JSON(http.StatusUnauthorized, response.Error(i18nMiddleware.T(c, "invalid email or password")))
+	case errors.Is(err, authService.ErrUserExists):
+		c.JSON(http.StatusConflict, response.Error(i18nMiddleware.T(c, "user already exists")))
+	case errors.Is(err, authService.ErrSSOUnavailable):
+		c.JSON(http.StatusServiceUnavailable, response.Error(i18nMiddleware.T(c, "authentication service is unavailable")))
+	default:
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, msg)))
+	}
+}
@@ -0,0 +1,579 @@
+package classHandler
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	i18nMiddleware "gym_app/internal/http/middleware/i18n"
+	tenantMiddleware "gym_app/internal/http/middleware/tenant"
+	"gym_app/internal/lib/api/response"
+	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/requestctx"
+	"gym_app/internal/models"
+	classService "gym_app/internal/services/class"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type ClassService interface {
+	AddClassType(ctx context.Context, gymID int64, classType models.ClassType) (int64, error)
+	UpdateClassType(ctx context.Context, gymID int64, classType models.ClassType, classTypeID int64) error
+	DeleteClassType(ctx context.Context, gymID int64, classTypeID int64) error
+	FindAllClassTypes(ctx context.Context, gymID int64) ([]models.ClassType, error)
+
+	AddClassSchedule(ctx context.Context, gymID int64, schedule models.ClassSchedule) (int64, error)
+	UpdateClassSchedule(ctx context.Context, gymID int64, schedule models.ClassSchedule, scheduleID int64) error
+	DeleteClassSchedule(ctx context.Context, gymID int64, scheduleID int64) error
+	FindClassSchedule(ctx context.Context, gymID int64) ([]models.ClassSchedule, error)
+	FindTimetable(ctx context.Context, gymID int64, from, to string) ([]models.ClassOccurrence, error)
+
+	BookClass(ctx context.Context, gymID int64, booking models.ClassBooking) (int64, error)
+	CancelBooking(ctx context.Context, gymID, bookingID int64) error
+	MarkAttendance(ctx context.Context, gymID, bookingID int64) error
+	FindClassBookings(ctx context.Context, gymID, scheduleID int64, classDate string) ([]models.ClassBooking, error)
+}
+
+type ClassHandler struct {
+	log          *slog.Logger
+	classService ClassService
+}
+
+func New(log *slog.Logger, classService ClassService) *ClassHandler {
+	return &ClassHandler{
+		log:          log,
+		classService: classService,
+	}
+}
+
+// AddClassType godoc
+// @Summary      Добавить вид занятия
+// @Description  Добавляет вид группового занятия
+// @Security BearerAuth
+// @Tags         class
+// @Accept       json
+// @Produce      json
+// @Param        class_type  body     models.ClassType  true  "Вид занятия"
+// @Success      200   {object}  response.Response "Вид занятия добавлен"
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      409   {object}  response.Response "Конфликт"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /classes/types/add [post]
+func (h *ClassHandler) AddClassType(c *gin.Context) {
+	const op = "handlers.class.addClassType"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	var classType models.ClassType
+	if !bindJSON(c, log, &classType) {
+		return
+	}
+
+	if errs := classType.Validate(); errs != nil {
+		log.Warn("failed to validate class type", sl.Error(errs))
+		c.JSON(http.StatusBadRequest, response.ValidationError(i18nMiddleware.T(c, "validation failed"), errs.Localize(i18nMiddleware.Lang(c))))
+		return
+	}
+
+	classTypeID, err := h.classService.AddClassType(c.Request.Context(), tenantMiddleware.GymID(c), classType)
+	if err != nil {
+		respondError(c, log, err, "failed to add class type")
+		return
+	}
+
+	log.Info("Class type added", slog.Int64("class_type_id", classTypeID))
+	c.JSON(http.StatusOK, response.OK("Class type added, classTypeId: "+strconv.FormatInt(classTypeID, 10)))
+}
+
+// UpdateClassType godoc
+// @Summary      Обновить вид занятия
+// @Description  Обновляет вид группового занятия
+// @Security BearerAuth
+// @Tags         class
+// @Accept       json
+// @Produce      json
+// @Param        id          path     int               true  "ID вида занятия"
+// @Param        class_type  body     models.ClassType  true  "Вид занятия"
+// @Success      200   {object}  response.Response "Вид занятия обновлен"
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      404   {object}  response.Response "Вид занятия не найден"
+// @Failure      409   {object}  response.Response "Конфликт"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /classes/types/update/{id} [put]
+func (h *ClassHandler) UpdateClassType(c *gin.Context) {
+	const op = "handlers.class.updateClassType"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	classTypeID, ok := parseID(c, log, "class type")
+	if !ok {
+		return
+	}
+
+	var classType models.ClassType
+	if !bindJSON(c, log, &classType) {
+		return
+	}
+
+	if errs := classType.Validate(); errs != nil {
+		log.Warn("failed to validate class type", sl.Error(errs))
+		c.JSON(http.StatusBadRequest, response.ValidationError(i18nMiddleware.T(c, "validation failed"), errs.Localize(i18nMiddleware.Lang(c))))
+		return
+	}
+
+	if err := h.classService.UpdateClassType(c.Request.Context(), tenantMiddleware.GymID(c), classType, classTypeID); err != nil {
+		respondError(c, log, err, "failed to update class type")
+		return
+	}
+
+	log.Info("Class type updated", slog.Int64("class_type_id", classTypeID))
+	c.JSON(http.StatusOK, response.OK("Class type updated"))
+}
+
+// DeleteClassType godoc
+// @Summary      Удалить вид занятия
+// @Description  Удаляет вид занятия, если его нет в расписании
+// @Security BearerAuth
+// @Tags         class
+// @Accept       json
+// @Produce      json
+// @Param        id  path     int  true  "ID вида занятия"
+// @Success      200   {object}  response.Response "Вид занятия удален"
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      404   {object}  response.Response "Вид занятия не найден"
+// @Failure      409   {object}  response.Response "Занятие есть в расписании"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /classes/types/delete/{id} [delete]
+func (h *ClassHandler) DeleteClassType(c *gin.Context) {
+	const op = "handlers.class.deleteClassType"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	classTypeID, ok := parseID(c, log, "class type")
+	if !ok {
+		return
+	}
+
+	if err := h.classService.DeleteClassType(c.Request.Context(), tenantMiddleware.GymID(c), classTypeID); err != nil {
+		respondError(c, log, err, "failed to delete class type")
+		return
+	}
+
+	log.Info("Class type deleted", slog.Int64("class_type_id", classTypeID))
+	c.JSON(http.StatusOK, response.OK("Class type deleted"))
+}
+
+// FindAllClassTypes godoc
+// @Summary      Получить виды занятий
+// @Description  Возвращает виды групповых занятий зала
+// @Security BearerAuth
+// @Tags         class
+// @Accept       json
+// @Produce      json
+// @Success      200   {array}   models.ClassType
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /classes/types [get]
+func (h *ClassHandler) FindAllClassTypes(c *gin.Context) {
+	const op = "handlers.class.findAllClassTypes"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	classTypes, err := h.classService.FindAllClassTypes(c.Request.Context(), tenantMiddleware.GymID(c))
+	if err != nil {
+		respondError(c, log, err, "failed to get class types")
+		return
+	}
+
+	c.JSON(http.StatusOK, classTypes)
+}
+
+// AddClassSchedule godoc
+// @Summary      Добавить занятие в расписание
+// @Description  Добавляет еженедельное занятие в расписание
+// @Security BearerAuth
+// @Tags         class
+// @Accept       json
+// @Produce      json
+// @Param        schedule  body     models.ClassSchedule  true  "Занятие"
+// @Success      200   {object}  response.Response "Занятие добавлено"
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      404   {object}  response.Response "Вид занятия или тренер не найден"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /classes/schedule/add [post]
+func (h *ClassHandler) AddClassSchedule(c *gin.Context) {
+	const op = "handlers.class.addClassSchedule"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	var schedule models.ClassSchedule
+	if !bindJSON(c, log, &schedule) {
+		return
+	}
+
+	if errs := schedule.Validate(); errs != nil {
+		log.Warn("failed to validate scheduled class", sl.Error(errs))
+		c.JSON(http.StatusBadRequest, response.ValidationError(i18nMiddleware.T(c, "validation failed"), errs.Localize(i18nMiddleware.Lang(c))))
+		return
+	}
+
+	scheduleID, err := h.classService.AddClassSchedule(c.Request.Context(), tenantMiddleware.GymID(c), schedule)
+	if err != nil {
+		respondError(c, log, err, "failed to add class to schedule")
+		return
+	}
+
+	log.Info("Class added to schedule", slog.Int64("schedule_id", scheduleID))
+	c.JSON(http.StatusOK, response.OK("Class added to schedule, scheduleId: "+strconv.FormatInt(scheduleID, 10)))
+}
+
+// UpdateClassSchedule godoc
+// @Summary      Обновить занятие в расписании
+// @Description  Обновляет еженедельное занятие в расписании
+// @Security BearerAuth
+// @Tags         class
+// @Accept       json
+// @Produce      json
+// @Param        id        path     int                   true  "ID занятия в расписании"
+// @Param        schedule  body     models.ClassSchedule  true  "Занятие"
+// @Success      200   {object}  response.Response "Занятие обновлено"
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      404   {object}  response.Response "Занятие, вид занятия или тренер не найден"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /classes/schedule/update/{id} [put]
+func (h *ClassHandler) UpdateClassSchedule(c *gin.Context) {
+	const op = "handlers.class.updateClassSchedule"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	scheduleID, ok := parseID(c, log, "schedule")
+	if !ok {
+		return
+	}
+
+	var schedule models.ClassSchedule
+	if !bindJSON(c, log, &schedule) {
+		return
+	}
+
+	if errs := schedule.Validate(); errs != nil {
+		log.Warn("failed to validate scheduled class", sl.Error(errs))
+		c.JSON(http.StatusBadRequest, response.ValidationError(i18nMiddleware.T(c, "validation failed"), errs.Localize(i18nMiddleware.Lang(c))))
+		return
+	}
+
+	if err := h.classService.UpdateClassSchedule(c.Request.Context(), tenantMiddleware.GymID(c), schedule, scheduleID); err != nil {
+		respondError(c, log, err, "failed to update scheduled class")
+		return
+	}
+
+	log.Info("Scheduled class updated", slog.Int64("schedule_id", scheduleID))
+	c.JSON(http.StatusOK, response.OK("Scheduled class updated"))
+}
+
+// DeleteClassSchedule godoc
+// @Summary      Удалить занятие из расписания
+// @Description  Удаляет занятие из расписания вместе с записями на него
+// @Security BearerAuth
+// @Tags         class
+// @Accept       json
+// @Produce      json
+// @Param        id  path     int  true  "ID занятия в расписании"
+// @Success      200   {object}  response.Response "Занятие удалено"
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      404   {object}  response.Response "Занятие не найдено"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /classes/schedule/delete/{id} [delete]
+func (h *ClassHandler) DeleteClassSchedule(c *gin.Context) {
+	const op = "handlers.class.deleteClassSchedule"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	scheduleID, ok := parseID(c, log, "schedule")
+	if !ok {
+		return
+	}
+
+	if err := h.classService.DeleteClassSchedule(c.Request.Context(), tenantMiddleware.GymID(c), scheduleID); err != nil {
+		respondError(c, log, err, "failed to delete scheduled class")
+		return
+	}
+
+	log.Info("Scheduled class deleted", slog.Int64("schedule_id", scheduleID))
+	c.JSON(http.StatusOK, response.OK("Scheduled class deleted"))
+}
+
+// FindClassSchedule godoc
+// @Summary      Получить расписание
+// @Description  Возвращает еженедельное расписание занятий зала
+// @Security BearerAuth
+// @Tags         class
+// @Accept       json
+// @Produce      json
+// @Success      200   {array}   models.ClassSchedule
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /classes/schedule [get]
+func (h *ClassHandler) FindClassSchedule(c *gin.Context) {
+	const op = "handlers.class.findClassSchedule"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	schedule, err := h.classService.FindClassSchedule(c.Request.Context(), tenantMiddleware.GymID(c))
+	if err != nil {
+		respondError(c, log, err, "failed to get class schedule")
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// FindTimetable godoc
+// @Summary      Получить расписание на даты
+// @Description  Возвращает занятия за период (по умолчанию на неделю вперед) с количеством записавшихся
+// @Security BearerAuth
+// @Tags         class
+// @Accept       json
+// @Produce      json
+// @Param        from  query     string  false  "Дата начала (дд-мм-гггг)"
+// @Param        to    query     string  false  "Дата окончания (дд-мм-гггг)"
+// @Success      200   {array}   models.ClassOccurrence
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /classes/timetable [get]
+func (h *ClassHandler) FindTimetable(c *gin.Context) {
+	const op = "handlers.class.findTimetable"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	timetable, err := h.classService.FindTimetable(c.Request.Context(), tenantMiddleware.GymID(c), c.Query("from"), c.Query("to"))
+	if err != nil {
+		respondError(c, log, err, "failed to get timetable")
+		return
+	}
+
+	c.JSON(http.StatusOK, timetable)
+}
+
+// BookClass godoc
+// @Summary      Записать клиента на занятие
+// @Description  Записывает клиента с активным абонементом на занятие, если есть свободные места
+// @Security BearerAuth
+// @Tags         class
+// @Accept       json
+// @Produce      json
+// @Param        booking  body     models.ClassBooking  true  "Запись"
+// @Success      200   {object}  response.Response "Клиент записан"
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      404   {object}  response.Response "Занятие не найдено"
+// @Failure      409   {object}  response.Response "Нет мест, нет абонемента или клиент уже записан"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /classes/book [post]
+func (h *ClassHandler) BookClass(c *gin.Context) {
+	const op = "handlers.class.bookClass"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	var booking models.ClassBooking
+	if !bindJSON(c, log, &booking) {
+		return
+	}
+
+	if errs := booking.Validate(); errs != nil {
+		log.Warn("failed to validate booking", sl.Error(errs))
+		c.JSON(http.StatusBadRequest, response.ValidationError(i18nMiddleware.T(c, "validation failed"), errs.Localize(i18nMiddleware.Lang(c))))
+		return
+	}
+
+	bookingID, err := h.classService.BookClass(c.Request.Context(), tenantMiddleware.GymID(c), booking)
+	if err != nil {
+		respondError(c, log, err, "failed to book class")
+		return
+	}
+
+	log.Info("Class booked", slog.Int64("booking_id", bookingID))
+	c.JSON(http.StatusOK, response.OK("Class booked, bookingId: "+strconv.FormatInt(bookingID, 10)))
+}
+
+// CancelBooking godoc
+// @Summary      Отменить запись
+// @Description  Отменяет запись клиента на занятие
+// @Security BearerAuth
+// @Tags         class
+// @Accept       json
+// @Produce      json
+// @Param        id  path     int  true  "ID записи"
+// @Success      200   {object}  response.Response "Запись отменена"
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      404   {object}  response.Response "Запись не найдена"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /classes/bookings/cancel/{id} [post]
+func (h *ClassHandler) CancelBooking(c *gin.Context) {
+	const op = "handlers.class.cancelBooking"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	bookingID, ok := parseID(c, log, "booking")
+	if !ok {
+		return
+	}
+
+	if err := h.classService.CancelBooking(c.Request.Context(), tenantMiddleware.GymID(c), bookingID); err != nil {
+		respondError(c, log, err, "failed to cancel booking")
+		return
+	}
+
+	log.Info("Booking cancelled", slog.Int64("booking_id", bookingID))
+	c.JSON(http.StatusOK, response.OK("Booking cancelled"))
+}
+
+// MarkAttendance godoc
+// @Summary      Отметить посещение
+// @Description  Отмечает, что клиент пришел на занятие
+// @Security BearerAuth
+// @Tags         class
+// @Accept       json
+// @Produce      json
+// @Param        id  path     int  true  "ID записи"
+// @Success      200   {object}  response.Response "Посещение отмечено"
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      404   {object}  response.Response "Запись не найдена"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /classes/bookings/attend/{id} [post]
+func (h *ClassHandler) MarkAttendance(c *gin.Context) {
+	const op = "handlers.class.markAttendance"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	bookingID, ok := parseID(c, log, "booking")
+	if !ok {
+		return
+	}
+
+	if err := h.classService.MarkAttendance(c.Request.Context(), tenantMiddleware.GymID(c), bookingID); err != nil {
+		respondError(c, log, err, "failed to mark attendance")
+		return
+	}
+
+	log.Info("Attendance marked", slog.Int64("booking_id", bookingID))
+	c.JSON(http.StatusOK, response.OK("Attendance marked"))
+}
+
+// FindClassBookings godoc
+// @Summary      Получить записи на занятие
+// @Description  Возвращает записи клиентов на занятие в указанную дату
+// @Security BearerAuth
+// @Tags         class
+// @Accept       json
+// @Produce      json
+// @Param        schedule_id  query     int     true  "ID занятия в расписании"
+// @Param        date         query     string  true  "Дата занятия (дд-мм-гггг)"
+// @Success      200   {array}   models.ClassBooking
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /classes/bookings [get]
+func (h *ClassHandler) FindClassBookings(c *gin.Context) {
+	const op = "handlers.class.findClassBookings"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	scheduleID, err := strconv.ParseInt(c.Query("schedule_id"), 10, 64)
+	if err != nil {
+		log.Error("failed to parse schedule id", sl.Error(err))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "invalid schedule id")))
+		return
+	}
+
+	bookings, err := h.classService.FindClassBookings(c.Request.Context(), tenantMiddleware.GymID(c), scheduleID, c.Query("date"))
+	if err != nil {
+		respondError(c, log, err, "failed to get bookings")
+		return
+	}
+
+	c.JSON(http.StatusOK, bookings)
+}
+
+func bindJSON(c *gin.Context, log *slog.Logger, v any) bool {
+	if err := c.ShouldBindJSON(v); err != nil {
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+
+			c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "empty request")))
+			return false
+		}
+
+		log.Error("failed to decode request body", sl.Error(err))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "failed to decode request")))
+		return false
+	}
+
+	return true
+}
+
+func parseID(c *gin.Context, log *slog.Logger, name string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		log.Error("failed to parse "+name+" id", sl.Error(err))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "invalid "+name+" id")))
+		return 0, false
+	}
+
+	return id, true
+}
+
+var errorStatuses = []struct {
+	err    error
+	status int
+}{
+	{classService.ErrInvalidDate, http.StatusBadRequest},
+	{classService.ErrInvalidPeriod, http.StatusBadRequest},
+	{classService.ErrClassInPast, http.StatusBadRequest},
+	{classService.ErrWrongClassDate, http.StatusBadRequest},
+	{classService.ErrClassTypeNotFound, http.StatusNotFound},
+	{classService.ErrScheduleNotFound, http.StatusNotFound},
+	{classService.ErrBookingNotFound, http.StatusNotFound},
+	{classService.ErrClassTypeExists, http.StatusConflict},
+	{classService.ErrClassTypeInUse, http.StatusConflict},
+	{classService.ErrNoActiveMembership, http.StatusConflict},
+	{classService.ErrClassFull, http.StatusConflict},
+	{classService.ErrAlreadyBooked, http.StatusConflict},
+}
+
+// respondError maps class service errors to HTTP statuses.
+func respondError(c *gin.Context, log *slog.Logger, err error, msg string) {
+	for _, e := range errorStatuses {
+		if errors.Is(err, e.err) {
+			log.Warn(msg, sl.Error(err))
+			c.JSON(e.status, response.Error(i18nMiddleware.T(c, e.err.Error())))
+			return
+		}
+	}
+
+	log.Error(msg, sl.Error(err))
+	c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, msg)))
+}
@@ -0,0 +1,137 @@
+package exportHandler
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	i18nMiddleware "gym_app/internal/http/middleware/i18n"
+	tenantMiddleware "gym_app/internal/http/middleware/tenant"
+	"gym_app/internal/lib/api/response"
+	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/requestctx"
+	"gym_app/internal/lib/spreadsheet"
+	"gym_app/internal/models"
+	exportService "gym_app/internal/services/export"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type ExportService interface {
+	Export(ctx context.Context, gymID int64, table, format, lang string, filter models.ExportFilter, w io.Writer) error
+}
+
+type ExportHandler struct {
+	log           *slog.Logger
+	exportService ExportService
+}
+
+func New(
+	log *slog.Logger,
+	exportService ExportService,
+) *ExportHandler {
+	return &ExportHandler{
+		log:           log,
+		exportService: exportService,
+	}
+}
+
+// ExportPeople godoc
+// @Summary      Выгрузка клиентов
+// @Description  Выгружает клиентов зала в CSV или XLSX
+// @Security BearerAuth
+// @Tags         export
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param        format  query  string  false  "Формат файла: csv или xlsx (по умолчанию)"
+// @Param        name    query  string  false  "ФИО клиента"
+// @Success      200  {file}    file "Файл выгрузки"
+// @Failure      400  {object}  response.Response "Некорректный запрос"
+// @Failure      500  {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /export/people [get]
+func (h *ExportHandler) ExportPeople(c *gin.Context) {
+	h.export(c, exportService.TablePeople)
+}
+
+// ExportMemberships godoc
+// @Summary      Выгрузка абонементов
+// @Description  Выгружает абонементы зала с ФИО клиента, названием и ценой тарифа в CSV или XLSX
+// @Security BearerAuth
+// @Tags         export
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param        format  query  string  false  "Формат файла: csv или xlsx (по умолчанию)"
+// @Param        name    query  string  false  "ФИО клиента"
+// @Success      200  {file}    file "Файл выгрузки"
+// @Failure      400  {object}  response.Response "Некорректный запрос"
+// @Failure      500  {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /export/memberships [get]
+func (h *ExportHandler) ExportMemberships(c *gin.Context) {
+	h.export(c, exportService.TableMemberships)
+}
+
+// ExportTrainingSales godoc
+// @Summary      Выгрузка продаж персональных тренировок
+// @Description  Выгружает проданные пакеты персональных тренировок с ценой, клиентом и тренером в CSV или XLSX
+// @Security BearerAuth
+// @Tags         export
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param        format     query  string  false  "Формат файла: csv или xlsx (по умолчанию)"
+// @Param        person_id  query  int     false  "ID клиента"
+// @Success      200  {file}    file "Файл выгрузки"
+// @Failure      400  {object}  response.Response "Некорректный запрос"
+// @Failure      500  {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /export/training_sales [get]
+func (h *ExportHandler) ExportTrainingSales(c *gin.Context) {
+	h.export(c, exportService.TableTrainingSales)
+}
+
+func (h *ExportHandler) export(c *gin.Context, table string) {
+	const op = "handlers.export.export"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+		slog.String("table", table),
+	)
+
+	format, err := spreadsheet.Format(c.DefaultQuery("format", spreadsheet.FormatXLSX))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "unsupported file format, use csv or xlsx")))
+		return
+	}
+
+	filter := models.ExportFilter{Name: c.Query("name")}
+
+	if s := c.Query("person_id"); s != "" {
+		if filter.PersonID, err = strconv.ParseInt(s, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "invalid person id")))
+			return
+		}
+	}
+
+	filename := table + "_" + time.Now().Format("2006-01-02") + "." + format
+
+	c.Header("Content-Type", spreadsheet.ContentType(format))
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	err = h.exportService.Export(c.Request.Context(), tenantMiddleware.GymID(c), table, format, i18nMiddleware.Lang(c), filter, c.Writer)
+	if err != nil {
+		log.Error("failed to export", sl.Error(err))
+
+		// Once part of the file is sent the status can't change, the
+		// download ends incomplete and the error is only logged
+		if c.Writer.Written() {
+			c.Abort()
+			return
+		}
+
+		c.Writer.Header().Del("Content-Disposition")
+		c.Writer.Header().Del("Content-Type")
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to export")))
+		return
+	}
+
+	log.Info("export finished")
+}
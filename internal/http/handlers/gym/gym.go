@@ -0,0 +1,240 @@
+package gymHandler
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	i18nMiddleware "gym_app/internal/http/middleware/i18n"
+	"gym_app/internal/lib/api/response"
+	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/requestctx"
+	"gym_app/internal/models"
+	gymService "gym_app/internal/services/gym"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type GymService interface {
+	AddGym(ctx context.Context, gym models.Gym) (int64, error)
+	UpdateGym(ctx context.Context, gym models.Gym, gymID int64) error
+	DeleteGym(ctx context.Context, gymID int64) error
+	FindAllGyms(ctx context.Context) ([]models.Gym, error)
+}
+
+type GymHandler struct {
+	log        *slog.Logger
+	gymService GymService
+}
+
+func New(log *slog.Logger, gymService GymService) *GymHandler {
+	return &GymHandler{
+		log:        log,
+		gymService: gymService,
+	}
+}
+
+// AddGym godoc
+// @Summary      Добавить зал
+// @Description  Добавляет новый зал (филиал)
+// @Security BearerAuth
+// @Tags         gym
+// @Accept       json
+// @Produce      json
+// @Param        gym  body     models.Gym  true  "Зал"
+// @Success      200   {object}  response.Response "Зал добавлен"
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      409   {object}  response.Response "Конфликт"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /gyms/add [post]
+func (h *GymHandler) AddGym(c *gin.Context) {
+	const op = "handlers.gym.addGym"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	var gym models.Gym
+
+	if err := c.ShouldBindJSON(&gym); err != nil {
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+
+			c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "empty request")))
+			return
+		}
+
+		log.Error("failed to decode request body", sl.Error(err))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "failed to decode request")))
+		return
+	}
+
+	if errs := gym.Validate(); errs != nil {
+		log.Warn("failed to validate gym", sl.Error(errs))
+
+		c.JSON(http.StatusBadRequest, response.ValidationError(i18nMiddleware.T(c, "validation failed"), errs.Localize(i18nMiddleware.Lang(c))))
+		return
+	}
+
+	gymID, err := h.gymService.AddGym(c.Request.Context(), gym)
+	if err != nil {
+		if errors.Is(err, gymService.ErrGymExists) {
+			c.JSON(http.StatusConflict, response.Error(i18nMiddleware.T(c, "gym already exists")))
+			return
+		}
+
+		log.Error("failed to add gym", sl.Error(err))
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to add gym")))
+		return
+	}
+
+	log.Info("Gym added", slog.Int64("gym_id", gymID))
+	c.JSON(http.StatusOK, response.OK("Gym added, gymId: "+strconv.FormatInt(gymID, 10)))
+}
+
+// UpdateGym godoc
+// @Summary      Обновить зал
+// @Description  Обновляет название и адрес зала
+// @Security BearerAuth
+// @Tags         gym
+// @Accept       json
+// @Produce      json
+// @Param        id   path     int         true  "ID зала"
+// @Param        gym  body     models.Gym  true  "Зал"
+// @Success      200   {object}  response.Response "Зал обновлен"
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      404   {object}  response.Response "Зал не найден"
+// @Failure      409   {object}  response.Response "Конфликт"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /gyms/update/{id} [put]
+func (h *GymHandler) UpdateGym(c *gin.Context) {
+	const op = "handlers.gym.updateGym"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	gymID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		log.Error("failed to parse gym id", sl.Error(err))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "invalid gym id")))
+		return
+	}
+
+	var gym models.Gym
+
+	if err := c.ShouldBindJSON(&gym); err != nil {
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+
+			c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "empty request")))
+			return
+		}
+
+		log.Error("failed to decode request body", sl.Error(err))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "failed to decode request")))
+		return
+	}
+
+	if errs := gym.Validate(); errs != nil {
+		log.Warn("failed to validate gym", sl.Error(errs))
+
+		c.JSON(http.StatusBadRequest, response.ValidationError(i18nMiddleware.T(c, "validation failed"), errs.Localize(i18nMiddleware.Lang(c))))
+		return
+	}
+
+	if err := h.gymService.UpdateGym(c.Request.Context(), gym, gymID); err != nil {
+		if errors.Is(err, gymService.ErrGymNotFound) {
+			c.JSON(http.StatusNotFound, response.Error(i18nMiddleware.T(c, "gym not found")))
+			return
+		}
+
+		if errors.Is(err, gymService.ErrGymExists) {
+			c.JSON(http.StatusConflict, response.Error(i18nMiddleware.T(c, "gym with such name already exists")))
+			return
+		}
+
+		log.Error("failed to update gym", sl.Error(err))
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to update gym")))
+		return
+	}
+
+	log.Info("Gym updated", slog.Int64("gym_id", gymID))
+	c.JSON(http.StatusOK, response.OK("Gym updated"))
+}
+
+// DeleteGym godoc
+// @Summary      Удалить зал
+// @Description  Удаляет зал, если в нем нет клиентов, тарифов, абонементов и сотрудников
+// @Security BearerAuth
+// @Tags         gym
+// @Accept       json
+// @Produce      json
+// @Param        id  path     int  true  "ID зала"
+// @Success      200   {object}  response.Response "Зал удален"
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      404   {object}  response.Response "Зал не найден"
+// @Failure      409   {object}  response.Response "В зале есть связанные записи"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /gyms/delete/{id} [delete]
+func (h *GymHandler) DeleteGym(c *gin.Context) {
+	const op = "handlers.gym.deleteGym"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	gymID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		log.Error("failed to parse gym id", sl.Error(err))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "invalid gym id")))
+		return
+	}
+
+	if err := h.gymService.DeleteGym(c.Request.Context(), gymID); err != nil {
+		if errors.Is(err, gymService.ErrGymNotFound) {
+			c.JSON(http.StatusNotFound, response.Error(i18nMiddleware.T(c, "gym not found")))
+			return
+		}
+
+		if errors.Is(err, gymService.ErrGymInUse) {
+			c.JSON(http.StatusConflict, response.Error(i18nMiddleware.T(c, "gym has clients, tariffs, memberships or staff")))
+			return
+		}
+
+		log.Error("failed to delete gym", sl.Error(err))
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to delete gym")))
+		return
+	}
+
+	log.Info("Gym deleted", slog.Int64("gym_id", gymID))
+	c.JSON(http.StatusOK, response.OK("Gym deleted"))
+}
+
+// FindAllGyms godoc
+// @Summary      Получить все залы
+// @Description  Возвращает список всех залов сети
+// @Security BearerAuth
+// @Tags         gym
+// @Accept       json
+// @Produce      json
+// @Success      200   {array}   models.Gym
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /gyms [get]
+func (h *GymHandler) FindAllGyms(c *gin.Context) {
+	const op = "handlers.gym.findAllGyms"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	gyms, err := h.gymService.FindAllGyms(c.Request.Context())
+	if err != nil {
+		log.Error("failed to get gyms", sl.Error(err))
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to get gyms")))
+		return
+	}
+
+	c.JSON(http.StatusOK, gyms)
+}
@@ -0,0 +1,133 @@
+package healthHandler
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/requestctx"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Check reports whether a dependency of the app can serve requests.
+type Check func(ctx context.Context) error
+
+// Statuses of the health responses.
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+	StatusDraining    = "shutting down"
+)
+
+// HealthResponse is returned by the probes
+type HealthResponse struct {
+	Status string            `json:"status"`           // ok, unavailable или shutting down
+	Checks map[string]string `json:"checks,omitempty"` // Результат проверки каждой зависимости
+}
+
+type HealthHandler struct {
+	log      *slog.Logger
+	timeout  time.Duration
+	checks   map[string]Check
+	draining atomic.Bool
+}
+
+func New(log *slog.Logger, timeout time.Duration, checks map[string]Check) *HealthHandler {
+	return &HealthHandler{
+		log:     log,
+		timeout: timeout,
+		checks:  checks,
+	}
+}
+
+// Drain makes the readiness probe fail, so the app is taken out of load
+// balancing before the server stops accepting connections.
+func (h *HealthHandler) Drain() {
+	h.draining.Store(true)
+}
+
+// Liveness godoc
+// @Summary      Проверка жизнеспособности
+// @Description  Отвечает 200, пока процесс способен обрабатывать запросы. Зависимости не проверяются
+// @Tags         health
+// @Produce      json
+// @Success      200   {object}  HealthResponse
+// @Router       /healthz [get]
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, HealthResponse{Status: StatusOK})
+}
+
+// Readiness godoc
+// @Summary      Проверка готовности
+// @Description  Проверяет пул соединений с БД, соединение с SSO и планировщик заданий. Во время остановки сервера отвечает 503
+// @Tags         health
+// @Produce      json
+// @Success      200   {object}  HealthResponse
+// @Failure      503   {object}  HealthResponse "Зависимость недоступна или сервер останавливается"
+// @Router       /readyz [get]
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	const op = "handlers.health.readiness"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	if h.draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, HealthResponse{Status: StatusDraining})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.timeout)
+	defer cancel()
+
+	results := h.run(ctx)
+
+	resp := HealthResponse{Status: StatusOK, Checks: make(map[string]string, len(results))}
+	for name, err := range results {
+		if err == nil {
+			resp.Checks[name] = StatusOK
+			continue
+		}
+
+		log.Warn("dependency is not ready", slog.String("check", name), sl.Error(err))
+		resp.Checks[name] = err.Error()
+		resp.Status = StatusUnavailable
+	}
+
+	if resp.Status != StatusOK {
+		c.JSON(http.StatusServiceUnavailable, resp)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// run executes the checks concurrently, so one slow dependency does not
+// eat the timeout of the others.
+func (h *HealthHandler) run(ctx context.Context) map[string]error {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]error, len(h.checks))
+	)
+
+	for name, check := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := check(ctx)
+
+			mu.Lock()
+			results[name] = err
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	return results
+}
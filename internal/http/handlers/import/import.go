@@ -0,0 +1,174 @@
+package importHandler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	i18nMiddleware "gym_app/internal/http/middleware/i18n"
+	tenantMiddleware "gym_app/internal/http/middleware/tenant"
+	"gym_app/internal/lib/api/response"
+	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/requestctx"
+	"gym_app/internal/lib/spreadsheet"
+	"gym_app/internal/lib/validation"
+	"gym_app/internal/models"
+	importService "gym_app/internal/services/import"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// maxFileSize limits the uploaded file, enough for tens of thousands of rows
+const maxFileSize = 20 << 20
+
+type ImportService interface {
+	Import(ctx context.Context, gymID int64, file io.Reader, format string, opts models.ImportOptions) (models.ImportResult, error)
+	ErrorReport(w io.Writer, format, lang string, result models.ImportResult) error
+}
+
+type ImportHandler struct {
+	log           *slog.Logger
+	importService ImportService
+}
+
+func New(
+	log *slog.Logger,
+	importService ImportService,
+) *ImportHandler {
+	return &ImportHandler{
+		log:           log,
+		importService: importService,
+	}
+}
+
+// ImportPeople godoc
+// @Summary      Импорт клиентов и абонементов
+// @Description  Загружает клиентов и их абонементы из CSV или XLSX. Первая строка файла - заголовок.
+// @Description  Колонки: name, phone, subscription_id, number, start_date, end_date; другие названия задаются в mapping.
+// @Description  Клиенты, которые уже есть в зале или повторяются в файле (ФИО и телефон), не добавляются повторно.
+// @Description  Строки с ошибками пропускаются. С report ответ - файл с такими строками и колонкой ошибок.
+// @Security BearerAuth
+// @Tags         import
+// @Accept       multipart/form-data
+// @Produce      json
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param        file     formData  file    true   "Файл CSV или XLSX"
+// @Param        format   formData  string  false  "Формат файла (csv, xlsx), по умолчанию по расширению"
+// @Param        mapping  formData  string  false  "Сопоставление полей колонкам в JSON, например {\"name\":\"ФИО\",\"phone\":\"Телефон\"}"
+// @Param        dry_run  formData  bool    false  "Только проверить файл, ничего не сохраняя"
+// @Param        report   query     string  false  "Вернуть отчет об ошибках в формате csv или xlsx"
+// @Success      200  {object}  models.ImportResult "Итог импорта"
+// @Failure      400  {object}  response.Response "Некорректный файл или сопоставление"
+// @Failure      413  {object}  response.Response "Файл слишком большой"
+// @Failure      500  {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /import/people [post]
+func (h *ImportHandler) ImportPeople(c *gin.Context) {
+	const op = "handlers.import.importPeople"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxFileSize)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, response.Error(i18nMiddleware.T(c, "file is too large")))
+			return
+		}
+
+		log.Warn("no file in request", sl.Error(err))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "file is required")))
+		return
+	}
+
+	format, err := spreadsheet.FormatOf(fileHeader.Filename)
+	if name := c.PostForm("format"); name != "" {
+		format, err = spreadsheet.Format(name)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "unsupported file format, use csv or xlsx")))
+		return
+	}
+
+	var opts models.ImportOptions
+
+	if mapping := c.PostForm("mapping"); mapping != "" {
+		if err := json.Unmarshal([]byte(mapping), &opts.Mapping); err != nil {
+			log.Warn("failed to decode mapping", sl.Error(err))
+			c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "invalid column mapping")))
+			return
+		}
+	}
+
+	if opts.DryRun, err = strconv.ParseBool(c.DefaultPostForm("dry_run", "false")); err != nil {
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "failed to decode request")))
+		return
+	}
+
+	var reportFormat string
+	if name := c.Query("report"); name != "" {
+		if reportFormat, err = spreadsheet.Format(name); err != nil {
+			c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "unsupported file format, use csv or xlsx")))
+			return
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.Error("failed to open uploaded file", sl.Error(err))
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to import")))
+		return
+	}
+	defer file.Close()
+
+	result, err := h.importService.Import(c.Request.Context(), tenantMiddleware.GymID(c), file, format, opts)
+	if err != nil {
+		var errs validation.Errors
+
+		switch {
+		case errors.Is(err, importService.ErrInvalidMapping) && errors.As(err, &errs):
+			c.JSON(http.StatusBadRequest, response.ValidationError(i18nMiddleware.T(c, "validation failed"), errs.Localize(i18nMiddleware.Lang(c))))
+		case errors.Is(err, importService.ErrEmptyFile):
+			c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "file has no header row")))
+		case errors.Is(err, spreadsheet.ErrInvalidFile):
+			log.Warn("failed to read import file", sl.Error(err))
+			c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "failed to read import file")))
+		default:
+			log.Error("failed to import", sl.Error(err))
+			c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to import")))
+		}
+		return
+	}
+
+	log.Info("import finished",
+		slog.Int("total", result.Total),
+		slog.Int("failed", result.Failed),
+	)
+
+	if reportFormat != "" {
+		var buf bytes.Buffer
+		if err := h.importService.ErrorReport(&buf, reportFormat, i18nMiddleware.Lang(c), result); err != nil {
+			log.Error("failed to write error report", sl.Error(err))
+			c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to import")))
+			return
+		}
+
+		c.Header("Content-Disposition", `attachment; filename="import_errors.`+reportFormat+`"`)
+		c.Data(http.StatusOK, spreadsheet.ContentType(reportFormat), buf.Bytes())
+		return
+	}
+
+	lang := i18nMiddleware.Lang(c)
+	for i := range result.Rows {
+		result.Rows[i].Errors = result.Rows[i].Errors.Localize(lang)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
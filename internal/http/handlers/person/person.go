@@ -4,8 +4,11 @@ import (
 	"context"
 	"errors"
 	"github.com/gin-gonic/gin"
+	i18nMiddleware "gym_app/internal/http/middleware/i18n"
+	tenantMiddleware "gym_app/internal/http/middleware/tenant"
 	"gym_app/internal/lib/api/response"
 	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/requestctx"
 	"gym_app/internal/models"
 	personService "gym_app/internal/services/person"
 	"io"
@@ -15,26 +18,25 @@ import (
 )
 
 type PersonService interface {
-	AddPerson(ctx context.Context, person models.Person) (int, error)
-	FindAllPeople(ctx context.Context) ([]models.Person, error)
-	UpdatePerson(ctx context.Context, person models.Person, pID int) (int, error)
-	DeletePerson(ctx context.Context, pID int) error
-	FindPersonByName(ctx context.Context, name string) (models.Person, error)
+	AddPerson(ctx context.Context, gymID int64, person models.Person) (int, error)
+	FindAllPeople(ctx context.Context, gymID int64) ([]models.Person, error)
+	UpdatePerson(ctx context.Context, gymID int64, person models.Person, pID int) (int, error)
+	DeletePerson(ctx context.Context, gymID int64, pID int) error
+	FindPersonByName(ctx context.Context, gymID int64, name string) (models.Person, error)
+	FindDuplicates(ctx context.Context, gymID int64, minSimilarity float64) ([]models.DuplicateGroup, error)
+	MergePeople(ctx context.Context, gymID int64, fromID, toID int) (models.PersonMergeResult, error)
 }
 
 type PersonHandler struct {
-	ctx           context.Context
 	log           *slog.Logger
 	personService PersonService
 }
 
 func New(
-	ctx context.Context,
 	log *slog.Logger,
 	personService PersonService,
 ) *PersonHandler {
 	return &PersonHandler{
-		ctx:           ctx,
 		log:           log,
 		personService: personService,
 	}
@@ -56,7 +58,7 @@ func New(
 func (h *PersonHandler) AddPerson(c *gin.Context) {
 	const op = "handlers.person.addPerson"
 
-	log := h.log.With(
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
 		slog.String("op", op),
 	)
 
@@ -66,31 +68,31 @@ func (h *PersonHandler) AddPerson(c *gin.Context) {
 		if errors.Is(err, io.EOF) {
 			log.Error("request body is empty")
 
-			c.JSON(http.StatusBadRequest, response.Error("empty request"))
+			c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "empty request")))
 			return
 		}
 
 		log.Error("failed to decode request body", sl.Error(err))
-		c.JSON(http.StatusBadRequest, response.Error("failed to decode request"))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "failed to decode request")))
 		return
 	}
 
-	if err := person.Validate(); err != nil {
-		log.Error("failed to validate person", err)
+	if errs := person.Validate(); errs != nil {
+		log.Warn("failed to validate person", sl.Error(errs))
 
-		c.JSON(http.StatusBadRequest, err)
+		c.JSON(http.StatusBadRequest, response.ValidationError(i18nMiddleware.T(c, "validation failed"), errs.Localize(i18nMiddleware.Lang(c))))
 		return
 	}
 
-	personId, err := h.personService.AddPerson(h.ctx, person)
+	personId, err := h.personService.AddPerson(c.Request.Context(), tenantMiddleware.GymID(c), person)
 	if err != nil {
 		if errors.Is(err, personService.ErrPersonExists) {
-			c.JSON(http.StatusConflict, response.Error("person already exists"))
+			c.JSON(http.StatusConflict, response.Error(i18nMiddleware.T(c, "person already exists")))
 			return
 		}
 
 		log.Error("failed to add person", sl.Error(err))
-		c.JSON(http.StatusInternalServerError, response.Error("failed to add person"))
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to add person")))
 		return
 	}
 
@@ -115,7 +117,7 @@ func (h *PersonHandler) AddPerson(c *gin.Context) {
 func (h *PersonHandler) UpdatePerson(c *gin.Context) {
 	const op = "handlers.person.updatePerson"
 
-	log := h.log.With(
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
 		slog.String("op", op),
 	)
 
@@ -123,14 +125,14 @@ func (h *PersonHandler) UpdatePerson(c *gin.Context) {
 	if pIDStr == "" {
 		log.Error("person id parameter is missing")
 
-		c.JSON(http.StatusBadRequest, response.Error("person id parameter is required"))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "person id parameter is required")))
 		return
 	}
 	pID, err := strconv.Atoi(pIDStr)
 	if err != nil {
 		log.Error("failed to parse person id", sl.Error(err))
 
-		c.JSON(http.StatusBadRequest, response.Error("invalid person id"))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "invalid person id")))
 		return
 	}
 
@@ -139,36 +141,36 @@ func (h *PersonHandler) UpdatePerson(c *gin.Context) {
 		if errors.Is(err, io.EOF) {
 			log.Error("request body is empty")
 
-			c.JSON(http.StatusBadRequest, response.Error("empty request"))
+			c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "empty request")))
 			return
 		}
 
 		log.Error("failed to decode request body", sl.Error(err))
-		c.JSON(http.StatusBadRequest, response.Error("failed to decode request"))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "failed to decode request")))
 		return
 	}
 
-	if err := person.Validate(); err != nil {
-		log.Error("failed to validate person", err)
+	if errs := person.Validate(); errs != nil {
+		log.Warn("failed to validate person", sl.Error(errs))
 
-		c.JSON(http.StatusBadRequest, err)
+		c.JSON(http.StatusBadRequest, response.ValidationError(i18nMiddleware.T(c, "validation failed"), errs.Localize(i18nMiddleware.Lang(c))))
 		return
 	}
 
-	personId, err := h.personService.UpdatePerson(h.ctx, person, pID)
+	personId, err := h.personService.UpdatePerson(c.Request.Context(), tenantMiddleware.GymID(c), person, pID)
 	if err != nil {
 		if errors.Is(err, personService.ErrPersonNotFound) {
-			c.JSON(http.StatusNotFound, response.Error("person not found"))
+			c.JSON(http.StatusNotFound, response.Error(i18nMiddleware.T(c, "person not found")))
 			return
 		}
 
 		if errors.Is(err, personService.ErrPersonExists) {
-			c.JSON(http.StatusConflict, response.Error("Person with such name and phone already exists. Set another name or phone"))
+			c.JSON(http.StatusConflict, response.Error(i18nMiddleware.T(c, "Person with such name and phone already exists. Set another name or phone")))
 			return
 		}
 
 		log.Error("failed to update person", sl.Error(err))
-		c.JSON(http.StatusInternalServerError, response.Error("failed to update person"))
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to update person")))
 		return
 	}
 
@@ -192,7 +194,7 @@ func (h *PersonHandler) UpdatePerson(c *gin.Context) {
 func (h *PersonHandler) DeletePerson(c *gin.Context) {
 	const op = "handlers.person.deletePerson"
 
-	log := h.log.With(
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
 		slog.String("op", op),
 	)
 
@@ -200,26 +202,26 @@ func (h *PersonHandler) DeletePerson(c *gin.Context) {
 	if pIDStr == "" {
 		log.Error("person id parameter is missing")
 
-		c.JSON(http.StatusBadRequest, response.Error("person id parameter is required"))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "person id parameter is required")))
 		return
 	}
 	pID, err := strconv.Atoi(pIDStr)
 	if err != nil {
 		log.Error("failed to parse person id", sl.Error(err))
 
-		c.JSON(http.StatusBadRequest, response.Error("invalid person id"))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "invalid person id")))
 		return
 	}
 
-	err = h.personService.DeletePerson(h.ctx, pID)
+	err = h.personService.DeletePerson(c.Request.Context(), tenantMiddleware.GymID(c), pID)
 	if err != nil {
 		if errors.Is(err, personService.ErrPersonNotFound) {
-			c.JSON(http.StatusNotFound, response.Error("person not found"))
+			c.JSON(http.StatusNotFound, response.Error(i18nMiddleware.T(c, "person not found")))
 			return
 		}
 
 		log.Error("failed to delete person", sl.Error(err))
-		c.JSON(http.StatusInternalServerError, response.Error("failed to delete person"))
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to delete person")))
 		return
 	}
 
@@ -243,7 +245,7 @@ func (h *PersonHandler) DeletePerson(c *gin.Context) {
 func (h *PersonHandler) FindPersonByName(c *gin.Context) {
 	const op = "handlers.person.findPersonByName"
 
-	log := h.log.With(
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
 		slog.String("op", op),
 	)
 
@@ -251,19 +253,19 @@ func (h *PersonHandler) FindPersonByName(c *gin.Context) {
 	if name == "" {
 		log.Error("name parameter is missing")
 
-		c.JSON(http.StatusBadRequest, response.Error("name parameter is required"))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "name parameter is required")))
 		return
 	}
 
-	person, err := h.personService.FindPersonByName(h.ctx, name)
+	person, err := h.personService.FindPersonByName(c.Request.Context(), tenantMiddleware.GymID(c), name)
 	if err != nil {
 		if errors.Is(err, personService.ErrPersonNotFound) {
-			c.JSON(http.StatusNotFound, response.Error("person not found"))
+			c.JSON(http.StatusNotFound, response.Error(i18nMiddleware.T(c, "person not found")))
 			return
 		}
 
 		log.Error("failed to find person", sl.Error(err))
-		c.JSON(http.StatusInternalServerError, response.Error("failed to find person"))
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to find person")))
 		return
 	}
 
@@ -284,15 +286,15 @@ func (h *PersonHandler) FindPersonByName(c *gin.Context) {
 func (h *PersonHandler) FindAllPeople(c *gin.Context) {
 	const op = "handlers.person.findAllPeople"
 
-	log := h.log.With(
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
 		slog.String("op", op),
 	)
 
-	people, err := h.personService.FindAllPeople(h.ctx)
+	people, err := h.personService.FindAllPeople(c.Request.Context(), tenantMiddleware.GymID(c))
 	if err != nil {
 		log.Error("failed to get people", sl.Error(err))
 
-		c.JSON(http.StatusInternalServerError, response.Error("failed to get people"))
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to get people")))
 		return
 	}
 
@@ -300,3 +302,105 @@ func (h *PersonHandler) FindAllPeople(c *gin.Context) {
 
 	c.JSON(http.StatusOK, people)
 }
+
+// FindDuplicates godoc
+// @Summary Find duplicate people
+// @Description Groups people with the same phone and similar names, e.g. "Иванов Иван" and "Иван Иванов"
+// @Security BearerAuth
+// @Tags person
+// @Accept json
+// @Produce json
+// @Param similarity query number false "Lowest name similarity from 0 to 1, 0.8 by default"
+// @Success 200 {array} models.DuplicateGroup "Duplicate groups"
+// @Failure 400 {object} response.Response "Bad request"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /people/duplicates [get]
+func (h *PersonHandler) FindDuplicates(c *gin.Context) {
+	const op = "handlers.person.findDuplicates"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	similarity := personService.DefaultSimilarity
+	if s := c.Query("similarity"); s != "" {
+		var err error
+		if similarity, err = strconv.ParseFloat(s, 64); err != nil || similarity < 0 || similarity > 1 {
+			c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "similarity must be a number from 0 to 1")))
+			return
+		}
+	}
+
+	groups, err := h.personService.FindDuplicates(c.Request.Context(), tenantMiddleware.GymID(c), similarity)
+	if err != nil {
+		log.Error("failed to find duplicates", sl.Error(err))
+
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to find duplicates")))
+		return
+	}
+
+	log.Info("Duplicates found", slog.Int("groups", len(groups)))
+
+	c.JSON(http.StatusOK, groups)
+}
+
+// MergePeople godoc
+// @Summary Merge duplicate people
+// @Description Moves memberships, class bookings and training packages of from_id to to_id and deletes from_id.
+// @Description Everything is done in one transaction. Bookings from_id has for a class to_id is already booked for are dropped.
+// @Security BearerAuth
+// @Tags person
+// @Accept json
+// @Produce json
+// @Param merge body models.PersonMerge true "People to merge"
+// @Success 200 {object} models.PersonMergeResult "People merged"
+// @Failure 400 {object} response.Response "Bad request"
+// @Failure 404 {object} response.Response "Not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /people/merge [post]
+func (h *PersonHandler) MergePeople(c *gin.Context) {
+	const op = "handlers.person.mergePeople"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	var merge models.PersonMerge
+	if err := c.ShouldBindJSON(&merge); err != nil {
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+
+			c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "empty request")))
+			return
+		}
+
+		log.Error("failed to decode request body", sl.Error(err))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "failed to decode request")))
+		return
+	}
+
+	if errs := merge.Validate(); errs != nil {
+		log.Warn("failed to validate merge", sl.Error(errs))
+
+		c.JSON(http.StatusBadRequest, response.ValidationError(i18nMiddleware.T(c, "validation failed"), errs.Localize(i18nMiddleware.Lang(c))))
+		return
+	}
+
+	result, err := h.personService.MergePeople(c.Request.Context(), tenantMiddleware.GymID(c), merge.FromID, merge.ToID)
+	if err != nil {
+		switch {
+		case errors.Is(err, personService.ErrPersonNotFound):
+			c.JSON(http.StatusNotFound, response.Error(i18nMiddleware.T(c, "person not found")))
+		case errors.Is(err, personService.ErrSamePerson):
+			c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "cannot merge a person with itself")))
+		default:
+			log.Error("failed to merge people", sl.Error(err))
+			c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to merge people")))
+		}
+		return
+	}
+
+	log.Info("People merged", slog.Int("from_id", merge.FromID), slog.Int("to_id", merge.ToID))
+
+	c.JSON(http.StatusOK, result)
+}
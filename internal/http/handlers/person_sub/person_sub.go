@@ -4,8 +4,12 @@ import (
 	"context"
 	"errors"
 	"github.com/gin-gonic/gin"
+	i18nMiddleware "gym_app/internal/http/middleware/i18n"
+	tenantMiddleware "gym_app/internal/http/middleware/tenant"
 	"gym_app/internal/lib/api/response"
+	"gym_app/internal/lib/cardnumber"
 	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/requestctx"
 	"gym_app/internal/models"
 	personSubService "gym_app/internal/services/person_sub"
 	"io"
@@ -14,23 +18,25 @@ import (
 )
 
 type PersonSubService interface {
-	AddPersonSub(ctx context.Context, personSubStrDate models.PersonSubStrDate) (string, error)
-	GetPersonSubByNumber(ctx context.Context, number string) (models.PersonSubStrDate, error)
-	GetAllPersonSubs(ctx context.Context) ([]models.PersonSubStrDate, error)
-	DeletePersonSub(ctx context.Context, number string) error
-	FindPersonSubByPersonName(ctx context.Context, name string) ([]models.PersonSubStrDate, error)
-	//UpdatePersonSub(ctx context.Context, number string, personSubStrDate models.PersonSubStrDate) error
+	AddPersonSub(ctx context.Context, gymID int64, personSubStrDate models.PersonSubStrDate) (string, error)
+	GetPersonSubByNumber(ctx context.Context, gymID int64, number string) (models.PersonSubStrDate, error)
+	GetAllPersonSubs(ctx context.Context, gymID int64) ([]models.PersonSubStrDate, error)
+	DeletePersonSub(ctx context.Context, gymID int64, number string) error
+	FindPersonSubByPersonName(ctx context.Context, gymID int64, name string) ([]models.PersonSubStrDate, error)
+	UpdatePersonSub(ctx context.Context, gymID int64, number string, update models.PersonSubUpdate) (models.PersonSubStrDate, error)
+	ReplaceCard(ctx context.Context, gymID int64, number string, replacement models.CardReplacement) error
+	FindCardHistory(ctx context.Context, gymID int64, number string) ([]models.CardNumberChange, error)
+	CheckIn(ctx context.Context, gymID int64, number string) (models.PersonSubStrDate, error)
+	CardImage(ctx context.Context, gymID int64, number, kind, format string) ([]byte, error)
 }
 
 type PersonSubHandler struct {
-	ctx              context.Context
 	log              *slog.Logger
 	personSubService PersonSubService
 }
 
-func New(ctx context.Context, log *slog.Logger, personSubService PersonSubService) *PersonSubHandler {
+func New(log *slog.Logger, personSubService PersonSubService) *PersonSubHandler {
 	return &PersonSubHandler{
-		ctx:              ctx,
 		log:              log,
 		personSubService: personSubService,
 	}
@@ -53,7 +59,7 @@ func (h *PersonSubHandler) AddPersonSub(c *gin.Context) {
 
 	const op = "handlers.personSub.addPersonSub"
 
-	log := h.log.With(
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
 		slog.String("op", op),
 	)
 
@@ -63,36 +69,36 @@ func (h *PersonSubHandler) AddPersonSub(c *gin.Context) {
 		if errors.Is(err, io.EOF) {
 			log.Error("request body is empty")
 
-			c.JSON(http.StatusBadRequest, response.Error("empty request"))
+			c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "empty request")))
 			return
 		}
 
 		log.Error("failed to decode request body", sl.Error(err))
-		c.JSON(http.StatusBadRequest, response.Error("failed to decode request"))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "failed to decode request")))
 		return
 	}
 
-	if err := personSubStrDate.Validate(); err != nil {
-		log.Error("failed to validate person subscription", err)
-		c.JSON(http.StatusBadRequest, err)
+	if errs := personSubStrDate.Validate(); errs != nil {
+		log.Warn("failed to validate person subscription", sl.Error(errs))
+		c.JSON(http.StatusBadRequest, response.ValidationError(i18nMiddleware.T(c, "validation failed"), errs.Localize(i18nMiddleware.Lang(c))))
 		return
 	}
 
-	personSubNumber, err := h.personSubService.AddPersonSub(h.ctx, personSubStrDate)
+	personSubNumber, err := h.personSubService.AddPersonSub(c.Request.Context(), tenantMiddleware.GymID(c), personSubStrDate)
 	if err != nil {
 
 		if errors.Is(err, personSubService.ErrSubExists) {
-			c.JSON(http.StatusConflict, gin.H{"error": "subscription with that number already exists"})
+			c.JSON(http.StatusConflict, response.Error(i18nMiddleware.T(c, "subscription with that number already exists")))
 			return
 		}
 
 		if errors.Is(err, personSubService.ErrPersonNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "person with this id not found"})
+			c.JSON(http.StatusNotFound, response.Error(i18nMiddleware.T(c, "person or tariff not found in this gym")))
 			return
 		}
 
 		log.Error("failed to add person subscription", sl.Error(err))
-		c.JSON(http.StatusInternalServerError, response.Error("failed to add person subscription"))
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to add person subscription")))
 		return
 	}
 
@@ -101,6 +107,254 @@ func (h *PersonSubHandler) AddPersonSub(c *gin.Context) {
 
 }
 
+// UpdatePersonSub godoc
+// @Summary      Изменить абонемент
+// @Description  Изменяет тариф, даты и номер карты абонемента. PUT заменяет все поля, PATCH - только переданные.
+// @Description  При смене тарифа без даты окончания она рассчитывается по сроку тарифа, статус пересчитывается.
+// @Description  Новый номер карты (при утере) выдается как при replace_card: старый номер сохраняется в истории и блокируется для входа
+// @Security BearerAuth
+// @Tags         person_sub
+// @Accept       json
+// @Produce      json
+// @Param        number  path     string                  true  "Номер абонемента"
+// @Param        update  body     models.PersonSubUpdate  true  "Изменения"
+// @Success      200   {object}  models.PersonSubStrDate
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      404   {object}  response.Response "Абонемент или тариф не найден"
+// @Failure      409   {object}  response.Response "Номер карты уже занят"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /person_sub/update/{number} [put]
+// @Router       /person_sub/update/{number} [patch]
+func (h *PersonSubHandler) UpdatePersonSub(c *gin.Context) {
+	const op = "handlers.personSub.updatePersonSub"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	number := c.Param("number")
+
+	var update models.PersonSubUpdate
+
+	if err := c.ShouldBindJSON(&update); err != nil {
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+
+			c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "empty request")))
+			return
+		}
+
+		log.Error("failed to decode request body", sl.Error(err))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "failed to decode request")))
+		return
+	}
+
+	if errs := update.Validate(c.Request.Method == http.MethodPatch); errs != nil {
+		log.Warn("failed to validate person subscription update", sl.Error(errs))
+		c.JSON(http.StatusBadRequest, response.ValidationError(i18nMiddleware.T(c, "validation failed"), errs.Localize(i18nMiddleware.Lang(c))))
+		return
+	}
+
+	personSubStrDate, err := h.personSubService.UpdatePersonSub(c.Request.Context(), tenantMiddleware.GymID(c), number, update)
+	if err != nil {
+		switch {
+		case errors.Is(err, personSubService.ErrInvalidDate):
+			c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "date must be in dd-mm-yyyy format")))
+		case errors.Is(err, personSubService.ErrInvalidPeriod):
+			c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "end date must not be before start date")))
+		case errors.Is(err, personSubService.ErrSubNotFound):
+			c.JSON(http.StatusNotFound, response.Error(i18nMiddleware.T(c, "subscription not found")))
+		case errors.Is(err, personSubService.ErrPlanNotFound):
+			c.JSON(http.StatusNotFound, response.Error(i18nMiddleware.T(c, "subscription plan not found in this gym")))
+		case errors.Is(err, personSubService.ErrSubExists):
+			c.JSON(http.StatusConflict, response.Error(i18nMiddleware.T(c, "subscription with that number already exists")))
+		default:
+			log.Error("failed to update person subscription", sl.Error(err))
+			c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to update person subscription")))
+		}
+		return
+	}
+
+	log.Info("person subscription updated", slog.String("number", number))
+	c.JSON(http.StatusOK, personSubStrDate)
+}
+
+// ReplaceCard godoc
+// @Summary      Перевыпустить карту
+// @Description  Выдает новый номер карты для абонемента. Старый номер сохраняется в истории и блокируется для входа
+// @Security BearerAuth
+// @Tags         person_sub
+// @Accept       json
+// @Produce      json
+// @Param        number       path     string                  true  "Текущий номер абонемента"
+// @Param        replacement  body     models.CardReplacement  true  "Новая карта"
+// @Success      200   {object}  response.Response "Карта перевыпущена"
+// @Failure      400   {object}  response.Response "Ошибка валидации или номер совпадает с текущим"
+// @Failure      404   {object}  response.Response "Абонемент не найден"
+// @Failure      409   {object}  response.Response "Номер уже занят или отозван"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /person_sub/replace_card/{number} [post]
+func (h *PersonSubHandler) ReplaceCard(c *gin.Context) {
+	const op = "handlers.personSub.replaceCard"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	number := c.Param("number")
+
+	var replacement models.CardReplacement
+
+	if err := c.ShouldBindJSON(&replacement); err != nil {
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+
+			c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "empty request")))
+			return
+		}
+
+		log.Error("failed to decode request body", sl.Error(err))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "failed to decode request")))
+		return
+	}
+
+	if errs := replacement.Validate(); errs != nil {
+		log.Warn("failed to validate card replacement", sl.Error(errs))
+		c.JSON(http.StatusBadRequest, response.ValidationError(i18nMiddleware.T(c, "validation failed"), errs.Localize(i18nMiddleware.Lang(c))))
+		return
+	}
+
+	err := h.personSubService.ReplaceCard(c.Request.Context(), tenantMiddleware.GymID(c), number, replacement)
+	if err != nil {
+		switch {
+		case errors.Is(err, personSubService.ErrSameCardNumber):
+			c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "new card number must differ from the current one")))
+		case errors.Is(err, personSubService.ErrSubNotFound):
+			c.JSON(http.StatusNotFound, response.Error(i18nMiddleware.T(c, "subscription not found")))
+		case errors.Is(err, personSubService.ErrCardRevoked):
+			c.JSON(http.StatusConflict, response.Error(i18nMiddleware.T(c, "card number has been revoked")))
+		case errors.Is(err, personSubService.ErrSubExists):
+			c.JSON(http.StatusConflict, response.Error(i18nMiddleware.T(c, "subscription with that number already exists")))
+		default:
+			log.Error("failed to replace card", sl.Error(err))
+			c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to replace card")))
+		}
+		return
+	}
+
+	log.Info("card replaced", slog.String("number", number), slog.String("new_number", replacement.NewNumber))
+	c.JSON(http.StatusOK, response.OK(replacement.NewNumber))
+}
+
+// FindCardHistory godoc
+// @Summary      Получить историю номеров карты
+// @Description  Возвращает отозванные номера карт абонемента по текущему или старому номеру
+// @Security BearerAuth
+// @Tags         person_sub
+// @Accept       json
+// @Produce      json
+// @Param        number  path     string  true  "Номер абонемента"
+// @Success      200   {array}   models.CardNumberChange
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /person_sub/history/{number} [get]
+func (h *PersonSubHandler) FindCardHistory(c *gin.Context) {
+	const op = "handlers.personSub.findCardHistory"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	history, err := h.personSubService.FindCardHistory(c.Request.Context(), tenantMiddleware.GymID(c), c.Param("number"))
+	if err != nil {
+		log.Error("failed to get card history", sl.Error(err))
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to get card history")))
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// CheckIn godoc
+// @Summary      Проверить карту на входе
+// @Description  Проверяет карту клиента на входе: номер не должен быть отозван, абонемент должен быть активен
+// @Security BearerAuth
+// @Tags         person_sub
+// @Accept       json
+// @Produce      json
+// @Param        number  path     string  true  "Номер карты"
+// @Success      200   {object}  models.PersonSubStrDate
+// @Failure      403   {object}  response.Response "Карта отозвана или абонемент не активен"
+// @Failure      404   {object}  response.Response "Абонемент не найден"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /person_sub/check_in/{number} [post]
+func (h *PersonSubHandler) CheckIn(c *gin.Context) {
+	const op = "handlers.personSub.checkIn"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	personSubStrDate, err := h.personSubService.CheckIn(c.Request.Context(), tenantMiddleware.GymID(c), c.Param("number"))
+	if err != nil {
+		switch {
+		case errors.Is(err, personSubService.ErrCardRevoked):
+			c.JSON(http.StatusForbidden, response.Error(i18nMiddleware.T(c, "card number has been revoked")))
+		case errors.Is(err, personSubService.ErrSubNotActive):
+			c.JSON(http.StatusForbidden, response.Error(i18nMiddleware.T(c, "subscription is not active")))
+		case errors.Is(err, personSubService.ErrSubNotFound):
+			c.JSON(http.StatusNotFound, response.Error(i18nMiddleware.T(c, "subscription not found")))
+		default:
+			log.Error("failed to check in", sl.Error(err))
+			c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to check in")))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, personSubStrDate)
+}
+
+// CardImage godoc
+// @Summary      Получить штрихкод карты
+// @Description  Возвращает номер карты абонемента в виде штрихкода Code128 или QR-кода для печати
+// @Security BearerAuth
+// @Tags         person_sub
+// @Produce      png
+// @Produce      svg
+// @Param        number  path     string  true   "Номер абонемента"
+// @Param        kind    query    string  false  "Вид кода: code128 (по умолчанию) или qr"
+// @Param        format  query    string  false  "Формат: png (по умолчанию) или svg"
+// @Success      200   {file}    file
+// @Failure      400   {object}  response.Response "Неизвестный вид кода или формат"
+// @Failure      404   {object}  response.Response "Абонемент не найден"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /person_sub/card/{number} [get]
+func (h *PersonSubHandler) CardImage(c *gin.Context) {
+	const op = "handlers.personSub.cardImage"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	kind := c.DefaultQuery("kind", cardnumber.KindCode128)
+	format := c.DefaultQuery("format", cardnumber.FormatPNG)
+
+	image, err := h.personSubService.CardImage(c.Request.Context(), tenantMiddleware.GymID(c), c.Param("number"), kind, format)
+	if err != nil {
+		switch {
+		case errors.Is(err, personSubService.ErrInvalidImage):
+			c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "unknown barcode kind or image format")))
+		case errors.Is(err, personSubService.ErrSubNotFound):
+			c.JSON(http.StatusNotFound, response.Error(i18nMiddleware.T(c, "subscription not found")))
+		default:
+			log.Error("failed to render card", sl.Error(err))
+			c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to render card")))
+		}
+		return
+	}
+
+	c.Data(http.StatusOK, cardnumber.ContentType(format), image)
+}
+
 // DeletePersonSub godoc
 // @Summary      Удалить абонемент
 // @Description  Удаляет абонемент по номеру
@@ -117,22 +371,22 @@ func (h *PersonSubHandler) AddPersonSub(c *gin.Context) {
 func (h *PersonSubHandler) DeletePersonSub(c *gin.Context) {
 	const op = "handlers.personSub.deletePersonSub"
 
-	log := h.log.With(
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
 		slog.String("op", op),
 	)
 
 	number := c.Param("number")
 
-	if err := h.personSubService.DeletePersonSub(h.ctx, number); err != nil {
+	if err := h.personSubService.DeletePersonSub(c.Request.Context(), tenantMiddleware.GymID(c), number); err != nil {
 
 		if errors.Is(err, personSubService.ErrSubNotFound) {
 			log.Error("subscription not found", sl.Error(err))
-			c.JSON(http.StatusNotFound, response.Error("subscription not found"))
+			c.JSON(http.StatusNotFound, response.Error(i18nMiddleware.T(c, "subscription not found")))
 			return
 		}
 
 		log.Error("failed to delete person subscription", sl.Error(err))
-		c.JSON(http.StatusInternalServerError, response.Error("failed to delete person subscription"))
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to delete person subscription")))
 		return
 	}
 
@@ -150,20 +404,26 @@ func (h *PersonSubHandler) DeletePersonSub(c *gin.Context) {
 // @Param        number  path     string  true  "Номер абонемента"
 // @Success      200   {array}   models.PersonSubscription
 // @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      404   {object}  response.Response "Абонемент не найден"
 // @Router       /person_sub/find/{number} [get]
 func (h *PersonSubHandler) FindPersonSubByNumber(c *gin.Context) {
 	const op = "handlers.personSub.getPersonSubByNumber"
 
-	log := h.log.With(
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
 		slog.String("op", op),
 	)
 
 	number := c.Param("number")
 
-	personSubStrDate, err := h.personSubService.GetPersonSubByNumber(h.ctx, number)
+	personSubStrDate, err := h.personSubService.GetPersonSubByNumber(c.Request.Context(), tenantMiddleware.GymID(c), number)
 	if err != nil {
+		if errors.Is(err, personSubService.ErrSubNotFound) {
+			c.JSON(http.StatusNotFound, response.Error(i18nMiddleware.T(c, "subscription not found")))
+			return
+		}
+
 		log.Error("failed to get person subscription by number", sl.Error(err))
-		c.JSON(http.StatusInternalServerError, response.Error("failed to get person subscription"))
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to get person subscription")))
 		return
 	}
 
@@ -183,14 +443,14 @@ func (h *PersonSubHandler) FindPersonSubByNumber(c *gin.Context) {
 func (h *PersonSubHandler) FindAllPersonSubs(c *gin.Context) {
 	const op = "handlers.personSub.getAllPersonSubs"
 
-	log := h.log.With(
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
 		slog.String("op", op),
 	)
 
-	personSubs, err := h.personSubService.GetAllPersonSubs(h.ctx)
+	personSubs, err := h.personSubService.GetAllPersonSubs(c.Request.Context(), tenantMiddleware.GymID(c))
 	if err != nil {
 		log.Error("failed to get all person subscriptions", sl.Error(err))
-		c.JSON(http.StatusInternalServerError, response.Error("failed to get all person subscriptions"))
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to get all person subscriptions")))
 		return
 	}
 
@@ -214,21 +474,21 @@ func (h *PersonSubHandler) FindAllPersonSubs(c *gin.Context) {
 func (h *PersonSubHandler) FindPersonSubByPersonName(c *gin.Context) {
 	const op = "handlers.personSub.getPersonSubByPersonName"
 
-	log := h.log.With(
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
 		slog.String("op", op),
 	)
 
 	name := c.Query("name")
 	if name == "" {
 		log.Error("name parameter is missing")
-		c.JSON(http.StatusBadRequest, response.Error("name parameter is required"))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "name parameter is required")))
 		return
 	}
 
-	personSubs, err := h.personSubService.FindPersonSubByPersonName(h.ctx, name)
+	personSubs, err := h.personSubService.FindPersonSubByPersonName(c.Request.Context(), tenantMiddleware.GymID(c), name)
 	if err != nil {
 		log.Error("failed to find person subscription by person name", sl.Error(err))
-		c.JSON(http.StatusInternalServerError, response.Error("failed to find person subscription"))
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to find person subscription")))
 		return
 	}
 
@@ -239,14 +499,14 @@ func (h *PersonSubHandler) FindPersonSubByPersonName(c *gin.Context) {
 //func (h *PersonSubHandler) UpdatePersonSub(c *gin.Context) {
 //	const op = "handlers.personSub.UpdatePersonSub"
 //
-//	log := h.log.With(
+//	log := requestctx.Logger(c.Request.Context(), h.log).With(
 //		slog.String("op", op),
 //	)
 //
 //	number := c.Param("number")
 //	if number == "" {
 //		log.Error("number parameter is missing")
-//		c.JSON(http.StatusBadRequest, response.Error("number parameter is required"))
+//		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "number parameter is required")))
 //		return
 //	}
 //
@@ -254,25 +514,25 @@ func (h *PersonSubHandler) FindPersonSubByPersonName(c *gin.Context) {
 //	if err := c.ShouldBindJSON(&personSubStrDate); err != nil {
 //		if errors.Is(err, io.EOF) {
 //			log.Error("request body is empty")
-//			c.JSON(http.StatusBadRequest, response.Error("empty request"))
+//			c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "empty request")))
 //			return
 //		}
 //
 //		log.Error("failed to decode request body", sl.Error(err))
-//		c.JSON(http.StatusBadRequest, response.Error("failed to decode request"))
+//		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "failed to decode request")))
 //		return
 //	}
 //
-//	err := h.personSubService.UpdatePersonSub(h.ctx, number, personSubStrDate)
+//	err := h.personSubService.UpdatePersonSub(c.Request.Context(), number, personSubStrDate)
 //	if err != nil {
 //		if errors.Is(err, personSubService.ErrSubNotFound) {
 //			log.Error("subscription not found", sl.Error(err))
-//			c.JSON(http.StatusNotFound, response.Error("subscription not found"))
+//			c.JSON(http.StatusNotFound, response.Error(i18nMiddleware.T(c, "subscription not found")))
 //			return
 //		}
 //
 //		log.Error("failed to update person subscription", sl.Error(err))
-//		c.JSON(http.StatusInternalServerError, response.Error("failed to update person subscription"))
+//		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to update person subscription")))
 //		return
 //	}
 //
@@ -0,0 +1,258 @@
+package staffHandler
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	i18nMiddleware "gym_app/internal/http/middleware/i18n"
+	"gym_app/internal/lib/api/response"
+	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/requestctx"
+	"gym_app/internal/models"
+	staffService "gym_app/internal/services/staff"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type StaffService interface {
+	AddStaff(ctx context.Context, staff models.Staff) (int64, error)
+	UpdateStaff(ctx context.Context, staff models.Staff, staffID int64) error
+	DeleteStaff(ctx context.Context, staffID int64) error
+	FindAllStaff(ctx context.Context, gymID int64) ([]models.Staff, error)
+}
+
+type StaffHandler struct {
+	log          *slog.Logger
+	staffService StaffService
+}
+
+func New(log *slog.Logger, staffService StaffService) *StaffHandler {
+	return &StaffHandler{
+		log:          log,
+		staffService: staffService,
+	}
+}
+
+// AddStaff godoc
+// @Summary      Добавить сотрудника
+// @Description  Связывает пользователя SSO с сотрудником зала
+// @Security BearerAuth
+// @Tags         staff
+// @Accept       json
+// @Produce      json
+// @Param        staff  body     models.Staff  true  "Сотрудник"
+// @Success      200   {object}  response.Response "Сотрудник добавлен"
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      404   {object}  response.Response "Зал не найден"
+// @Failure      409   {object}  response.Response "Конфликт"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /staff/add [post]
+func (h *StaffHandler) AddStaff(c *gin.Context) {
+	const op = "handlers.staff.addStaff"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	var staff models.Staff
+
+	if err := c.ShouldBindJSON(&staff); err != nil {
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+
+			c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "empty request")))
+			return
+		}
+
+		log.Error("failed to decode request body", sl.Error(err))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "failed to decode request")))
+		return
+	}
+
+	if errs := staff.Validate(); errs != nil {
+		log.Warn("failed to validate staff member", sl.Error(errs))
+
+		c.JSON(http.StatusBadRequest, response.ValidationError(i18nMiddleware.T(c, "validation failed"), errs.Localize(i18nMiddleware.Lang(c))))
+		return
+	}
+
+	staffID, err := h.staffService.AddStaff(c.Request.Context(), staff)
+	if err != nil {
+		if errors.Is(err, staffService.ErrStaffExists) {
+			c.JSON(http.StatusConflict, response.Error(i18nMiddleware.T(c, "staff member with that user id already exists")))
+			return
+		}
+
+		if errors.Is(err, staffService.ErrGymNotFound) {
+			c.JSON(http.StatusNotFound, response.Error(i18nMiddleware.T(c, "gym not found")))
+			return
+		}
+
+		log.Error("failed to add staff member", sl.Error(err))
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to add staff member")))
+		return
+	}
+
+	log.Info("Staff member added", slog.Int64("staff_id", staffID))
+	c.JSON(http.StatusOK, response.OK("Staff member added, staffId: "+strconv.FormatInt(staffID, 10)))
+}
+
+// UpdateStaff godoc
+// @Summary      Обновить сотрудника
+// @Description  Обновляет ФИО, должность, зал или пользователя SSO сотрудника
+// @Security BearerAuth
+// @Tags         staff
+// @Accept       json
+// @Produce      json
+// @Param        id     path     int           true  "ID сотрудника"
+// @Param        staff  body     models.Staff  true  "Сотрудник"
+// @Success      200   {object}  response.Response "Сотрудник обновлен"
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      404   {object}  response.Response "Сотрудник или зал не найден"
+// @Failure      409   {object}  response.Response "Конфликт"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /staff/update/{id} [put]
+func (h *StaffHandler) UpdateStaff(c *gin.Context) {
+	const op = "handlers.staff.updateStaff"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	staffID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		log.Error("failed to parse staff id", sl.Error(err))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "invalid staff id")))
+		return
+	}
+
+	var staff models.Staff
+
+	if err := c.ShouldBindJSON(&staff); err != nil {
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+
+			c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "empty request")))
+			return
+		}
+
+		log.Error("failed to decode request body", sl.Error(err))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "failed to decode request")))
+		return
+	}
+
+	if errs := staff.Validate(); errs != nil {
+		log.Warn("failed to validate staff member", sl.Error(errs))
+
+		c.JSON(http.StatusBadRequest, response.ValidationError(i18nMiddleware.T(c, "validation failed"), errs.Localize(i18nMiddleware.Lang(c))))
+		return
+	}
+
+	if err := h.staffService.UpdateStaff(c.Request.Context(), staff, staffID); err != nil {
+		if errors.Is(err, staffService.ErrStaffNotFound) {
+			c.JSON(http.StatusNotFound, response.Error(i18nMiddleware.T(c, "staff member not found")))
+			return
+		}
+
+		if errors.Is(err, staffService.ErrGymNotFound) {
+			c.JSON(http.StatusNotFound, response.Error(i18nMiddleware.T(c, "gym not found")))
+			return
+		}
+
+		if errors.Is(err, staffService.ErrStaffExists) {
+			c.JSON(http.StatusConflict, response.Error(i18nMiddleware.T(c, "staff member with that user id already exists")))
+			return
+		}
+
+		log.Error("failed to update staff member", sl.Error(err))
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to update staff member")))
+		return
+	}
+
+	log.Info("Staff member updated", slog.Int64("staff_id", staffID))
+	c.JSON(http.StatusOK, response.OK("Staff member updated"))
+}
+
+// DeleteStaff godoc
+// @Summary      Удалить сотрудника
+// @Description  Удаляет сотрудника. Пользователь SSO теряет доступ к данным зала
+// @Security BearerAuth
+// @Tags         staff
+// @Accept       json
+// @Produce      json
+// @Param        id  path     int  true  "ID сотрудника"
+// @Success      200   {object}  response.Response "Сотрудник удален"
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      404   {object}  response.Response "Сотрудник не найден"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /staff/delete/{id} [delete]
+func (h *StaffHandler) DeleteStaff(c *gin.Context) {
+	const op = "handlers.staff.deleteStaff"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	staffID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		log.Error("failed to parse staff id", sl.Error(err))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "invalid staff id")))
+		return
+	}
+
+	if err := h.staffService.DeleteStaff(c.Request.Context(), staffID); err != nil {
+		if errors.Is(err, staffService.ErrStaffNotFound) {
+			c.JSON(http.StatusNotFound, response.Error(i18nMiddleware.T(c, "staff member not found")))
+			return
+		}
+
+		log.Error("failed to delete staff member", sl.Error(err))
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to delete staff member")))
+		return
+	}
+
+	log.Info("Staff member deleted", slog.Int64("staff_id", staffID))
+	c.JSON(http.StatusOK, response.OK("Staff member deleted"))
+}
+
+// FindAllStaff godoc
+// @Summary      Получить сотрудников
+// @Description  Возвращает сотрудников всех залов или одного зала
+// @Security BearerAuth
+// @Tags         staff
+// @Accept       json
+// @Produce      json
+// @Param        gym_id  query     int  false  "ID зала"
+// @Success      200   {array}   models.Staff
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /staff [get]
+func (h *StaffHandler) FindAllStaff(c *gin.Context) {
+	const op = "handlers.staff.findAllStaff"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	var gymID int64
+	if gymIDStr := c.Query("gym_id"); gymIDStr != "" {
+		var err error
+		gymID, err = strconv.ParseInt(gymIDStr, 10, 64)
+		if err != nil {
+			log.Error("failed to parse gym id", sl.Error(err))
+			c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "invalid gym id")))
+			return
+		}
+	}
+
+	staff, err := h.staffService.FindAllStaff(c.Request.Context(), gymID)
+	if err != nil {
+		log.Error("failed to get staff", sl.Error(err))
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to get staff")))
+		return
+	}
+
+	c.JSON(http.StatusOK, staff)
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"errors"
 	"github.com/gin-gonic/gin"
+	i18nMiddleware "gym_app/internal/http/middleware/i18n"
+	tenantMiddleware "gym_app/internal/http/middleware/tenant"
 	"gym_app/internal/lib/api/response"
 	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/requestctx"
 	"gym_app/internal/models"
-	personSubService "gym_app/internal/services/person_sub"
+	subscriptionService "gym_app/internal/services/subscription"
 	"io"
 	"log/slog"
 	"net/http"
@@ -15,25 +18,22 @@ import (
 )
 
 type SubscriptionService interface {
-	AddSubscription(ctx context.Context, subscription models.Subscription) (int, error)
-	FindAllSubscriptions(ctx context.Context) ([]models.Subscription, error)
-	UpdateSubscription(ctx context.Context, subscription models.Subscription, subID int) (int, error)
-	DeleteSubscription(ctx context.Context, subID int) error
+	AddSubscription(ctx context.Context, gymID int64, subscription models.Subscription) (int, error)
+	FindAllSubscriptions(ctx context.Context, gymID int64) ([]models.Subscription, error)
+	UpdateSubscription(ctx context.Context, gymID int64, subscription models.Subscription, subID int) (int, error)
+	DeleteSubscription(ctx context.Context, gymID int64, subID int, allGyms bool) error
 }
 
 type SubscriptionHandler struct {
-	ctx                 context.Context
 	log                 *slog.Logger
 	subscriptionService SubscriptionService
 }
 
 func New(
-	ctx context.Context,
 	log *slog.Logger,
 	subscriptionService SubscriptionService,
 ) *SubscriptionHandler {
 	return &SubscriptionHandler{
-		ctx:                 ctx,
 		log:                 log,
 		subscriptionService: subscriptionService,
 	}
@@ -53,10 +53,31 @@ func New(
 // @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
 // @Router       /subscription/add [post]
 func (h *SubscriptionHandler) AddSubscription(c *gin.Context) {
+	h.addSubscription(c, false)
+}
+
+// AddAllGymsSubscription godoc
+// @Summary      Добавить абонемент всех филиалов
+// @Description  Добавляет тариф, действующий во всех филиалах. Требует права gyms.manage
+// @Security BearerAuth
+// @Tags         subscription
+// @Accept       json
+// @Produce      json
+// @Param        subscription  body     models.Subscription  true  "Абонемент"
+// @Success      200   {object}  response.Response "Абонемент добавлен"
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /subscription/all_gyms/add [post]
+func (h *SubscriptionHandler) AddAllGymsSubscription(c *gin.Context) {
+	h.addSubscription(c, true)
+}
+
+func (h *SubscriptionHandler) addSubscription(c *gin.Context, allGyms bool) {
 	const op = "handlers.subscription.addSubscription"
 
-	log := h.log.With(
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
 		slog.String("op", op),
+		slog.Bool("all_gyms", allGyms),
 	)
 
 	var subscription models.Subscription
@@ -65,21 +86,32 @@ func (h *SubscriptionHandler) AddSubscription(c *gin.Context) {
 		if errors.Is(err, io.EOF) {
 			log.Error("request body is empty")
 
-			c.JSON(http.StatusBadRequest, response.Error("empty request"))
+			c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "empty request")))
 			return
 		}
 
 		log.Error("failed to decode request body", sl.Error(err))
 
-		c.JSON(http.StatusBadRequest, response.Error("failed to decode request"))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "failed to decode request")))
 		return
 	}
 
-	subId, err := h.subscriptionService.AddSubscription(h.ctx, subscription)
+	if !h.checkScope(c, &subscription, allGyms) {
+		return
+	}
+
+	if errs := subscription.Validate(); errs != nil {
+		log.Warn("failed to validate subscription", sl.Error(errs))
+
+		c.JSON(http.StatusBadRequest, response.ValidationError(i18nMiddleware.T(c, "validation failed"), errs.Localize(i18nMiddleware.Lang(c))))
+		return
+	}
+
+	subId, err := h.subscriptionService.AddSubscription(c.Request.Context(), tenantMiddleware.GymID(c), subscription)
 	if err != nil {
 		log.Error("failed to add subscription", sl.Error(err))
 
-		c.JSON(http.StatusInternalServerError, response.Error("failed to add subscription"))
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to add subscription")))
 		return
 	}
 
@@ -102,22 +134,45 @@ func (h *SubscriptionHandler) AddSubscription(c *gin.Context) {
 // @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
 // @Router       /subscription/update/{id} [put]
 func (h *SubscriptionHandler) UpdateSubscription(c *gin.Context) {
+	h.updateSubscription(c, false)
+}
+
+// UpdateAllGymsSubscription godoc
+// @Summary      Обновить абонемент всех филиалов
+// @Description  Обновляет тариф, действующий во всех филиалах. Требует права gyms.manage
+// @Security BearerAuth
+// @Tags         subscription
+// @Accept       json
+// @Produce      json
+// @Param        id           path     int                  true  "ID абонемента"
+// @Param        subscription body     models.Subscription  true  "Абонемент"
+// @Success      200   {object}  response.Response "Абонемент обновлен"
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      404   {object}  response.Response "Не найдено"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /subscription/all_gyms/update/{id} [put]
+func (h *SubscriptionHandler) UpdateAllGymsSubscription(c *gin.Context) {
+	h.updateSubscription(c, true)
+}
+
+func (h *SubscriptionHandler) updateSubscription(c *gin.Context, allGyms bool) {
 	const op = "handlers.subscription.updateSubscription"
 
-	log := h.log.With(
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
 		slog.String("op", op),
+		slog.Bool("all_gyms", allGyms),
 	)
 
 	subscriptionIdStr := c.Param("id")
 	if subscriptionIdStr == "" {
 		log.Error("subscription ID is empty")
-		c.JSON(http.StatusBadRequest, response.Error("subscription ID is required"))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "subscription ID is required")))
 		return
 	}
 	subscriptionID, err := strconv.Atoi(subscriptionIdStr)
 	if err != nil {
 		log.Error("failed to parse subscription ID", sl.Error(err))
-		c.JSON(http.StatusBadRequest, response.Error("invalid subscription ID"))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "invalid subscription ID")))
 		return
 	}
 
@@ -127,21 +182,37 @@ func (h *SubscriptionHandler) UpdateSubscription(c *gin.Context) {
 		if errors.Is(err, io.EOF) {
 			log.Error("request body is empty")
 
-			c.JSON(http.StatusBadRequest, response.Error("empty request"))
+			c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "empty request")))
 			return
 		}
 
 		log.Error("failed to decode request body", sl.Error(err))
 
-		c.JSON(http.StatusBadRequest, response.Error("failed to decode request"))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "failed to decode request")))
+		return
+	}
+
+	if !h.checkScope(c, &subscription, allGyms) {
 		return
 	}
 
-	subId, err := h.subscriptionService.UpdateSubscription(h.ctx, subscription, subscriptionID)
+	if errs := subscription.Validate(); errs != nil {
+		log.Warn("failed to validate subscription", sl.Error(errs))
+
+		c.JSON(http.StatusBadRequest, response.ValidationError(i18nMiddleware.T(c, "validation failed"), errs.Localize(i18nMiddleware.Lang(c))))
+		return
+	}
+
+	subId, err := h.subscriptionService.UpdateSubscription(c.Request.Context(), tenantMiddleware.GymID(c), subscription, subscriptionID)
 	if err != nil {
+		if errors.Is(err, subscriptionService.ErrSubNotFound) {
+			c.JSON(http.StatusNotFound, response.Error(i18nMiddleware.T(c, "subscription not found")))
+			return
+		}
+
 		log.Error("failed to update subscription", sl.Error(err))
 
-		c.JSON(http.StatusInternalServerError, response.Error("failed to update subscription"))
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to update subscription")))
 		return
 	}
 
@@ -163,36 +234,58 @@ func (h *SubscriptionHandler) UpdateSubscription(c *gin.Context) {
 // @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
 // @Router       /subscription/delete/{id} [delete]
 func (h *SubscriptionHandler) DeleteSubscription(c *gin.Context) {
+	h.deleteSubscription(c, false)
+}
+
+// DeleteAllGymsSubscription godoc
+// @Summary      Удалить абонемент всех филиалов
+// @Description  Удаляет тариф, действующий во всех филиалах. Требует права gyms.manage
+// @Security BearerAuth
+// @Tags         subscription
+// @Accept       json
+// @Produce      json
+// @Param        id  path     int  true  "ID абонемента"
+// @Success      200   {object}  response.Response "Абонемент удален"
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      404   {object}  response.Response "Абонемент не найден"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /subscription/all_gyms/delete/{id} [delete]
+func (h *SubscriptionHandler) DeleteAllGymsSubscription(c *gin.Context) {
+	h.deleteSubscription(c, true)
+}
+
+func (h *SubscriptionHandler) deleteSubscription(c *gin.Context, allGyms bool) {
 	const op = "handlers.subscription.deleteSubscription"
 
-	log := h.log.With(
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
 		slog.String("op", op),
+		slog.Bool("all_gyms", allGyms),
 	)
 
 	subscriptionIdStr := c.Param("id")
 	if subscriptionIdStr == "" {
 		log.Error("subscription ID is empty")
-		c.JSON(http.StatusBadRequest, response.Error("subscription ID is required"))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "subscription ID is required")))
 		return
 	}
 	subscriptionID, err := strconv.Atoi(subscriptionIdStr)
 	if err != nil {
 		log.Error("failed to parse subscription ID", sl.Error(err))
-		c.JSON(http.StatusBadRequest, response.Error("invalid subscription ID"))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "invalid subscription ID")))
 		return
 	}
 
-	err = h.subscriptionService.DeleteSubscription(h.ctx, subscriptionID)
+	err = h.subscriptionService.DeleteSubscription(c.Request.Context(), tenantMiddleware.GymID(c), subscriptionID, allGyms)
 	if err != nil {
 
-		if errors.Is(err, personSubService.ErrSubNotFound) {
-			c.JSON(http.StatusNotFound, response.Error("subscription not found"))
+		if errors.Is(err, subscriptionService.ErrSubNotFound) {
+			c.JSON(http.StatusNotFound, response.Error(i18nMiddleware.T(c, "subscription not found")))
 			return
 		}
 
 		log.Error("failed to delete subscription", sl.Error(err))
 
-		c.JSON(http.StatusInternalServerError, response.Error("failed to delete subscription"))
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to delete subscription")))
 		return
 	}
 
@@ -213,15 +306,15 @@ func (h *SubscriptionHandler) DeleteSubscription(c *gin.Context) {
 func (h *SubscriptionHandler) FindAllSubscriptions(c *gin.Context) {
 	const op = "handlers.subscription.findAllSubscriptions"
 
-	log := h.log.With(
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
 		slog.String("op", op),
 	)
 
-	subscriptions, err := h.subscriptionService.FindAllSubscriptions(h.ctx)
+	subscriptions, err := h.subscriptionService.FindAllSubscriptions(c.Request.Context(), tenantMiddleware.GymID(c))
 	if err != nil {
 		log.Error("failed to get Subscriptions", sl.Error(err))
 
-		c.JSON(http.StatusInternalServerError, response.Error("failed to get Subscriptions"))
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to get Subscriptions")))
 		return
 	}
 
@@ -229,3 +322,17 @@ func (h *SubscriptionHandler) FindAllSubscriptions(c *gin.Context) {
 
 	c.JSON(http.StatusOK, subscriptions)
 }
+
+// checkScope makes the tariff belong to the scope of the route. Tariffs of
+// all gyms are only changed through /subscription/all_gyms, which needs
+// gyms.manage, so a branch can't touch the tariffs of the other branches.
+func (h *SubscriptionHandler) checkScope(c *gin.Context, subscription *models.Subscription, allGyms bool) bool {
+	if subscription.AllGyms && !allGyms {
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "tariffs of all gyms are managed via /subscription/all_gyms")))
+		return false
+	}
+
+	subscription.AllGyms = allGyms
+
+	return true
+}
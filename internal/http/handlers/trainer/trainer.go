@@ -0,0 +1,252 @@
+package trainerHandler
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	i18nMiddleware "gym_app/internal/http/middleware/i18n"
+	tenantMiddleware "gym_app/internal/http/middleware/tenant"
+	"gym_app/internal/lib/api/response"
+	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/requestctx"
+	"gym_app/internal/models"
+	trainerService "gym_app/internal/services/trainer"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type TrainerService interface {
+	AddTrainer(ctx context.Context, gymID int64, trainer models.Trainer) (int64, error)
+	UpdateTrainer(ctx context.Context, gymID int64, trainer models.Trainer, trainerID int64) error
+	DeleteTrainer(ctx context.Context, gymID int64, trainerID int64) error
+	FindAllTrainers(ctx context.Context, gymID int64) ([]models.Trainer, error)
+}
+
+type TrainerHandler struct {
+	log            *slog.Logger
+	trainerService TrainerService
+}
+
+func New(log *slog.Logger, trainerService TrainerService) *TrainerHandler {
+	return &TrainerHandler{
+		log:            log,
+		trainerService: trainerService,
+	}
+}
+
+// AddTrainer godoc
+// @Summary      Добавить тренера
+// @Description  Добавляет тренера в зал
+// @Security BearerAuth
+// @Tags         trainer
+// @Accept       json
+// @Produce      json
+// @Param        trainer  body     models.Trainer  true  "Тренер"
+// @Success      200   {object}  response.Response "Тренер добавлен"
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      404   {object}  response.Response "Сотрудник не найден"
+// @Failure      409   {object}  response.Response "Конфликт"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /trainers/add [post]
+func (h *TrainerHandler) AddTrainer(c *gin.Context) {
+	const op = "handlers.trainer.addTrainer"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	var trainer models.Trainer
+
+	if err := c.ShouldBindJSON(&trainer); err != nil {
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+
+			c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "empty request")))
+			return
+		}
+
+		log.Error("failed to decode request body", sl.Error(err))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "failed to decode request")))
+		return
+	}
+
+	if errs := trainer.Validate(); errs != nil {
+		log.Warn("failed to validate trainer", sl.Error(errs))
+
+		c.JSON(http.StatusBadRequest, response.ValidationError(i18nMiddleware.T(c, "validation failed"), errs.Localize(i18nMiddleware.Lang(c))))
+		return
+	}
+
+	trainerID, err := h.trainerService.AddTrainer(c.Request.Context(), tenantMiddleware.GymID(c), trainer)
+	if err != nil {
+		if errors.Is(err, trainerService.ErrStaffNotFound) {
+			c.JSON(http.StatusNotFound, response.Error(i18nMiddleware.T(c, "staff member not found")))
+			return
+		}
+
+		if errors.Is(err, trainerService.ErrStaffTaken) {
+			c.JSON(http.StatusConflict, response.Error(i18nMiddleware.T(c, "staff member is already linked to another trainer")))
+			return
+		}
+
+		log.Error("failed to add trainer", sl.Error(err))
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to add trainer")))
+		return
+	}
+
+	log.Info("Trainer added", slog.Int64("trainer_id", trainerID))
+	c.JSON(http.StatusOK, response.OK("Trainer added, trainerId: "+strconv.FormatInt(trainerID, 10)))
+}
+
+// UpdateTrainer godoc
+// @Summary      Обновить тренера
+// @Description  Обновляет данные тренера
+// @Security BearerAuth
+// @Tags         trainer
+// @Accept       json
+// @Produce      json
+// @Param        id       path     int             true  "ID тренера"
+// @Param        trainer  body     models.Trainer  true  "Тренер"
+// @Success      200   {object}  response.Response "Тренер обновлен"
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      404   {object}  response.Response "Тренер или сотрудник не найден"
+// @Failure      409   {object}  response.Response "Конфликт"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /trainers/update/{id} [put]
+func (h *TrainerHandler) UpdateTrainer(c *gin.Context) {
+	const op = "handlers.trainer.updateTrainer"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	trainerID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		log.Error("failed to parse trainer id", sl.Error(err))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "invalid trainer id")))
+		return
+	}
+
+	var trainer models.Trainer
+
+	if err := c.ShouldBindJSON(&trainer); err != nil {
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+
+			c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "empty request")))
+			return
+		}
+
+		log.Error("failed to decode request body", sl.Error(err))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "failed to decode request")))
+		return
+	}
+
+	if errs := trainer.Validate(); errs != nil {
+		log.Warn("failed to validate trainer", sl.Error(errs))
+
+		c.JSON(http.StatusBadRequest, response.ValidationError(i18nMiddleware.T(c, "validation failed"), errs.Localize(i18nMiddleware.Lang(c))))
+		return
+	}
+
+	if err := h.trainerService.UpdateTrainer(c.Request.Context(), tenantMiddleware.GymID(c), trainer, trainerID); err != nil {
+		if errors.Is(err, trainerService.ErrTrainerNotFound) {
+			c.JSON(http.StatusNotFound, response.Error(i18nMiddleware.T(c, "trainer not found")))
+			return
+		}
+
+		if errors.Is(err, trainerService.ErrStaffNotFound) {
+			c.JSON(http.StatusNotFound, response.Error(i18nMiddleware.T(c, "staff member not found")))
+			return
+		}
+
+		if errors.Is(err, trainerService.ErrStaffTaken) {
+			c.JSON(http.StatusConflict, response.Error(i18nMiddleware.T(c, "staff member is already linked to another trainer")))
+			return
+		}
+
+		log.Error("failed to update trainer", sl.Error(err))
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to update trainer")))
+		return
+	}
+
+	log.Info("Trainer updated", slog.Int64("trainer_id", trainerID))
+	c.JSON(http.StatusOK, response.OK("Trainer updated"))
+}
+
+// DeleteTrainer godoc
+// @Summary      Удалить тренера
+// @Description  Удаляет тренера, если у него нет занятий в расписании
+// @Security BearerAuth
+// @Tags         trainer
+// @Accept       json
+// @Produce      json
+// @Param        id  path     int  true  "ID тренера"
+// @Success      200   {object}  response.Response "Тренер удален"
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      404   {object}  response.Response "Тренер не найден"
+// @Failure      409   {object}  response.Response "У тренера есть занятия"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /trainers/delete/{id} [delete]
+func (h *TrainerHandler) DeleteTrainer(c *gin.Context) {
+	const op = "handlers.trainer.deleteTrainer"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	trainerID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		log.Error("failed to parse trainer id", sl.Error(err))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "invalid trainer id")))
+		return
+	}
+
+	if err := h.trainerService.DeleteTrainer(c.Request.Context(), tenantMiddleware.GymID(c), trainerID); err != nil {
+		if errors.Is(err, trainerService.ErrTrainerNotFound) {
+			c.JSON(http.StatusNotFound, response.Error(i18nMiddleware.T(c, "trainer not found")))
+			return
+		}
+
+		if errors.Is(err, trainerService.ErrTrainerInUse) {
+			c.JSON(http.StatusConflict, response.Error(i18nMiddleware.T(c, "trainer has scheduled classes")))
+			return
+		}
+
+		log.Error("failed to delete trainer", sl.Error(err))
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to delete trainer")))
+		return
+	}
+
+	log.Info("Trainer deleted", slog.Int64("trainer_id", trainerID))
+	c.JSON(http.StatusOK, response.OK("Trainer deleted"))
+}
+
+// FindAllTrainers godoc
+// @Summary      Получить тренеров
+// @Description  Возвращает тренеров зала
+// @Security BearerAuth
+// @Tags         trainer
+// @Accept       json
+// @Produce      json
+// @Success      200   {array}   models.Trainer
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /trainers [get]
+func (h *TrainerHandler) FindAllTrainers(c *gin.Context) {
+	const op = "handlers.trainer.findAllTrainers"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	trainers, err := h.trainerService.FindAllTrainers(c.Request.Context(), tenantMiddleware.GymID(c))
+	if err != nil {
+		log.Error("failed to get trainers", sl.Error(err))
+		c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to get trainers")))
+		return
+	}
+
+	c.JSON(http.StatusOK, trainers)
+}
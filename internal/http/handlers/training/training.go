@@ -0,0 +1,507 @@
+package trainingHandler
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	i18nMiddleware "gym_app/internal/http/middleware/i18n"
+	tenantMiddleware "gym_app/internal/http/middleware/tenant"
+	"gym_app/internal/lib/api/response"
+	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/requestctx"
+	"gym_app/internal/models"
+	trainingService "gym_app/internal/services/training"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type TrainingService interface {
+	AddPackage(ctx context.Context, gymID int64, pkg models.TrainingPackage) (int64, error)
+	UpdatePackage(ctx context.Context, gymID int64, pkg models.TrainingPackage, pkgID int64) error
+	DeletePackage(ctx context.Context, gymID int64, pkgID int64) error
+	FindAllPackages(ctx context.Context, gymID int64) ([]models.TrainingPackage, error)
+
+	SellPackage(ctx context.Context, gymID int64, sale models.PersonTrainingPackage) (int64, error)
+	FindPersonPackages(ctx context.Context, gymID, personID int64) ([]models.PersonTrainingPackage, error)
+
+	BookSession(ctx context.Context, gymID int64, session models.TrainingSession) (int64, error)
+	CancelSession(ctx context.Context, gymID, sessionID int64) error
+	CompleteSession(ctx context.Context, gymID, sessionID int64) error
+	FindUpcomingSessions(ctx context.Context, gymID, trainerID int64) ([]models.TrainingSession, error)
+	FindStaffSessions(ctx context.Context, gymID, staffID int64) ([]models.TrainingSession, error)
+}
+
+type TrainingHandler struct {
+	log             *slog.Logger
+	trainingService TrainingService
+}
+
+func New(log *slog.Logger, trainingService TrainingService) *TrainingHandler {
+	return &TrainingHandler{
+		log:             log,
+		trainingService: trainingService,
+	}
+}
+
+// AddPackage godoc
+// @Summary      Добавить пакет тренировок
+// @Description  Добавляет пакет персональных тренировок
+// @Security BearerAuth
+// @Tags         training
+// @Accept       json
+// @Produce      json
+// @Param        package  body     models.TrainingPackage  true  "Пакет тренировок"
+// @Success      200   {object}  response.Response "Пакет добавлен"
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /training/packages/add [post]
+func (h *TrainingHandler) AddPackage(c *gin.Context) {
+	const op = "handlers.training.addPackage"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	var pkg models.TrainingPackage
+	if !bindJSON(c, log, &pkg) {
+		return
+	}
+
+	if errs := pkg.Validate(); errs != nil {
+		log.Warn("failed to validate training package", sl.Error(errs))
+		c.JSON(http.StatusBadRequest, response.ValidationError(i18nMiddleware.T(c, "validation failed"), errs.Localize(i18nMiddleware.Lang(c))))
+		return
+	}
+
+	pkgID, err := h.trainingService.AddPackage(c.Request.Context(), tenantMiddleware.GymID(c), pkg)
+	if err != nil {
+		respondError(c, log, err, "failed to add training package")
+		return
+	}
+
+	log.Info("Training package added", slog.Int64("package_id", pkgID))
+	c.JSON(http.StatusOK, response.OK("Training package added, packageId: "+strconv.FormatInt(pkgID, 10)))
+}
+
+// UpdatePackage godoc
+// @Summary      Обновить пакет тренировок
+// @Description  Обновляет пакет персональных тренировок. Уже проданные пакеты не меняются
+// @Security BearerAuth
+// @Tags         training
+// @Accept       json
+// @Produce      json
+// @Param        id       path     int                     true  "ID пакета"
+// @Param        package  body     models.TrainingPackage  true  "Пакет тренировок"
+// @Success      200   {object}  response.Response "Пакет обновлен"
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      404   {object}  response.Response "Пакет не найден"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /training/packages/update/{id} [put]
+func (h *TrainingHandler) UpdatePackage(c *gin.Context) {
+	const op = "handlers.training.updatePackage"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	pkgID, ok := parseID(c, log, "package")
+	if !ok {
+		return
+	}
+
+	var pkg models.TrainingPackage
+	if !bindJSON(c, log, &pkg) {
+		return
+	}
+
+	if errs := pkg.Validate(); errs != nil {
+		log.Warn("failed to validate training package", sl.Error(errs))
+		c.JSON(http.StatusBadRequest, response.ValidationError(i18nMiddleware.T(c, "validation failed"), errs.Localize(i18nMiddleware.Lang(c))))
+		return
+	}
+
+	if err := h.trainingService.UpdatePackage(c.Request.Context(), tenantMiddleware.GymID(c), pkg, pkgID); err != nil {
+		respondError(c, log, err, "failed to update training package")
+		return
+	}
+
+	log.Info("Training package updated", slog.Int64("package_id", pkgID))
+	c.JSON(http.StatusOK, response.OK("Training package updated"))
+}
+
+// DeletePackage godoc
+// @Summary      Удалить пакет тренировок
+// @Description  Удаляет пакет персональных тренировок, если он ни разу не продавался
+// @Security BearerAuth
+// @Tags         training
+// @Accept       json
+// @Produce      json
+// @Param        id  path     int  true  "ID пакета"
+// @Success      200   {object}  response.Response "Пакет удален"
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      404   {object}  response.Response "Пакет не найден"
+// @Failure      409   {object}  response.Response "Пакет уже продавался"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /training/packages/delete/{id} [delete]
+func (h *TrainingHandler) DeletePackage(c *gin.Context) {
+	const op = "handlers.training.deletePackage"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	pkgID, ok := parseID(c, log, "package")
+	if !ok {
+		return
+	}
+
+	if err := h.trainingService.DeletePackage(c.Request.Context(), tenantMiddleware.GymID(c), pkgID); err != nil {
+		respondError(c, log, err, "failed to delete training package")
+		return
+	}
+
+	log.Info("Training package deleted", slog.Int64("package_id", pkgID))
+	c.JSON(http.StatusOK, response.OK("Training package deleted"))
+}
+
+// FindAllPackages godoc
+// @Summary      Получить пакеты тренировок
+// @Description  Возвращает пакеты персональных тренировок зала
+// @Security BearerAuth
+// @Tags         training
+// @Accept       json
+// @Produce      json
+// @Success      200   {array}   models.TrainingPackage
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /training/packages [get]
+func (h *TrainingHandler) FindAllPackages(c *gin.Context) {
+	const op = "handlers.training.findAllPackages"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	packages, err := h.trainingService.FindAllPackages(c.Request.Context(), tenantMiddleware.GymID(c))
+	if err != nil {
+		respondError(c, log, err, "failed to get training packages")
+		return
+	}
+
+	c.JSON(http.StatusOK, packages)
+}
+
+// SellPackage godoc
+// @Summary      Продать пакет тренировок
+// @Description  Продает клиенту пакет персональных тренировок с закрепленным тренером
+// @Security BearerAuth
+// @Tags         training
+// @Accept       json
+// @Produce      json
+// @Param        sale  body     models.PersonTrainingPackage  true  "Продажа пакета"
+// @Success      200   {object}  response.Response "Пакет продан"
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      404   {object}  response.Response "Клиент, пакет или тренер не найден"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /training/person_packages/sell [post]
+func (h *TrainingHandler) SellPackage(c *gin.Context) {
+	const op = "handlers.training.sellPackage"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	var sale models.PersonTrainingPackage
+	if !bindJSON(c, log, &sale) {
+		return
+	}
+
+	if errs := sale.Validate(); errs != nil {
+		log.Warn("failed to validate training package sale", sl.Error(errs))
+		c.JSON(http.StatusBadRequest, response.ValidationError(i18nMiddleware.T(c, "validation failed"), errs.Localize(i18nMiddleware.Lang(c))))
+		return
+	}
+
+	personPkgID, err := h.trainingService.SellPackage(c.Request.Context(), tenantMiddleware.GymID(c), sale)
+	if err != nil {
+		respondError(c, log, err, "failed to sell training package")
+		return
+	}
+
+	log.Info("Training package sold", slog.Int64("person_package_id", personPkgID))
+	c.JSON(http.StatusOK, response.OK("Training package sold, personPackageId: "+strconv.FormatInt(personPkgID, 10)))
+}
+
+// FindPersonPackages godoc
+// @Summary      Получить пакеты тренировок клиента
+// @Description  Возвращает пакеты персональных тренировок клиента с остатком тренировок
+// @Security BearerAuth
+// @Tags         training
+// @Accept       json
+// @Produce      json
+// @Param        person_id  query     int  true  "ID клиента"
+// @Success      200   {array}   models.PersonTrainingPackage
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /training/person_packages [get]
+func (h *TrainingHandler) FindPersonPackages(c *gin.Context) {
+	const op = "handlers.training.findPersonPackages"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	personID, err := strconv.ParseInt(c.Query("person_id"), 10, 64)
+	if err != nil {
+		log.Error("failed to parse person id", sl.Error(err))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "invalid person id")))
+		return
+	}
+
+	packages, err := h.trainingService.FindPersonPackages(c.Request.Context(), tenantMiddleware.GymID(c), personID)
+	if err != nil {
+		respondError(c, log, err, "failed to get person training packages")
+		return
+	}
+
+	c.JSON(http.StatusOK, packages)
+}
+
+// BookSession godoc
+// @Summary      Записать на персональную тренировку
+// @Description  Записывает клиента на тренировку по его пакету, если в пакете остались тренировки и тренер свободен
+// @Security BearerAuth
+// @Tags         training
+// @Accept       json
+// @Produce      json
+// @Param        session  body     models.TrainingSession  true  "Тренировка"
+// @Success      200   {object}  response.Response "Клиент записан"
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      404   {object}  response.Response "Пакет клиента не найден"
+// @Failure      409   {object}  response.Response "Пакет исчерпан, истек или еще не начался, тренер занят"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /training/sessions/book [post]
+func (h *TrainingHandler) BookSession(c *gin.Context) {
+	const op = "handlers.training.bookSession"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	var session models.TrainingSession
+	if !bindJSON(c, log, &session) {
+		return
+	}
+
+	if errs := session.Validate(); errs != nil {
+		log.Warn("failed to validate training session", sl.Error(errs))
+		c.JSON(http.StatusBadRequest, response.ValidationError(i18nMiddleware.T(c, "validation failed"), errs.Localize(i18nMiddleware.Lang(c))))
+		return
+	}
+
+	sessionID, err := h.trainingService.BookSession(c.Request.Context(), tenantMiddleware.GymID(c), session)
+	if err != nil {
+		respondError(c, log, err, "failed to book training session")
+		return
+	}
+
+	log.Info("Training session booked", slog.Int64("session_id", sessionID))
+	c.JSON(http.StatusOK, response.OK("Training session booked, sessionId: "+strconv.FormatInt(sessionID, 10)))
+}
+
+// CancelSession godoc
+// @Summary      Отменить персональную тренировку
+// @Description  Отменяет запись на тренировку, тренировка возвращается в пакет
+// @Security BearerAuth
+// @Tags         training
+// @Accept       json
+// @Produce      json
+// @Param        id  path     int  true  "ID тренировки"
+// @Success      200   {object}  response.Response "Тренировка отменена"
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      404   {object}  response.Response "Тренировка не найдена"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /training/sessions/cancel/{id} [post]
+func (h *TrainingHandler) CancelSession(c *gin.Context) {
+	const op = "handlers.training.cancelSession"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	sessionID, ok := parseID(c, log, "session")
+	if !ok {
+		return
+	}
+
+	if err := h.trainingService.CancelSession(c.Request.Context(), tenantMiddleware.GymID(c), sessionID); err != nil {
+		respondError(c, log, err, "failed to cancel training session")
+		return
+	}
+
+	log.Info("Training session cancelled", slog.Int64("session_id", sessionID))
+	c.JSON(http.StatusOK, response.OK("Training session cancelled"))
+}
+
+// CompleteSession godoc
+// @Summary      Отметить проведенную тренировку
+// @Description  Отмечает тренировку проведенной и списывает ее из пакета клиента
+// @Security BearerAuth
+// @Tags         training
+// @Accept       json
+// @Produce      json
+// @Param        id  path     int  true  "ID тренировки"
+// @Success      200   {object}  response.Response "Тренировка проведена"
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      404   {object}  response.Response "Тренировка не найдена"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /training/sessions/complete/{id} [post]
+func (h *TrainingHandler) CompleteSession(c *gin.Context) {
+	const op = "handlers.training.completeSession"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	sessionID, ok := parseID(c, log, "session")
+	if !ok {
+		return
+	}
+
+	if err := h.trainingService.CompleteSession(c.Request.Context(), tenantMiddleware.GymID(c), sessionID); err != nil {
+		respondError(c, log, err, "failed to complete training session")
+		return
+	}
+
+	log.Info("Training session completed", slog.Int64("session_id", sessionID))
+	c.JSON(http.StatusOK, response.OK("Training session completed"))
+}
+
+// FindUpcomingSessions godoc
+// @Summary      Получить предстоящие тренировки
+// @Description  Возвращает предстоящие персональные тренировки тренера или всех тренеров зала
+// @Security BearerAuth
+// @Tags         training
+// @Accept       json
+// @Produce      json
+// @Param        trainer_id  query     int  false  "ID тренера"
+// @Success      200   {array}   models.TrainingSession
+// @Failure      400   {object}  response.Response "Ошибка валидации"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /training/sessions/upcoming [get]
+func (h *TrainingHandler) FindUpcomingSessions(c *gin.Context) {
+	const op = "handlers.training.findUpcomingSessions"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	var trainerID int64
+	if s := c.Query("trainer_id"); s != "" {
+		var err error
+		trainerID, err = strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			log.Error("failed to parse trainer id", sl.Error(err))
+			c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "invalid trainer id")))
+			return
+		}
+	}
+
+	sessions, err := h.trainingService.FindUpcomingSessions(c.Request.Context(), tenantMiddleware.GymID(c), trainerID)
+	if err != nil {
+		respondError(c, log, err, "failed to get upcoming training sessions")
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// FindMySessions godoc
+// @Summary      Получить свои тренировки
+// @Description  Возвращает предстоящие персональные тренировки текущего тренера
+// @Security BearerAuth
+// @Tags         training
+// @Accept       json
+// @Produce      json
+// @Success      200   {array}   models.TrainingSession
+// @Failure      403   {object}  response.Response "Пользователь не является тренером"
+// @Failure      500   {object}  response.Response "Внутренняя ошибка сервера"
+// @Router       /training/sessions/my [get]
+func (h *TrainingHandler) FindMySessions(c *gin.Context) {
+	const op = "handlers.training.findMySessions"
+
+	log := requestctx.Logger(c.Request.Context(), h.log).With(
+		slog.String("op", op),
+	)
+
+	staff, _ := tenantMiddleware.Staff(c)
+
+	sessions, err := h.trainingService.FindStaffSessions(c.Request.Context(), tenantMiddleware.GymID(c), staff.ID)
+	if err != nil {
+		respondError(c, log, err, "failed to get training sessions")
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+func bindJSON(c *gin.Context, log *slog.Logger, v any) bool {
+	if err := c.ShouldBindJSON(v); err != nil {
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+
+			c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "empty request")))
+			return false
+		}
+
+		log.Error("failed to decode request body", sl.Error(err))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "failed to decode request")))
+		return false
+	}
+
+	return true
+}
+
+func parseID(c *gin.Context, log *slog.Logger, name string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		log.Error("failed to parse "+name+" id", sl.Error(err))
+		c.JSON(http.StatusBadRequest, response.Error(i18nMiddleware.T(c, "invalid "+name+" id")))
+		return 0, false
+	}
+
+	return id, true
+}
+
+var errorStatuses = []struct {
+	err    error
+	status int
+}{
+	{trainingService.ErrInvalidDate, http.StatusBadRequest},
+	{trainingService.ErrSessionInPast, http.StatusBadRequest},
+	{trainingService.ErrNotATrainer, http.StatusForbidden},
+	{trainingService.ErrPackageNotFound, http.StatusNotFound},
+	{trainingService.ErrSaleRefsNotFound, http.StatusNotFound},
+	{trainingService.ErrPersonPackageNotFound, http.StatusNotFound},
+	{trainingService.ErrSessionNotFound, http.StatusNotFound},
+	{trainingService.ErrPackageInUse, http.StatusConflict},
+	{trainingService.ErrPackageExpired, http.StatusConflict},
+	{trainingService.ErrPackageNotStarted, http.StatusConflict},
+	{trainingService.ErrNoSessionsLeft, http.StatusConflict},
+	{trainingService.ErrTrainerBusy, http.StatusConflict},
+}
+
+// respondError maps training service errors to HTTP statuses.
+func respondError(c *gin.Context, log *slog.Logger, err error, msg string) {
+	for _, e := range errorStatuses {
+		if errors.Is(err, e.err) {
+			log.Warn(msg, sl.Error(err))
+			c.JSON(e.status, response.Error(i18nMiddleware.T(c, e.err.Error())))
+			return
+		}
+	}
+
+	log.Error(msg, sl.Error(err))
+	c.JSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, msg)))
+}
@@ -1,48 +1,58 @@
 package authMiddleware
 
 import (
-	"fmt"
+	"errors"
+	ssov1 "github.com/Muaz717/protos_sso/gen/go/sso"
 	"github.com/gin-gonic/gin"
 	"gym_app/internal/clients/sso/grpc"
+	i18nMiddleware "gym_app/internal/http/middleware/i18n"
+	"gym_app/internal/lib/api/response"
 	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/requestctx"
 	"log/slog"
 	"net/http"
 )
 
 const userContextKey = "user"
 
-func AuthMiddleware(log *slog.Logger, ssoClient *grpc.SSOClient, appId int32, requiredRole string) gin.HandlerFunc {
+func AuthMiddleware(log *slog.Logger, ssoClient *grpc.SSOClient, appId int32) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		const op = "middleware.AuthMiddleware"
 
-		log = log.With(
+		log := requestctx.Logger(c.Request.Context(), log).With(
 			slog.String("op", op),
 		)
 
 		//authHeader := c.GetHeader("Authorization")
 		//if authHeader == "" {
 		//	log.Error("authorization header missing", slog.String("op", op))
-		//	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authorization header missing"})
+		//	c.AbortWithStatusJSON(http.StatusUnauthorized, response.Error(i18nMiddleware.T(c, "authorization header missing")))
 		//	return
 		//}
 		//
 		//token := strings.TrimPrefix(authHeader, "Bearer ")
 		//if token == authHeader {
 		//	log.Error("invalid authorization format", slog.String("op", op))
-		//	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization format"})
+		//	c.AbortWithStatusJSON(http.StatusUnauthorized, response.Error(i18nMiddleware.T(c, "invalid authorization format")))
 		//	return
 		//}
 
 		token, err := c.Cookie("token")
 		if err != nil || token == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Error(i18nMiddleware.T(c, "unauthorized")))
 			return
 		}
 
 		resp, err := ssoClient.CheckToken(c.Request.Context(), appId, token)
 		if err != nil {
 			log.Error("failed to check token", slog.String("op", op), sl.Error(err))
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token validation failed"})
+
+			if errors.Is(err, grpc.ErrUnavailable) {
+				c.AbortWithStatusJSON(http.StatusServiceUnavailable, response.Error(i18nMiddleware.T(c, "authentication service is unavailable")))
+				return
+			}
+
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Error(i18nMiddleware.T(c, "token validation failed")))
 			return
 		}
 
@@ -56,27 +66,22 @@ func AuthMiddleware(log *slog.Logger, ssoClient *grpc.SSOClient, appId int32, re
 
 		if !resp.IsValid {
 			log.Warn("invalid token", slog.String("op", op), slog.String("token", token))
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
-			return
-		}
-
-		hasRequiredRole := false
-		for _, role := range resp.Roles {
-			if role == requiredRole {
-				hasRequiredRole = true
-				break
-			}
-		}
-
-		if !hasRequiredRole {
-			log.Warn(fmt.Sprintf("%s role required", requiredRole), slog.String("op", op), slog.Int64("user_id", resp.GetUserId()))
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("%s role required", requiredRole)})
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Error(i18nMiddleware.T(c, "invalid token")))
 			return
 		}
 
 		c.Set(userContextKey, resp)
+		c.Request = c.Request.WithContext(requestctx.WithUserID(c.Request.Context(), resp.GetUserId()))
 		c.Next()
 	}
 }
 
-//func GetUserFromContext(c *gin.Context)
+func GetUserFromContext(c *gin.Context) (*ssov1.CheckTokenResponse, bool) {
+	v, ok := c.Get(userContextKey)
+	if !ok {
+		return nil, false
+	}
+
+	user, ok := v.(*ssov1.CheckTokenResponse)
+	return user, ok
+}
@@ -0,0 +1,39 @@
+package authMiddleware
+
+import (
+	"github.com/gin-gonic/gin"
+	i18nMiddleware "gym_app/internal/http/middleware/i18n"
+	"gym_app/internal/lib/api/response"
+	"gym_app/internal/lib/permission"
+	"gym_app/internal/lib/requestctx"
+	"log/slog"
+	"net/http"
+)
+
+// RequirePermission must run after AuthMiddleware: it reads the roles of the
+// authenticated user and checks them against the policy.
+func RequirePermission(log *slog.Logger, policy *permission.Policy, perm string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "middleware.RequirePermission"
+
+		log := requestctx.Logger(c.Request.Context(), log).With(
+			slog.String("op", op),
+			slog.String("permission", perm),
+		)
+
+		user, ok := GetUserFromContext(c)
+		if !ok {
+			log.Error("user is missing in context")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Error(i18nMiddleware.T(c, "unauthorized")))
+			return
+		}
+
+		if !policy.Allowed(user.Roles, perm) {
+			log.Warn("permission denied", slog.Any("roles", user.Roles))
+			c.AbortWithStatusJSON(http.StatusForbidden, response.Error(i18nMiddleware.Tf(c, "%s permission required", perm)))
+			return
+		}
+
+		c.Next()
+	}
+}
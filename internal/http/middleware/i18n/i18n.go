@@ -0,0 +1,44 @@
+package i18nMiddleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"gym_app/internal/lib/i18n"
+)
+
+const langKey = "lang"
+
+// New picks the response language from the Accept-Language header, falling
+// back to defaultLang, and reports it in the Content-Language header.
+func New(defaultLang string) gin.HandlerFunc {
+	if !i18n.Supported(defaultLang) {
+		defaultLang = i18n.Default
+	}
+
+	return func(c *gin.Context) {
+		lang := i18n.Negotiate(c.GetHeader("Accept-Language"), defaultLang)
+
+		c.Set(langKey, lang)
+		c.Header("Content-Language", lang)
+
+		c.Next()
+	}
+}
+
+// Lang returns the language negotiated for the request.
+func Lang(c *gin.Context) string {
+	if lang := c.GetString(langKey); lang != "" {
+		return lang
+	}
+
+	return i18n.Default
+}
+
+// T translates a message into the language of the request.
+func T(c *gin.Context, msg string) string {
+	return i18n.T(Lang(c), msg)
+}
+
+// Tf translates a format string into the language of the request and formats it.
+func Tf(c *gin.Context, format string, args ...any) string {
+	return i18n.Tf(Lang(c), format, args...)
+}
@@ -2,6 +2,8 @@ package loggerMiddleware
 
 import (
 	"github.com/gin-gonic/gin"
+	tenantMiddleware "gym_app/internal/http/middleware/tenant"
+	"gym_app/internal/lib/requestctx"
 	"log/slog"
 	"time"
 )
@@ -10,21 +12,30 @@ func New(log *slog.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 
-		log = log.With(
+		c.Next()
+
+		log := requestctx.Logger(c.Request.Context(), log).With(
 			slog.String("component", "middleware/logger"),
 		)
 
-		c.Next()
-
 		duration := time.Since(start)
 
-		log.Info("HTTP Request",
+		attrs := []any{
 			slog.String("method", c.Request.Method),
 			slog.String("path", c.Request.URL.Path),
 			slog.Int("status", c.Writer.Status()),
 			slog.String("client_ip", c.ClientIP()),
 			slog.Duration("duration", duration),
 			slog.String("user_agent", c.Request.UserAgent()),
-		)
+		}
+
+		if staff, ok := tenantMiddleware.Staff(c); ok {
+			attrs = append(attrs,
+				slog.String("staff", staff.FullName),
+				slog.Int64("gym_id", staff.GymID),
+			)
+		}
+
+		log.Info("HTTP Request", attrs...)
 	}
 }
@@ -0,0 +1,29 @@
+package metricsMiddleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"gym_app/internal/lib/metrics"
+	"strconv"
+	"time"
+)
+
+// unmatchedRoute labels requests that matched no route, so that random
+// paths do not create new series.
+const unmatchedRoute = "unmatched"
+
+// New records the count and latency of requests per route template.
+func New() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		metrics.HTTPRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		metrics.HTTPDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,176 @@
+package rateLimitMiddleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	i18nMiddleware "gym_app/internal/http/middleware/i18n"
+	"gym_app/internal/lib/api/response"
+	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/ratelimit"
+	"gym_app/internal/lib/requestctx"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxBodySize limits how much of the body is read to find the email.
+const maxBodySize = 64 << 10
+
+type Limiter struct {
+	log   *slog.Logger
+	store ratelimit.Store
+}
+
+func New(log *slog.Logger, store ratelimit.Store) *Limiter {
+	return &Limiter{
+		log:   log,
+		store: store,
+	}
+}
+
+// ByIP limits requests per client IP. The scope separates the buckets of
+// different endpoints.
+func (l *Limiter) ByIP(scope string, rule ratelimit.Rule) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if l.take(c, "ip:"+scope+":"+c.ClientIP(), rule) {
+			c.Next()
+		}
+	}
+}
+
+// ByEmail limits requests per email given in the JSON body and client IP,
+// so requests from other addresses can't use up the owner's bucket.
+// Requests without an email are left to the handler's validation.
+func (l *Limiter) ByEmail(scope string, rule ratelimit.Rule) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		email := bodyEmail(c)
+		if email == "" || l.take(c, "email:"+scope+":"+email+":"+c.ClientIP(), rule) {
+			c.Next()
+		}
+	}
+}
+
+// Lockout locks an email for the client IP after repeated failed logins.
+// A response with 401 counts as a failure, a successful one resets the
+// counter. The lock is per email and IP, so guessing passwords from one
+// address can't lock the owner out; attempts spread over many addresses
+// are bounded by ByIP for each of them.
+func (l *Limiter) Lockout(lockout ratelimit.Lockout) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "middleware.ratelimit.Lockout"
+
+		log := requestctx.Logger(c.Request.Context(), l.log).With(
+			slog.String("op", op),
+		)
+
+		email := bodyEmail(c)
+		if email == "" {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		key := "lockout:" + email + ":" + c.ClientIP()
+
+		left, err := l.store.Locked(ctx, key)
+		if err != nil {
+			// The limiter must not take logins down with it
+			log.Error("failed to check lockout", sl.Error(err))
+		}
+
+		if left > 0 {
+			log.Warn("login attempt while locked out", slog.Duration("left", left))
+			abort(c, left, i18nMiddleware.T(c, "too many failed login attempts, try again later"))
+			return
+		}
+
+		c.Next()
+
+		switch status := c.Writer.Status(); {
+		case status == http.StatusUnauthorized:
+			locked, err := l.store.Fail(ctx, key, lockout)
+			if err != nil {
+				log.Error("failed to record failed login", sl.Error(err))
+				return
+			}
+
+			if locked > 0 {
+				log.Warn("email locked out for client IP after failed logins", slog.Duration("for", locked))
+			}
+		case status < http.StatusBadRequest:
+			if err := l.store.Reset(ctx, key); err != nil {
+				log.Error("failed to reset failed logins", sl.Error(err))
+			}
+		}
+	}
+}
+
+// take reports whether the request may proceed, otherwise it answers 429.
+func (l *Limiter) take(c *gin.Context, key string, rule ratelimit.Rule) bool {
+	const op = "middleware.ratelimit.take"
+
+	if rule.Rate <= 0 || rule.Burst <= 0 {
+		return true
+	}
+
+	ok, wait, err := l.store.Take(c.Request.Context(), key, rule)
+	if err != nil {
+		requestctx.Logger(c.Request.Context(), l.log).Error("failed to take token",
+			slog.String("op", op), sl.Error(err))
+		return true
+	}
+
+	if !ok {
+		requestctx.Logger(c.Request.Context(), l.log).Warn("rate limit exceeded",
+			slog.String("op", op), slog.String("key", key))
+		abort(c, wait, i18nMiddleware.T(c, "too many requests, try again later"))
+		return false
+	}
+
+	return true
+}
+
+func abort(c *gin.Context, retryAfter time.Duration, msg string) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, response.Error(msg))
+}
+
+// bodyEmail reads the email from the JSON body and puts the body back for
+// the handler. The result is cached for the other limiters of the chain.
+func bodyEmail(c *gin.Context) string {
+	const emailKey = "ratelimit.email"
+
+	if v, ok := c.Get(emailKey); ok {
+		return v.(string)
+	}
+
+	var email string
+
+	if c.Request.Body != nil {
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxBodySize))
+		if err == nil {
+			var req struct {
+				Email string `json:"email"`
+			}
+			if json.Unmarshal(body, &req) == nil {
+				email = strings.ToLower(strings.TrimSpace(req.Email))
+			}
+		}
+
+		c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+	}
+
+	c.Set(emailKey, email)
+
+	return email
+}
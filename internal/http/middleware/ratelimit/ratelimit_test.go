@@ -0,0 +1,51 @@
+package rateLimitMiddleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"gym_app/internal/lib/ratelimit"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestByEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limiter := New(slog.New(slog.NewTextHandler(io.Discard, nil)), ratelimit.NewMemoryStore())
+
+	engine := gin.New()
+	engine.POST("/login", limiter.ByEmail("login", ratelimit.PerMinute(1, 2)), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	steps := []struct {
+		ip    string
+		email string
+		want  int
+	}{
+		{"10.0.0.1", "owner@example.com", http.StatusOK},
+		{"10.0.0.1", "Owner@Example.com", http.StatusOK},
+		{"10.0.0.1", "owner@example.com", http.StatusTooManyRequests},
+		// Исчерпанный с одного адреса лимит не мешает владельцу войти с другого
+		{"10.0.0.2", "owner@example.com", http.StatusOK},
+		// Другой email с того же адреса считается отдельно
+		{"10.0.0.1", "other@example.com", http.StatusOK},
+		{"10.0.0.1", "owner@example.com", http.StatusTooManyRequests},
+	}
+
+	for i, step := range steps {
+		body := `{"email":"` + step.email + `","password":"secret"}`
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+		req.RemoteAddr = step.ip + ":40000"
+
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+
+		if w.Code != step.want {
+			t.Errorf("step %d: %s from %s got %d, want %d", i, step.email, step.ip, w.Code, step.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package requestCtxMiddleware
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+// New gives every request its own deadline: the request context is canceled
+// when the client goes away or the timeout expires. A zero timeout leaves
+// the deadline to the server.
+//
+// Routes in long, keyed by their full path, get their own timeout. It also
+// moves the server read and write deadlines, so that large uploads and
+// downloads are not cut off by the server timeouts.
+func New(timeout time.Duration, long map[string]time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout := timeout
+
+		if routeTimeout, ok := long[c.FullPath()]; ok {
+			timeout = routeTimeout
+
+			rc := http.NewResponseController(c.Writer)
+			deadline := time.Now().Add(routeTimeout)
+			_ = rc.SetReadDeadline(deadline)
+			_ = rc.SetWriteDeadline(deadline)
+		}
+
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,51 @@
+package requestIDMiddleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"gym_app/internal/lib/requestctx"
+)
+
+// Header is the header the request ID is read from and returned in.
+const Header = "X-Request-ID"
+
+// maxLength limits IDs sent by clients, they end up in every log line.
+const maxLength = 128
+
+// New puts the request ID into the request context and the response
+// headers. The ID of the client (or a proxy) is kept if it looks sane,
+// otherwise a new one is generated.
+func New() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(Header)
+		if !valid(id) {
+			id = newRequestID()
+		}
+
+		c.Request = c.Request.WithContext(requestctx.WithRequestID(c.Request.Context(), id))
+		c.Header(Header, id)
+		c.Next()
+	}
+}
+
+func valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,56 @@
+package securityMiddleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"gym_app/internal/config"
+	"strconv"
+)
+
+// SwaggerCSP lets the Swagger UI load its own scripts, styles and images.
+const SwaggerCSP = "default-src 'self'; script-src 'self' 'unsafe-inline'; " +
+	"style-src 'self' 'unsafe-inline'; img-src 'self' data:; frame-ancestors 'none'"
+
+// New sets the security headers of every response. HSTS is only sent over
+// HTTPS (directly or behind a proxy), browsers ignore it otherwise.
+func New(cfg config.SecurityHeaders) gin.HandlerFunc {
+	hsts := ""
+	if cfg.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(int(cfg.HSTSMaxAge.Seconds()))
+		if cfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("Cross-Origin-Opener-Policy", "same-origin")
+
+		if cfg.FrameOptions != "" {
+			h.Set("X-Frame-Options", cfg.FrameOptions)
+		}
+
+		if cfg.ReferrerPolicy != "" {
+			h.Set("Referrer-Policy", cfg.ReferrerPolicy)
+		}
+
+		if cfg.ContentSecurityPolicy != "" {
+			h.Set("Content-Security-Policy", cfg.ContentSecurityPolicy)
+		}
+
+		if hsts != "" && (c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https") {
+			h.Set("Strict-Transport-Security", hsts)
+		}
+
+		c.Next()
+	}
+}
+
+// CSP overrides the Content-Security-Policy of a route group.
+func CSP(policy string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Content-Security-Policy", policy)
+		c.Next()
+	}
+}
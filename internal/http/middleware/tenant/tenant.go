@@ -0,0 +1,75 @@
+package tenantMiddleware
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	authMiddleware "gym_app/internal/http/middleware/auth"
+	i18nMiddleware "gym_app/internal/http/middleware/i18n"
+	"gym_app/internal/lib/api/response"
+	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/requestctx"
+	"gym_app/internal/models"
+	staffService "gym_app/internal/services/staff"
+	"log/slog"
+	"net/http"
+)
+
+const staffContextKey = "staff"
+
+type StaffResolver interface {
+	FindStaffByUserID(ctx context.Context, userID int64) (models.Staff, error)
+}
+
+// New resolves the staff member behind the authenticated user and, through
+// it, the branch whose data the request works with. It must run after
+// authMiddleware.AuthMiddleware.
+func New(log *slog.Logger, resolver StaffResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "middleware.tenant"
+
+		log := requestctx.Logger(c.Request.Context(), log).With(
+			slog.String("op", op),
+		)
+
+		user, ok := authMiddleware.GetUserFromContext(c)
+		if !ok {
+			log.Error("user is missing in context")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Error(i18nMiddleware.T(c, "unauthorized")))
+			return
+		}
+
+		staff, err := resolver.FindStaffByUserID(c.Request.Context(), user.GetUserId())
+		if err != nil {
+			if errors.Is(err, staffService.ErrStaffNotFound) {
+				log.Warn("user is not registered as staff", slog.Int64("user_id", user.GetUserId()))
+				c.AbortWithStatusJSON(http.StatusForbidden, response.Error(i18nMiddleware.T(c, "user is not registered as staff")))
+				return
+			}
+
+			log.Error("failed to resolve staff member", sl.Error(err))
+			c.AbortWithStatusJSON(http.StatusInternalServerError, response.Error(i18nMiddleware.T(c, "failed to resolve staff member")))
+			return
+		}
+
+		c.Set(staffContextKey, staff)
+		c.Next()
+	}
+}
+
+// Staff returns the staff member resolved by the middleware.
+func Staff(c *gin.Context) (models.Staff, bool) {
+	v, ok := c.Get(staffContextKey)
+	if !ok {
+		return models.Staff{}, false
+	}
+
+	staff, ok := v.(models.Staff)
+	return staff, ok
+}
+
+// GymID returns the branch of the staff member resolved by the middleware.
+func GymID(c *gin.Context) int64 {
+	staff, _ := Staff(c)
+	return staff.GymID
+}
@@ -1,10 +1,13 @@
 package response
 
+import "gym_app/internal/lib/validation"
+
 // swagger:model Response
 type Response struct {
-	Status string `json:"status"`
-	Error  string `json:"error,omitempty"`
-	Msg    string `json:"msg,omitempty"`
+	Status string                  `json:"status"`
+	Error  string                  `json:"error,omitempty"`
+	Msg    string                  `json:"msg,omitempty"`
+	Fields []validation.FieldError `json:"fields,omitempty"`
 }
 
 const (
@@ -26,22 +29,12 @@ func Error(msg string) Response {
 	}
 }
 
-//func ValidationError(errs validator.ValidationErrors) Response {
-//	errMsgs := []string{}
-//
-//	for _, err := range errs {
-//		switch err.ActualTag() {
-//		case "required":
-//			errMsgs = append(errMsgs, fmt.Sprintf("field %s is a required field", err.Field()))
-//		case "email":
-//			errMsgs = append(errMsgs, fmt.Sprintf("field %s is not a valid Email", err.Field()))
-//		default:
-//			errMsgs = append(errMsgs, fmt.Sprintf("field %s is not valid", err.Field()))
-//		}
-//	}
-//
-//	return Response{
-//		Status: StatusError,
-//		Error:  strings.Join(errMsgs, ", "),
-//	}
-//}
+// ValidationError lists the invalid fields of a request body. msg is the
+// already translated summary, like the field messages.
+func ValidationError(msg string, errs validation.Errors) Response {
+	return Response{
+		Status: StatusError,
+		Error:  msg,
+		Fields: errs,
+	}
+}
@@ -0,0 +1,47 @@
+package cardnumber
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Generator builds card numbers of the form <prefix><sequence><check digit>,
+// where the sequence is zero-padded to a fixed width and the check digit is
+// computed with the Luhn algorithm over the sequence part.
+type Generator struct {
+	prefix string
+	digits int
+}
+
+func New(prefix string, digits int) *Generator {
+	return &Generator{
+		prefix: prefix,
+		digits: digits,
+	}
+}
+
+// Format returns the card number for the given sequence value.
+func (g *Generator) Format(seq int64) string {
+	body := fmt.Sprintf("%0*d", g.digits, seq)
+
+	return g.prefix + body + strconv.Itoa(checkDigit(body))
+}
+
+func checkDigit(body string) int {
+	sum := 0
+	double := true
+
+	for i := len(body) - 1; i >= 0; i-- {
+		d := int(body[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+
+	return (10 - sum%10) % 10
+}
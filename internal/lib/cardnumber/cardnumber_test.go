@@ -0,0 +1,68 @@
+package cardnumber
+
+import "testing"
+
+func TestCheckDigit(t *testing.T) {
+	tests := []struct {
+		body string
+		want int
+	}{
+		{"7992739871", 3},
+		{"4539148803436467"[:15], 7},
+		{"0", 0},
+		{"000000", 0},
+		{"000001", 8},
+		{"000042", 2},
+		{"999999", 6},
+	}
+
+	for _, tt := range tests {
+		if got := checkDigit(tt.body); got != tt.want {
+			t.Errorf("checkDigit(%q) = %d, want %d", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		prefix string
+		digits int
+		seq    int64
+		want   string
+	}{
+		{"", 6, 1, "0000018"},
+		{"GYM", 6, 42, "GYM0000422"},
+		{"GYM", 6, 999999, "GYM9999996"},
+		// Последовательность длиннее ширины не обрезается
+		{"C", 2, 1234, "C12344"},
+		{"", 10, 7992739871, "79927398713"},
+	}
+
+	for _, tt := range tests {
+		got := New(tt.prefix, tt.digits).Format(tt.seq)
+		if got != tt.want {
+			t.Errorf("Format(%q, %d, %d) = %q, want %q", tt.prefix, tt.digits, tt.seq, got, tt.want)
+		}
+
+		if !luhnValid(got[len(tt.prefix):]) {
+			t.Errorf("Format(%q, %d, %d) = %q, digits fail the Luhn check", tt.prefix, tt.digits, tt.seq, got)
+		}
+	}
+}
+
+// luhnValid is the textbook Luhn check, independent of checkDigit.
+func luhnValid(number string) bool {
+	sum := 0
+	for i := 0; i < len(number); i++ {
+		d := int(number[len(number)-1-i] - '0')
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+
+	return sum%10 == 0
+}
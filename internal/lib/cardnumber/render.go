@@ -0,0 +1,116 @@
+package cardnumber
+
+import (
+	"errors"
+	"fmt"
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/qr"
+	"image/png"
+	"io"
+)
+
+const (
+	KindCode128 = "code128"
+	KindQR      = "qr"
+
+	FormatPNG = "png"
+	FormatSVG = "svg"
+
+	// Размеры изображения для печати на карте
+	code128Width  = 400
+	code128Height = 120
+	qrSize        = 256
+)
+
+var (
+	ErrUnknownKind   = errors.New("unknown barcode kind")
+	ErrUnknownFormat = errors.New("unknown image format")
+)
+
+// ContentType returns the MIME type of the image format.
+func ContentType(format string) string {
+	if format == FormatSVG {
+		return "image/svg+xml"
+	}
+
+	return "image/png"
+}
+
+// Render writes the number encoded as a Code128 barcode or a QR code in PNG
+// or SVG format.
+func Render(w io.Writer, number, kind, format string) error {
+	const op = "lib.cardnumber.Render"
+
+	var (
+		code          barcode.Barcode
+		width, height int
+		err           error
+	)
+
+	switch kind {
+	case KindCode128:
+		code, err = code128.Encode(number)
+		width, height = code128Width, code128Height
+	case KindQR:
+		code, err = qr.Encode(number, qr.M, qr.Auto)
+		width, height = qrSize, qrSize
+	default:
+		return fmt.Errorf("%s: %w", op, ErrUnknownKind)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	switch format {
+	case FormatPNG:
+		scaled, err := barcode.Scale(code, width, height)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		if err := png.Encode(w, scaled); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	case FormatSVG:
+		if err := writeSVG(w, code, width, height); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	default:
+		return fmt.Errorf("%s: %w", op, ErrUnknownFormat)
+	}
+
+	return nil
+}
+
+// writeSVG draws every dark module of the code as a rectangle. Linear
+// barcodes are one module high and are stretched to the full height.
+func writeSVG(w io.Writer, code barcode.Barcode, width, height int) error {
+	bounds := code.Bounds()
+	cols, rows := bounds.Dx(), bounds.Dy()
+
+	if _, err := fmt.Fprintf(w,
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" preserveAspectRatio="none" shape-rendering="crispEdges">`+
+			`<rect width="100%%" height="100%%" fill="#fff"/>`,
+		width, height, cols, rows,
+	); err != nil {
+		return err
+	}
+
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			r, _, _, _ := code.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			if r != 0 {
+				continue
+			}
+
+			if _, err := fmt.Fprintf(w, `<rect x="%d" y="%d" width="1" height="1"/>`, x, y); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, `</svg>`)
+
+	return err
+}
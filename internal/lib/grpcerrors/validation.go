@@ -1,29 +1,74 @@
 package grpcerrors
 
 import (
+	"errors"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"gym_app/internal/lib/i18n"
+	"gym_app/internal/lib/validation"
 	"strings"
 )
 
-func ParseValidationError(err error) string {
-	st, ok := status.FromError(err)
+// ParseValidationError turns an InvalidArgument error of the SSO service
+// into a message in the given language. Field violations are translated one
+// by one; the SSO descriptions are the catalog keys.
+func ParseValidationError(err error, lang string) string {
+	st, ok := statusOf(err)
 	if !ok || st.Code() != codes.InvalidArgument {
 		return err.Error()
 	}
 
-	for _, detail := range st.Details() {
-		switch t := detail.(type) {
-		case *errdetails.BadRequest:
-			var msgs []string
-			for _, v := range t.FieldViolations {
-				msgs = append(msgs, v.Field+": "+v.Description)
-			}
-			return strings.Join(msgs, "; ")
+	if violations := fieldViolations(st); len(violations) > 0 {
+		var msgs []string
+		for _, v := range violations {
+			msgs = append(msgs, v.Field+": "+i18n.T(lang, v.Description))
 		}
+		return strings.Join(msgs, "; ")
 	}
 
 	// fallback
-	return st.Message()
+	return i18n.T(lang, st.Message())
+}
+
+// FieldErrors returns the field violations of an InvalidArgument error of the
+// SSO service in the format of the API validation errors. It returns nil if
+// the error carries no violations.
+func FieldErrors(err error, lang string) validation.Errors {
+	st, ok := statusOf(err)
+	if !ok || st.Code() != codes.InvalidArgument {
+		return nil
+	}
+
+	var errs validation.Errors
+	for _, v := range fieldViolations(st) {
+		errs = append(errs, validation.FieldError{
+			Field:   v.Field,
+			Code:    "invalid",
+			Message: i18n.T(lang, v.Description),
+		})
+	}
+
+	return errs
+}
+
+// statusOf finds the gRPC status in the error chain. Unlike status.FromError
+// it keeps the original message of the SSO service.
+func statusOf(err error) (*status.Status, bool) {
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if !errors.As(err, &grpcErr) {
+		return nil, false
+	}
+
+	return grpcErr.GRPCStatus(), true
+}
+
+func fieldViolations(st *status.Status) []*errdetails.BadRequest_FieldViolation {
+	for _, detail := range st.Details() {
+		if t, ok := detail.(*errdetails.BadRequest); ok {
+			return t.FieldViolations
+		}
+	}
+
+	return nil
 }
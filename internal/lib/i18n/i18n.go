@@ -0,0 +1,137 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	RU = "ru"
+	EN = "en"
+
+	// Default is the language of messages produced outside of HTTP requests.
+	Default = RU
+)
+
+// catalog holds the messages of one language. Messages are keyed by their
+// English text, so a missing translation falls back to English. Validation
+// messages are keyed by scope, JSON field name and validator tag (see
+// FieldMessage) and may contain a {param} placeholder.
+type catalog struct {
+	Messages   map[string]string `json:"messages"`
+	Validation map[string]string `json:"validation"`
+}
+
+//go:embed locales/*.json
+var locales embed.FS
+
+var catalogs = mustLoad(RU, EN)
+
+func mustLoad(langs ...string) map[string]catalog {
+	catalogs := make(map[string]catalog, len(langs))
+
+	for _, lang := range langs {
+		data, err := locales.ReadFile("locales/" + lang + ".json")
+		if err != nil {
+			panic(fmt.Sprintf("i18n: failed to read %s catalog: %s", lang, err))
+		}
+
+		var c catalog
+		if err := json.Unmarshal(data, &c); err != nil {
+			panic(fmt.Sprintf("i18n: failed to parse %s catalog: %s", lang, err))
+		}
+
+		catalogs[lang] = c
+	}
+
+	return catalogs
+}
+
+// Supported reports whether there is a catalog for the language.
+func Supported(lang string) bool {
+	_, ok := catalogs[lang]
+	return ok
+}
+
+// T translates a message. Unknown messages are returned as is.
+func T(lang, msg string) string {
+	if translated, ok := catalogs[lang].Messages[msg]; ok {
+		return translated
+	}
+
+	return msg
+}
+
+// Tf translates a format string and then formats it with args.
+func Tf(lang, format string, args ...any) string {
+	return fmt.Sprintf(T(lang, format), args...)
+}
+
+// FieldMessage returns the message for a failed validation check. The most
+// specific key wins: scope.field.code, scope.field, field.code, field, code.
+func FieldMessage(lang, scope, field, code, param string) string {
+	keys := []string{
+		scope + "." + field + "." + code,
+		scope + "." + field,
+		field + "." + code,
+		field,
+		code,
+		"invalid",
+	}
+
+	for _, l := range []string{lang, Default} {
+		msgs := catalogs[l].Validation
+
+		for _, key := range keys {
+			if msg, ok := msgs[key]; ok {
+				return strings.ReplaceAll(msg, "{param}", param)
+			}
+		}
+	}
+
+	return code
+}
+
+// Negotiate picks the supported language preferred by an Accept-Language
+// header, e.g. "en-US,en;q=0.9,ru;q=0.8". It returns fallback when the header
+// names no supported language.
+func Negotiate(acceptLanguage, fallback string) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+
+	var candidates []candidate
+
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		lang, _, _ := strings.Cut(strings.ToLower(tag), "-")
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		if q > 0 && Supported(lang) {
+			candidates = append(candidates, candidate{lang: lang, q: q})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return fallback
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+
+	return candidates[0].lang
+}
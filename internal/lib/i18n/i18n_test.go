@@ -0,0 +1,36 @@
+package i18n
+
+import "testing"
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header   string
+		fallback string
+		want     string
+	}{
+		{"", "ru", "ru"},
+		{"en", "ru", "en"},
+		{"en-US,en;q=0.9,ru;q=0.8", "ru", "en"},
+		{"ru-RU,ru;q=0.9,en-US;q=0.8,en;q=0.7", "en", "ru"},
+		{"EN-gb", "ru", "en"},
+		// Побеждает наибольший q, а не порядок в заголовке
+		{"ru;q=0.5, en;q=0.8", "ru", "en"},
+		// При равных q остается первый
+		{"en;q=0.7,ru;q=0.7", "ru", "en"},
+		// Неподдерживаемые языки пропускаются
+		{"de-DE,de;q=0.9,en;q=0.5", "ru", "en"},
+		{"fr, de", "ru", "ru"},
+		// q=0 означает отказ от языка
+		{"en;q=0, ru;q=0.1", "en", "ru"},
+		{"en;q=0", "ru", "ru"},
+		// Некорректный q пропускает только этот язык
+		{"en;q=abc, ru;q=0.3", "en", "ru"},
+		{"*", "ru", "ru"},
+	}
+
+	for _, tt := range tests {
+		if got := Negotiate(tt.header, tt.fallback); got != tt.want {
+			t.Errorf("Negotiate(%q, %q) = %q, want %q", tt.header, tt.fallback, got, tt.want)
+		}
+	}
+}
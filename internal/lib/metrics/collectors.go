@@ -0,0 +1,119 @@
+package metrics
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/models"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+// PoolStater is implemented by postgres.Storage.
+type PoolStater interface {
+	PoolStat() *pgxpool.Stat
+}
+
+// PoolCollector exports the statistics of the pgx connection pool.
+type PoolCollector struct {
+	pool PoolStater
+
+	acquired     *prometheus.Desc
+	idle         *prometheus.Desc
+	total        *prometheus.Desc
+	max          *prometheus.Desc
+	acquires     *prometheus.Desc
+	acquireTime  *prometheus.Desc
+	emptyWaits   *prometheus.Desc
+	canceledAcqs *prometheus.Desc
+}
+
+func NewPoolCollector(pool PoolStater) *PoolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+
+	return &PoolCollector{
+		pool:         pool,
+		acquired:     desc("acquired_connections", "Connections currently in use."),
+		idle:         desc("idle_connections", "Idle connections."),
+		total:        desc("total_connections", "Open connections."),
+		max:          desc("max_connections", "Maximum size of the pool."),
+		acquires:     desc("acquires_total", "Successful connection acquires."),
+		acquireTime:  desc("acquire_duration_seconds_total", "Total time spent acquiring connections."),
+		emptyWaits:   desc("empty_acquires_total", "Acquires that had to wait for a connection."),
+		canceledAcqs: desc("canceled_acquires_total", "Acquires canceled by their context."),
+	}
+}
+
+func (c *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.PoolStat()
+
+	ch <- prometheus.MustNewConstMetric(c.acquired, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.max, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquires, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireTime, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.emptyWaits, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.canceledAcqs, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+}
+
+// MembershipCounter is implemented by postgres.Storage.
+type MembershipCounter interface {
+	CountPersonSubsByStatus(ctx context.Context) ([]models.PersonSubStatusCount, error)
+}
+
+// MembershipCollector exports the number of memberships per gym and status.
+// The numbers are read from the database on every scrape.
+type MembershipCollector struct {
+	log     *slog.Logger
+	counter MembershipCounter
+	timeout time.Duration
+
+	memberships *prometheus.Desc
+}
+
+func NewMembershipCollector(log *slog.Logger, counter MembershipCounter, timeout time.Duration) *MembershipCollector {
+	return &MembershipCollector{
+		log:     log,
+		counter: counter,
+		timeout: timeout,
+		memberships: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "memberships"),
+			"Memberships by gym and status.",
+			[]string{"gym_id", "status"}, nil,
+		),
+	}
+}
+
+func (c *MembershipCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.memberships
+}
+
+func (c *MembershipCollector) Collect(ch chan<- prometheus.Metric) {
+	const op = "metrics.MembershipCollector.Collect"
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	counts, err := c.counter.CountPersonSubsByStatus(ctx)
+	if err != nil {
+		c.log.Error("failed to count memberships", slog.String("op", op), sl.Error(err))
+		ch <- prometheus.NewInvalidMetric(c.memberships, err)
+		return
+	}
+
+	for _, cnt := range counts {
+		ch <- prometheus.MustNewConstMetric(
+			c.memberships, prometheus.GaugeValue, float64(cnt.Count),
+			strconv.FormatInt(cnt.GymID, 10), cnt.Status,
+		)
+	}
+}
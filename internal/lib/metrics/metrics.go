@@ -0,0 +1,76 @@
+// Package metrics holds the Prometheus collectors of the app. All of them
+// are registered in Registry, which is served on the metrics endpoint.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "gym"
+
+// Registry is the registry exposed on the metrics endpoint.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+var (
+	HTTPRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests by route, method and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of HTTP requests by route and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	SSORequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "sso",
+		Name:      "requests_total",
+		Help:      "Calls to the SSO service by method and gRPC code.",
+	}, []string{"method", "code"})
+
+	SSODuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "sso",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of calls to the SSO service, retries included.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	CronRuns = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cron",
+		Name:      "runs_total",
+		Help:      "Cron job runs by job and outcome (success, failure).",
+	}, []string{"job", "outcome"})
+
+	CronDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "cron",
+		Name:      "run_duration_seconds",
+		Help:      "Duration of cron job runs.",
+		Buckets:   []float64{0.1, 0.5, 1, 5, 15, 60, 300},
+	}, []string{"job"})
+)
+
+// Outcomes of a cron job run.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
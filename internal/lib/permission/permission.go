@@ -0,0 +1,93 @@
+package permission
+
+// Permissions checked by the HTTP layer. Roles coming from SSO are mapped
+// to these names via the access section of the config.
+const (
+	PeopleRead         = "people.read"
+	PeopleWrite        = "people.write"
+	PeopleMerge        = "people.merge"
+	SubscriptionsRead  = "subscriptions.read"
+	SubscriptionsWrite = "subscriptions.write"
+	MembershipsRead    = "memberships.read"
+	MembershipsWrite   = "memberships.write"
+	GymsManage         = "gyms.manage"
+	StaffManage        = "staff.manage"
+	ClassesRead        = "classes.read"
+	ClassesManage      = "classes.manage"
+	ClassesBook        = "classes.book"
+	ClassesAttend      = "classes.attend"
+	TrainingRead       = "training.read"
+	TrainingManage     = "training.manage"
+	TrainingSell       = "training.sell"
+	TrainingComplete   = "training.complete"
+)
+
+const (
+	RoleUser         = "user"
+	RoleTrainer      = "trainer"
+	RoleReceptionist = "receptionist"
+	RoleManager      = "manager"
+	RoleAdmin        = "admin"
+)
+
+// DefaultRoles is used when the config does not define any role mapping.
+var DefaultRoles = map[string][]string{
+	RoleUser: {
+		PeopleRead, SubscriptionsRead, MembershipsRead,
+		ClassesRead,
+	},
+	RoleTrainer: {
+		PeopleRead, SubscriptionsRead, MembershipsRead,
+		ClassesRead, ClassesAttend,
+		TrainingRead, TrainingComplete,
+	},
+	RoleReceptionist: {
+		PeopleRead, PeopleWrite, SubscriptionsRead, MembershipsRead, MembershipsWrite,
+		ClassesRead, ClassesBook, ClassesAttend,
+		TrainingRead, TrainingSell,
+	},
+	RoleManager: {
+		PeopleRead, PeopleWrite, SubscriptionsRead, SubscriptionsWrite, MembershipsRead, MembershipsWrite,
+		ClassesRead, ClassesManage, ClassesBook, ClassesAttend,
+		TrainingRead, TrainingManage, TrainingSell, TrainingComplete,
+	},
+	RoleAdmin: {
+		PeopleRead, PeopleWrite, SubscriptionsRead, SubscriptionsWrite, MembershipsRead, MembershipsWrite,
+		ClassesRead, ClassesManage, ClassesBook, ClassesAttend,
+		TrainingRead, TrainingManage, TrainingSell, TrainingComplete,
+		GymsManage, StaffManage, PeopleMerge,
+	},
+}
+
+type Policy struct {
+	roles map[string]map[string]struct{}
+}
+
+func New(roles map[string][]string) *Policy {
+	if len(roles) == 0 {
+		roles = DefaultRoles
+	}
+
+	p := &Policy{roles: make(map[string]map[string]struct{}, len(roles))}
+
+	for role, perms := range roles {
+		set := make(map[string]struct{}, len(perms))
+		for _, perm := range perms {
+			set[perm] = struct{}{}
+		}
+		p.roles[role] = set
+	}
+
+	return p
+}
+
+// Allowed reports whether any of the given roles grants the permission.
+func (p *Policy) Allowed(roles []string, permission string) bool {
+	for _, role := range roles {
+		if _, ok := p.roles[role][permission]; ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,63 @@
+package permission
+
+import "testing"
+
+func TestPolicyAllowed(t *testing.T) {
+	policy := New(map[string][]string{
+		"reception": {PeopleRead, PeopleWrite},
+		"viewer":    {PeopleRead},
+		"empty":     {},
+	})
+
+	tests := []struct {
+		name       string
+		roles      []string
+		permission string
+		want       bool
+	}{
+		{"granted", []string{"viewer"}, PeopleRead, true},
+		{"not granted", []string{"viewer"}, PeopleWrite, false},
+		{"any role grants", []string{"viewer", "reception"}, PeopleWrite, true},
+		{"unknown role", []string{"ghost"}, PeopleRead, false},
+		{"role without permissions", []string{"empty"}, PeopleRead, false},
+		{"no roles", nil, PeopleRead, false},
+		{"unknown permission", []string{"reception"}, "people.destroy", false},
+		{"role names are case sensitive", []string{"Viewer"}, PeopleRead, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.Allowed(tt.roles, tt.permission); got != tt.want {
+				t.Errorf("Allowed(%v, %q) = %v, want %v", tt.roles, tt.permission, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultRoles(t *testing.T) {
+	policy := New(nil)
+
+	tests := []struct {
+		role       string
+		permission string
+		want       bool
+	}{
+		{RoleUser, PeopleRead, true},
+		{RoleUser, PeopleWrite, false},
+		{RoleTrainer, TrainingComplete, true},
+		{RoleTrainer, TrainingSell, false},
+		{RoleReceptionist, MembershipsWrite, true},
+		{RoleReceptionist, SubscriptionsWrite, false},
+		{RoleManager, TrainingManage, true},
+		{RoleManager, StaffManage, false},
+		{RoleManager, PeopleMerge, false},
+		{RoleAdmin, GymsManage, true},
+		{RoleAdmin, PeopleMerge, true},
+	}
+
+	for _, tt := range tests {
+		if got := policy.Allowed([]string{tt.role}, tt.permission); got != tt.want {
+			t.Errorf("default %s: Allowed(%q) = %v, want %v", tt.role, tt.permission, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often idle entries are dropped from memory.
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	rule   Rule
+}
+
+type failures struct {
+	count       int
+	first       time.Time
+	window      time.Duration
+	lockedUntil time.Time
+}
+
+// MemoryStore keeps the limiter state in the process memory. It is only
+// correct when the app runs as a single instance.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	failures  map[string]*failures
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:  make(map[string]*bucket),
+		failures: make(map[string]*failures),
+		now:      time.Now,
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, rule Rule) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(rule.Burst), last: now, rule: rule}
+		s.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(rule.Burst), b.tokens+now.Sub(b.last).Seconds()*rule.Rate)
+	b.last = now
+	b.rule = rule
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0, nil
+	}
+
+	wait := time.Duration((1 - b.tokens) / rule.Rate * float64(time.Second))
+
+	return false, wait, nil
+}
+
+func (s *MemoryStore) Fail(_ context.Context, key string, lockout Lockout) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	f, ok := s.failures[key]
+	if !ok || now.Sub(f.first) > lockout.Window {
+		f = &failures{first: now, window: lockout.Window}
+		s.failures[key] = f
+	}
+
+	f.count++
+	if f.count < lockout.MaxFailures {
+		return 0, nil
+	}
+
+	f.count = 0
+	f.first = now
+	f.lockedUntil = now.Add(lockout.Duration)
+
+	return lockout.Duration, nil
+}
+
+func (s *MemoryStore) Locked(_ context.Context, key string) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.failures[key]
+	if !ok {
+		return 0, nil
+	}
+
+	if left := f.lockedUntil.Sub(s.now()); left > 0 {
+		return left, nil
+	}
+
+	return 0, nil
+}
+
+func (s *MemoryStore) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.failures, key)
+
+	return nil
+}
+
+// sweep drops buckets that have refilled and failures that have expired,
+// so that a stream of distinct keys does not grow the maps forever.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		full := time.Duration(float64(b.rule.Burst) / b.rule.Rate * float64(time.Second))
+		if now.Sub(b.last) >= full {
+			delete(s.buckets, key)
+		}
+	}
+
+	for key, f := range s.failures {
+		if now.After(f.lockedUntil) && now.Sub(f.first) > f.window {
+			delete(s.failures, key)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// clock is a manual time source for MemoryStore.
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time { return c.t }
+
+func (c *clock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestStore() (*MemoryStore, *clock) {
+	c := &clock{t: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)}
+	s := NewMemoryStore()
+	s.now = c.now
+
+	return s, c
+}
+
+func TestMemoryStoreTake(t *testing.T) {
+	rule := Rule{Rate: 1, Burst: 2} // 2 запроса сразу, затем 1 в секунду
+
+	steps := []struct {
+		advance time.Duration
+		key     string
+		ok      bool
+		wait    time.Duration
+	}{
+		{0, "a", true, 0},
+		{0, "a", true, 0},
+		{0, "a", false, time.Second},
+		// Другой ключ не зависит от первого
+		{0, "b", true, 0},
+		{500 * time.Millisecond, "a", false, 500 * time.Millisecond},
+		{500 * time.Millisecond, "a", true, 0},
+		{0, "a", false, time.Second},
+		// Корзина не наполняется больше Burst
+		{time.Hour, "a", true, 0},
+		{0, "a", true, 0},
+		{0, "a", false, time.Second},
+	}
+
+	s, c := newTestStore()
+	ctx := context.Background()
+
+	for i, step := range steps {
+		c.advance(step.advance)
+
+		ok, wait, err := s.Take(ctx, step.key, rule)
+		if err != nil {
+			t.Fatalf("step %d: Take: %v", i, err)
+		}
+		if ok != step.ok || wait != step.wait {
+			t.Errorf("step %d: Take(%q) = %v, %v, want %v, %v", i, step.key, ok, wait, step.ok, step.wait)
+		}
+	}
+}
+
+func TestMemoryStoreLockout(t *testing.T) {
+	lockout := Lockout{MaxFailures: 3, Window: 10 * time.Minute, Duration: 15 * time.Minute}
+
+	const (
+		fail  = "fail"
+		reset = "reset"
+		check = "check"
+	)
+
+	type lockoutStep struct {
+		advance time.Duration
+		action  string
+		want    time.Duration // время блокировки из Fail или остаток из Locked
+	}
+
+	tests := []struct {
+		name  string
+		steps []lockoutStep
+	}{
+		{
+			name: "locks after max failures",
+			steps: []lockoutStep{
+				{0, fail, 0},
+				{time.Minute, fail, 0},
+				{0, check, 0},
+				{time.Minute, fail, 15 * time.Minute},
+				{5 * time.Minute, check, 10 * time.Minute},
+				{10 * time.Minute, check, 0},
+			},
+		},
+		{
+			name: "failures outside the window start over",
+			steps: []lockoutStep{
+				{0, fail, 0},
+				{0, fail, 0},
+				{11 * time.Minute, fail, 0},
+				{0, fail, 0},
+				{0, check, 0},
+				{0, fail, 15 * time.Minute},
+			},
+		},
+		{
+			name: "success resets the counter",
+			steps: []lockoutStep{
+				{0, fail, 0},
+				{0, fail, 0},
+				{0, reset, 0},
+				{0, fail, 0},
+				{0, fail, 0},
+				{0, check, 0},
+			},
+		},
+		{
+			name: "failures after the lock count anew",
+			steps: []lockoutStep{
+				{0, fail, 0},
+				{0, fail, 0},
+				{0, fail, 15 * time.Minute},
+				{16 * time.Minute, fail, 0},
+				{0, check, 0},
+			},
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, c := newTestStore()
+
+			for i, step := range tt.steps {
+				c.advance(step.advance)
+
+				var (
+					got time.Duration
+					err error
+				)
+				switch step.action {
+				case fail:
+					got, err = s.Fail(ctx, "user@example.com", lockout)
+				case check:
+					got, err = s.Locked(ctx, "user@example.com")
+				case reset:
+					err = s.Reset(ctx, "user@example.com")
+				}
+				if err != nil {
+					t.Fatalf("step %d: %s: %v", i, step.action, err)
+				}
+				if got != step.want {
+					t.Errorf("step %d: %s = %v, want %v", i, step.action, got, step.want)
+				}
+			}
+
+			// Другой ключ не заблокирован
+			if left, _ := s.Locked(ctx, "other@example.com"); left != 0 {
+				t.Errorf("Locked(other) = %v, want 0", left)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	s, c := newTestStore()
+	ctx := context.Background()
+
+	rule := Rule{Rate: 1, Burst: 1}
+	lockout := Lockout{MaxFailures: 5, Window: time.Minute, Duration: time.Minute}
+
+	if _, _, err := s.Take(ctx, "a", rule); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Fail(ctx, "a", lockout); err != nil {
+		t.Fatal(err)
+	}
+
+	c.advance(2 * sweepInterval)
+
+	if _, _, err := s.Take(ctx, "b", rule); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := s.buckets["a"]; ok {
+		t.Error("refilled bucket was not swept")
+	}
+	if _, ok := s.failures["a"]; ok {
+		t.Error("expired failures were not swept")
+	}
+}
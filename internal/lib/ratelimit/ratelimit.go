@@ -0,0 +1,41 @@
+// Package ratelimit implements token bucket rate limiting and lockout after
+// repeated failures. The state lives in a Store; MemoryStore keeps it in the
+// process, other implementations can share it between instances.
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Rule is a token bucket: Burst requests at once, refilled at Rate tokens
+// per second.
+type Rule struct {
+	Rate  float64
+	Burst int
+}
+
+// PerMinute returns a rule refilling n tokens per minute.
+func PerMinute(n float64, burst int) Rule {
+	return Rule{Rate: n / 60, Burst: burst}
+}
+
+// Lockout locks a key for Duration after MaxFailures failures within Window.
+type Lockout struct {
+	MaxFailures int
+	Window      time.Duration
+	Duration    time.Duration
+}
+
+type Store interface {
+	// Take takes a token from the bucket of key. If the bucket is empty it
+	// returns false and the time until the next token.
+	Take(ctx context.Context, key string, rule Rule) (bool, time.Duration, error)
+	// Fail records a failed attempt for key. It returns the lock time if the
+	// attempt locked the key, zero otherwise.
+	Fail(ctx context.Context, key string, lockout Lockout) (time.Duration, error)
+	// Locked returns the time left until key is unlocked, zero if it is not.
+	Locked(ctx context.Context, key string) (time.Duration, error)
+	// Reset forgets the failures of key.
+	Reset(ctx context.Context, key string) error
+}
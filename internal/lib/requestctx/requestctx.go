@@ -0,0 +1,60 @@
+// Package requestctx carries request-scoped values (request ID, user ID)
+// through context.Context, so that every layer can log them together with
+// the trace ID of the current span.
+package requestctx
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+)
+
+type ctxKey int
+
+const (
+	requestIDKey ctxKey = iota
+	userIDKey
+)
+
+// WithRequestID returns a copy of ctx that carries the request ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the request ID stored in ctx.
+func RequestID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey).(string)
+	return id, ok && id != ""
+}
+
+// WithUserID returns a copy of ctx that carries the ID of the SSO user.
+func WithUserID(ctx context.Context, userID int64) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// UserID returns the ID of the SSO user stored in ctx.
+func UserID(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(userIDKey).(int64)
+	return id, ok
+}
+
+// Logger returns log with the request ID, user ID and trace ID from ctx
+// attached.
+func Logger(ctx context.Context, log *slog.Logger) *slog.Logger {
+	if id, ok := RequestID(ctx); ok {
+		log = log.With(slog.String("request_id", id))
+	}
+
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		log = log.With(
+			slog.String("trace_id", span.TraceID().String()),
+			slog.String("span_id", span.SpanID().String()),
+		)
+	}
+
+	if id, ok := UserID(ctx); ok {
+		log = log.With(slog.Int64("user_id", id))
+	}
+
+	return log
+}
@@ -0,0 +1,302 @@
+// Package spreadsheet reads and writes tables as CSV or XLSX files.
+package spreadsheet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/xuri/excelize/v2"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown spreadsheet format, use csv or xlsx")
+	ErrInvalidFile   = errors.New("file is not a valid spreadsheet")
+)
+
+// utf8BOM lets Excel recognise a CSV file as UTF-8.
+const utf8BOM = "\ufeff"
+
+// dateLayouts are the text forms of dates accepted by ParseDate, day first
+// as in Russian spreadsheets.
+var dateLayouts = []string{"2-1-2006", "2.1.2006", "2/1/2006", "2006-1-2"}
+
+// FormatOf returns the format of a file by its extension.
+func FormatOf(filename string) (string, error) {
+	return Format(strings.TrimPrefix(filepath.Ext(filename), "."))
+}
+
+// Format checks a format name, ignoring case.
+func Format(name string) (string, error) {
+	switch format := strings.ToLower(name); format {
+	case FormatCSV, FormatXLSX:
+		return format, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownFormat, name)
+	}
+}
+
+// ContentType returns the MIME type of a format.
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+
+	return "text/csv; charset=utf-8"
+}
+
+// Read returns the rows of a CSV file or of the first sheet of an XLSX
+// file. XLSX cells are read without their number format, so long phone
+// numbers stay intact and dates come as serial numbers, see ParseDate.
+// The CSV delimiter is a comma or, as Excel writes it in many locales,
+// a semicolon.
+func Read(r io.Reader, format string) ([][]string, error) {
+	const op = "spreadsheet.Read"
+
+	var (
+		rows [][]string
+		err  error
+	)
+
+	switch format {
+	case FormatCSV:
+		rows, err = readCSV(r)
+	case FormatXLSX:
+		rows, err = readXLSX(r)
+	default:
+		return nil, fmt.Errorf("%s: %w", op, ErrUnknownFormat)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: %w", op, ErrInvalidFile, err)
+	}
+
+	return rows, nil
+}
+
+func readCSV(r io.Reader) ([][]string, error) {
+	br := bufio.NewReader(r)
+
+	if bom, err := br.Peek(len(utf8BOM)); err == nil && string(bom) == utf8BOM {
+		_, _ = br.Discard(len(utf8BOM))
+	}
+
+	// The header line decides the delimiter
+	first, err := br.Peek(br.Size())
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, err
+	}
+	if i := bytes.IndexByte(first, '\n'); i >= 0 {
+		first = first[:i]
+	}
+
+	cr := csv.NewReader(br)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	if bytes.Count(first, []byte{';'}) > bytes.Count(first, []byte{','}) {
+		cr.Comma = ';'
+	}
+
+	return cr.ReadAll()
+}
+
+func readXLSX(r io.Reader) ([][]string, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, nil
+	}
+
+	return f.GetRows(sheets[0], excelize.Options{RawCellValue: true})
+}
+
+// Write writes rows as a CSV file or as a single XLSX sheet.
+func Write(w io.Writer, format string, rows [][]string) error {
+	const op = "spreadsheet.Write"
+
+	sw, err := NewWriter(w, format)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer sw.Close()
+
+	for _, row := range rows {
+		cells := make([]any, len(row))
+		for i, v := range row {
+			cells[i] = v
+		}
+
+		if err := sw.Write(cells...); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := sw.Flush(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Writer writes a table row by row without keeping it in memory. CSV rows
+// reach the underlying writer as the buffer fills. XLSX rows are kept in a
+// temporary file, since the sheet can only be written out as a whole, and
+// reach it on Flush.
+//
+// Nothing is written before the first few kilobytes of CSV, so a failure
+// early in an export can still be reported instead of the file.
+type Writer struct {
+	w      io.Writer
+	row    int
+	buf    *bufio.Writer
+	csv    *csv.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+}
+
+func NewWriter(w io.Writer, format string) (*Writer, error) {
+	const op = "spreadsheet.NewWriter"
+
+	sw := &Writer{w: w}
+
+	switch format {
+	case FormatCSV:
+		sw.buf = bufio.NewWriter(w)
+		if _, err := sw.buf.WriteString(utf8BOM); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		sw.csv = csv.NewWriter(sw.buf)
+	case FormatXLSX:
+		sw.file = excelize.NewFile()
+
+		stream, err := sw.file.NewStreamWriter(sw.file.GetSheetName(0))
+		if err != nil {
+			sw.file.Close()
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		sw.stream = stream
+	default:
+		return nil, fmt.Errorf("%s: %w", op, ErrUnknownFormat)
+	}
+
+	return sw, nil
+}
+
+// Write adds a row. Cells are strings, integers, floats or bools; XLSX keeps
+// numbers as numbers. CSV text cells that Excel would take for a formula
+// are escaped, see escapeFormula.
+func (sw *Writer) Write(cells ...any) error {
+	sw.row++
+
+	if sw.csv != nil {
+		record := make([]string, len(cells))
+		for i, v := range cells {
+			record[i] = cellString(v)
+			if _, text := v.(string); text {
+				record[i] = escapeFormula(record[i])
+			}
+		}
+
+		return sw.csv.Write(record)
+	}
+
+	cell, err := excelize.CoordinatesToCellName(1, sw.row)
+	if err != nil {
+		return err
+	}
+
+	return sw.stream.SetRow(cell, cells)
+}
+
+// Flush writes what is left of the table.
+func (sw *Writer) Flush() error {
+	if sw.csv != nil {
+		sw.csv.Flush()
+		if err := sw.csv.Error(); err != nil {
+			return err
+		}
+
+		return sw.buf.Flush()
+	}
+
+	if err := sw.stream.Flush(); err != nil {
+		return err
+	}
+
+	_, err := sw.file.WriteTo(sw.w)
+
+	return err
+}
+
+// Close releases the temporary files of an XLSX table. Rows not flushed
+// are dropped.
+func (sw *Writer) Close() error {
+	if sw.file != nil {
+		return sw.file.Close()
+	}
+
+	return nil
+}
+
+func cellString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// escapeFormula prefixes text starting like a formula with a quote, so that
+// a client named "=HYPERLINK(...)" stays text when the CSV is opened in
+// Excel. XLSX cells are typed and need no escaping.
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+
+	return s
+}
+
+// ParseDate parses a date cell: an Excel serial number or a text date such
+// as 31-12-2024, 31.12.2024 or 2024-12-31.
+func ParseDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if serial, err := strconv.ParseFloat(value, 64); err == nil {
+		return excelize.ExcelDateToTime(serial, false)
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("spreadsheet.ParseDate: unknown date format %q", value)
+}
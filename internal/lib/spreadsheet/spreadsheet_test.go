@@ -0,0 +1,153 @@
+package spreadsheet
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"31-12-2024", date(2024, 12, 31), false},
+		{"1-2-2025", date(2025, 2, 1), false},
+		{"31.12.2024", date(2024, 12, 31), false},
+		{"05.03.2025", date(2025, 3, 5), false},
+		{"31/12/2024", date(2024, 12, 31), false},
+		{"2024-12-31", date(2024, 12, 31), false},
+		{" 2024-12-31 ", date(2024, 12, 31), false},
+		// Даты из XLSX приходят порядковыми номерами Excel
+		{"45292", date(2024, 1, 1), false},
+		{"45658", date(2025, 1, 1), false},
+		{"", time.Time{}, true},
+		{"31-13-2024", time.Time{}, true},
+		{"12/31/2024", time.Time{}, true},
+		{"завтра", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDate(tt.value)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseDate(%q) = %v, want error", tt.value, got)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("ParseDate(%q): %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseDate(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want [][]string
+	}{
+		{
+			name: "comma",
+			file: "name,phone\nИванов Иван,79001112233\n",
+			want: [][]string{{"name", "phone"}, {"Иванов Иван", "79001112233"}},
+		},
+		{
+			name: "semicolon from Excel",
+			file: "name;phone\nИванов Иван;79001112233\n",
+			want: [][]string{{"name", "phone"}, {"Иванов Иван", "79001112233"}},
+		},
+		{
+			name: "semicolon with commas in values",
+			file: "name;note\nИванов Иван;a,b\n",
+			want: [][]string{{"name", "note"}, {"Иванов Иван", "a,b"}},
+		},
+		{
+			name: "header decides, not the data",
+			file: "name,note\nИванов Иван,a;b;c\n",
+			want: [][]string{{"name", "note"}, {"Иванов Иван", "a;b;c"}},
+		},
+		{
+			name: "BOM is stripped",
+			file: utf8BOM + "name;phone\r\nИванов Иван;79001112233\r\n",
+			want: [][]string{{"name", "phone"}, {"Иванов Иван", "79001112233"}},
+		},
+		{
+			name: "rows of different length",
+			file: "name,phone,number\nИванов Иван,79001112233\n",
+			want: [][]string{{"name", "phone", "number"}, {"Иванов Иван", "79001112233"}},
+		},
+		{
+			name: "header only without newline",
+			file: "name;phone",
+			want: [][]string{{"name", "phone"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Read(strings.NewReader(tt.file), FormatCSV)
+			if err != nil {
+				t.Fatalf("Read: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Read = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestWriteCSVEscapesFormulas(t *testing.T) {
+	tests := []struct {
+		cell any
+		want string
+	}{
+		{"Иванов Иван", "Иванов Иван"},
+		{"=HYPERLINK(\"http://evil\",\"x\")", "'=HYPERLINK(\"http://evil\",\"x\")"},
+		{"+cmd|' /C calc'!A0", "'+cmd|' /C calc'!A0"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1:A2)", "'@SUM(A1:A2)"},
+		{"\t=1", "'\t=1"},
+		{"\r=1", "'\r=1"},
+		{"a=b", "a=b"},
+		{"", ""},
+		// Числа записываются как есть
+		{-5, "-5"},
+		{-1.5, "-1.5"},
+	}
+
+	for _, tt := range tests {
+		var b strings.Builder
+
+		sw, err := NewWriter(&b, FormatCSV)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sw.Write(tt.cell, "end"); err != nil {
+			t.Fatal(err)
+		}
+		if err := sw.Flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		rows, err := Read(strings.NewReader(b.String()), FormatCSV)
+		if err != nil {
+			t.Fatalf("Read(%q): %v", b.String(), err)
+		}
+
+		if got := rows[0][0]; got != tt.want {
+			t.Errorf("cell %q written as %q, want %q", tt.cell, got, tt.want)
+		}
+	}
+}
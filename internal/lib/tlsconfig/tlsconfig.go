@@ -0,0 +1,168 @@
+// Package tlsconfig builds TLS configurations from the app config. Key
+// pairs are re-read when their files change, so certificates can be
+// rotated without a restart.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"gym_app/internal/config"
+	"os"
+	"sync"
+	"time"
+)
+
+var ErrNoCACerts = errors.New("no certificates found in CA file")
+
+// Server returns the TLS configuration of the HTTP listener.
+func Server(cfg config.ServerTLS) (*tls.Config, error) {
+	const op = "tlsconfig.Server"
+
+	reloader, err := NewKeyPairReloader(cfg.CertFile, cfg.KeyFile, cfg.ReloadInterval)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}, nil
+}
+
+// Client returns the TLS configuration of a gRPC client: the server is
+// verified against the CA bundle (or the system roots), and a client
+// certificate is presented when one is configured (mTLS).
+func Client(cfg config.ClientTLS) (*tls.Config, error) {
+	const op = "tlsconfig.Client"
+
+	tlsCfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: cfg.ServerName,
+	}
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%s: %s: %w", op, cfg.CAFile, ErrNoCACerts)
+		}
+
+		tlsCfg.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		reloader, err := NewKeyPairReloader(cfg.CertFile, cfg.KeyFile, cfg.ReloadInterval)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		tlsCfg.GetClientCertificate = reloader.GetClientCertificate
+	}
+
+	return tlsCfg, nil
+}
+
+// KeyPairReloader serves a certificate loaded from files and reloads it
+// when the files change. Files are checked at most once per interval, on
+// the next handshake.
+type KeyPairReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+func NewKeyPairReloader(certFile, keyFile string, interval time.Duration) (*KeyPairReloader, error) {
+	const op = "tlsconfig.NewKeyPairReloader"
+
+	r := &KeyPairReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: interval,
+	}
+
+	if err := r.load(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return r, nil
+}
+
+func (r *KeyPairReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.current(), nil
+}
+
+func (r *KeyPairReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.current(), nil
+}
+
+// current returns the certificate, reloading it if the files have changed.
+// A failed reload keeps the previous certificate, so a half-written file
+// does not break handshakes.
+func (r *KeyPairReloader) current() *tls.Certificate {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.interval > 0 && time.Since(r.lastCheck) >= r.interval {
+		r.lastCheck = time.Now()
+
+		if modTime, err := r.latestModTime(); err == nil && modTime.After(r.modTime) {
+			_ = r.loadLocked()
+		}
+	}
+
+	return r.cert
+}
+
+func (r *KeyPairReloader) load() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastCheck = time.Now()
+
+	return r.loadLocked()
+}
+
+func (r *KeyPairReloader) loadLocked() error {
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	r.cert = &cert
+	r.modTime = modTime
+
+	return nil
+}
+
+func (r *KeyPairReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+
+	for _, name := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(name)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+
+	return latest, nil
+}
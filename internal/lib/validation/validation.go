@@ -0,0 +1,174 @@
+package validation
+
+import (
+	"github.com/go-playground/validator/v10"
+	"gym_app/internal/lib/i18n"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// DateLayout is the format of all dates accepted by the API.
+const DateLayout = "02-01-2006"
+
+// Codes of checks which are not validator tags.
+const (
+	CodeDateOrder = "date_order"
+)
+
+// FieldError describes a single invalid field. Field is the JSON name of the
+// field, Code is the failed check (the validator tag, e.g. "required").
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+
+	// scope and param are kept to translate the message later
+	scope string
+	param string
+}
+
+// Errors is a list of invalid fields. A nil Errors means the value is valid.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	fields := make([]string, 0, len(e))
+	for _, f := range e {
+		fields = append(fields, f.Field+": "+f.Code)
+	}
+
+	return "validation failed: " + strings.Join(fields, ", ")
+}
+
+// Localize returns the errors with messages in the given language.
+func (e Errors) Localize(lang string) Errors {
+	localized := make(Errors, len(e))
+	for i, f := range e {
+		f.Message = i18n.FieldMessage(lang, f.scope, f.Field, f.Code, f.param)
+		localized[i] = f
+	}
+
+	return localized
+}
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" || name == "" {
+			return f.Name
+		}
+
+		return name
+	})
+
+	_ = v.RegisterValidation("date", func(fl validator.FieldLevel) bool {
+		_, err := time.Parse(DateLayout, fl.Field().String())
+		return err == nil
+	})
+
+	_ = v.RegisterValidation("phone", func(fl validator.FieldLevel) bool {
+		phone := fl.Field().String()
+		if len(phone) != 11 || phone[0] != '7' {
+			return false
+		}
+
+		for _, r := range phone {
+			if !unicode.IsDigit(r) {
+				return false
+			}
+		}
+
+		return true
+	})
+
+	return v
+}
+
+// Struct validates the struct tags of v. The scope names the kind of value
+// (e.g. "person") and lets the catalog give messages specific to it.
+func Struct(v any, scope string) Errors {
+	err := validate.Struct(v)
+	if err == nil {
+		return nil
+	}
+
+	validationErrs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return Errors{NewFieldError(scope, "", "invalid", "")}
+	}
+
+	errs := make(Errors, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		errs = append(errs, NewFieldError(scope, fe.Field(), fe.Tag(), fe.Param()))
+	}
+
+	return errs
+}
+
+// NewFieldError builds an error for a check made outside of struct tags.
+func NewFieldError(scope, field, code, param string) FieldError {
+	return FieldError{
+		Field:   field,
+		Code:    code,
+		Message: i18n.FieldMessage(i18n.Default, scope, field, code, param),
+		scope:   scope,
+		param:   param,
+	}
+}
+
+// DateRange checks that end is not before start. Both dates are expected to
+// be already validated; empty or malformed dates are skipped.
+func DateRange(scope, start, end, endField string) Errors {
+	startDate, err := time.Parse(DateLayout, start)
+	if err != nil {
+		return nil
+	}
+
+	endDate, err := time.Parse(DateLayout, end)
+	if err != nil {
+		return nil
+	}
+
+	if endDate.Before(startDate) {
+		return Errors{NewFieldError(scope, endField, CodeDateOrder, "")}
+	}
+
+	return nil
+}
+
+// Merge joins several results into one, keeping nil for valid values.
+func Merge(results ...Errors) Errors {
+	var errs Errors
+	for _, r := range results {
+		errs = append(errs, r...)
+	}
+
+	return errs
+}
+
+// NormalizePhone strips formatting from a Russian phone number and brings it
+// to the 7XXXXXXXXXX form: "+7 (912) 345-67-89", "8 912 345 67 89" and
+// "9123456789" all become "79123456789". Anything else is returned as digits
+// only and fails the "phone" check.
+func NormalizePhone(phone string) string {
+	digits := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, phone)
+
+	switch {
+	case len(digits) == 10:
+		return "7" + digits
+	case len(digits) == 11 && digits[0] == '8':
+		return "7" + digits[1:]
+	default:
+		return digits
+	}
+}
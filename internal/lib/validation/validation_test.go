@@ -0,0 +1,64 @@
+package validation
+
+import "testing"
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		phone string
+		want  string
+	}{
+		{"79123456789", "79123456789"},
+		{"+7 (912) 345-67-89", "79123456789"},
+		{"8 912 345 67 89", "79123456789"},
+		{"89123456789", "79123456789"},
+		{"9123456789", "79123456789"},
+		{"912-345-67-89", "79123456789"},
+		// Остальное остается цифрами и не проходит проверку phone
+		{"", ""},
+		{"12345", "12345"},
+		{"+1 212 555 0100", "12125550100"},
+		{"+375 29 123 45 67", "375291234567"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizePhone(tt.phone); got != tt.want {
+			t.Errorf("NormalizePhone(%q) = %q, want %q", tt.phone, got, tt.want)
+		}
+	}
+}
+
+func TestDateRange(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end string
+		wantErr    bool
+	}{
+		{"end after start", "01-01-2025", "31-01-2025", false},
+		{"same day", "15-03-2025", "15-03-2025", false},
+		{"end before start", "31-01-2025", "01-01-2025", true},
+		{"end in previous year", "01-01-2025", "31-12-2024", true},
+		{"empty start", "", "01-01-2025", false},
+		{"empty end", "01-01-2025", "", false},
+		{"malformed date", "2025-01-31", "01-01-2025", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := DateRange("person_sub", tt.start, tt.end, "end_date")
+
+			if !tt.wantErr {
+				if errs != nil {
+					t.Fatalf("DateRange(%q, %q) = %v, want nil", tt.start, tt.end, errs)
+				}
+				return
+			}
+
+			if len(errs) != 1 {
+				t.Fatalf("DateRange(%q, %q) = %v, want one error", tt.start, tt.end, errs)
+			}
+			if errs[0].Field != "end_date" || errs[0].Code != CodeDateOrder {
+				t.Errorf("DateRange(%q, %q) = %s: %s, want end_date: %s", tt.start, tt.end, errs[0].Field, errs[0].Code, CodeDateOrder)
+			}
+		})
+	}
+}
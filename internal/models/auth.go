@@ -1,8 +1,10 @@
 package models
 
+import "gym_app/internal/lib/validation"
+
 type LoginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
 }
 
 type LoginResponse struct {
@@ -10,6 +12,14 @@ type LoginResponse struct {
 }
 
 type RegisterRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+func (r *LoginRequest) Validate() validation.Errors {
+	return validation.Struct(r, "auth")
+}
+
+func (r *RegisterRequest) Validate() validation.Errors {
+	return validation.Struct(r, "auth")
 }
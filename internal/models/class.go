@@ -0,0 +1,61 @@
+package models
+
+import (
+	"gym_app/internal/lib/validation"
+	"time"
+)
+
+// ClassType представляет вид группового занятия
+type ClassType struct {
+	ID              int64  `json:"id,omitempty"`
+	Title           string `json:"title" validate:"required,max=100"`                  // Название занятия
+	Description     string `json:"description,omitempty"`                              // Описание
+	DurationMinutes int    `json:"duration_minutes" validate:"required,min=1,max=600"` // Длительность в минутах
+	GymID           int64  `json:"gym_id,omitempty"`
+}
+
+// ClassSchedule представляет занятие в еженедельном расписании
+type ClassSchedule struct {
+	ID          int64  `json:"id,omitempty"`
+	ClassTypeID int64  `json:"class_type_id" validate:"required"`
+	TrainerID   int64  `json:"trainer_id" validate:"required"`
+	Weekday     int    `json:"weekday" validate:"required,min=1,max=7"`       // 1 - понедельник, 7 - воскресенье
+	StartTime   string `json:"start_time" validate:"required,datetime=15:04"` // Время начала (чч:мм)
+	Capacity    int    `json:"capacity" validate:"required,min=1"`            // Максимум участников
+	GymID       int64  `json:"gym_id,omitempty"`
+}
+
+// ClassOccurrence представляет конкретное занятие в расписании на дату
+type ClassOccurrence struct {
+	ScheduleID  int64  `json:"schedule_id"`
+	Date        string `json:"date"`       // Дата занятия (дд-мм-гггг)
+	StartTime   string `json:"start_time"` // Время начала (чч:мм)
+	ClassTypeID int64  `json:"class_type_id"`
+	Title       string `json:"title"`
+	TrainerID   int64  `json:"trainer_id"`
+	TrainerName string `json:"trainer_name"`
+	Capacity    int    `json:"capacity"`
+	Booked      int    `json:"booked"` // Количество записавшихся
+}
+
+// ClassBooking представляет запись клиента на занятие
+type ClassBooking struct {
+	ID         int64     `json:"id,omitempty"`
+	ScheduleID int64     `json:"schedule_id" validate:"required"`
+	ClassDate  string    `json:"class_date" validate:"required,date"` // Дата занятия (дд-мм-гггг)
+	PersonID   int64     `json:"person_id" validate:"required"`
+	Status     string    `json:"status,omitempty"` // booked / cancelled / attended
+	CreatedAt  time.Time `json:"created_at,omitempty"`
+}
+
+func (c *ClassType) Validate() validation.Errors {
+	return validation.Struct(c, "class")
+}
+
+func (c *ClassSchedule) Validate() validation.Errors {
+	return validation.Struct(c, "class")
+}
+
+func (c *ClassBooking) Validate() validation.Errors {
+	return validation.Struct(c, "class")
+}
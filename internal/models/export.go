@@ -0,0 +1,36 @@
+package models
+
+// ExportFilter повторяет фильтры списков. Пустые поля не ограничивают выгрузку
+type ExportFilter struct {
+	Name     string // ФИО клиента, как в /people/find и /person_sub/find
+	PersonID int64  // ID клиента, как в /training/person_packages
+}
+
+// PersonSubExport — строка выгрузки абонементов вместе с клиентом и тарифом
+type PersonSubExport struct {
+	Number         string  `db:"number"`
+	PersonID       int64   `db:"person_id"`
+	FullName       string  `db:"full_name"`
+	Phone          string  `db:"phone"`
+	SubscriptionID int64   `db:"subscription_id"`
+	PlanTitle      string  `db:"plan_title"`
+	Price          float64 `db:"price"`
+	StartDate      string  `db:"start_date"` // дд-мм-гггг
+	EndDate        string  `db:"end_date"`   // дд-мм-гггг
+	Status         string  `db:"status"`
+}
+
+// TrainingSaleExport — строка выгрузки проданных пакетов персональных тренировок
+type TrainingSaleExport struct {
+	ID            int64   `db:"id"`
+	PersonID      int64   `db:"person_id"`
+	FullName      string  `db:"full_name"`
+	Phone         string  `db:"phone"`
+	PackageTitle  string  `db:"package_title"`
+	TrainerName   string  `db:"trainer_name"`
+	Price         float64 `db:"price"`
+	SessionsTotal int     `db:"sessions_total"`
+	SessionsUsed  int     `db:"sessions_used"`
+	StartDate     string  `db:"start_date"`  // дд-мм-гггг
+	ExpiryDate    string  `db:"expiry_date"` // дд-мм-гггг
+}
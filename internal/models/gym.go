@@ -0,0 +1,14 @@
+package models
+
+import "gym_app/internal/lib/validation"
+
+// Gym представляет зал (филиал) сети
+type Gym struct {
+	ID      int64  `json:"id,omitempty"`
+	Name    string `json:"name" validate:"required,max=100"`     // Название зала
+	Address string `json:"address,omitempty" validate:"max=200"` // Адрес зала
+}
+
+func (g *Gym) Validate() validation.Errors {
+	return validation.Struct(g, "gym")
+}
@@ -0,0 +1,65 @@
+package models
+
+import "gym_app/internal/lib/validation"
+
+// Поля строки импорта. Ключи совпадают с JSON-полями клиента и абонемента
+const (
+	ImportName           = "name"
+	ImportPhone          = "phone"
+	ImportSubscriptionID = "subscription_id"
+	ImportNumber         = "number"
+	ImportStartDate      = "start_date"
+	ImportEndDate        = "end_date"
+)
+
+// ImportFields перечисляет поля в порядке колонок шаблона импорта
+var ImportFields = []string{
+	ImportName, ImportPhone, ImportSubscriptionID, ImportNumber, ImportStartDate, ImportEndDate,
+}
+
+// Статусы строки импорта
+const (
+	ImportCreated   = "created"   // клиент добавлен (при dry_run — будет добавлен)
+	ImportExisting  = "existing"  // клиент уже есть в зале
+	ImportDuplicate = "duplicate" // клиент повторяет одну из предыдущих строк файла
+	ImportInvalid   = "invalid"   // строка не прошла проверку
+	ImportFailed    = "failed"    // ошибка при сохранении
+)
+
+// ImportMapping сопоставляет полю импорта заголовок колонки файла.
+// Поля без сопоставления ищутся по колонке с таким же названием, как поле
+type ImportMapping map[string]string
+
+// ImportOptions задает параметры импорта
+type ImportOptions struct {
+	Mapping ImportMapping `json:"mapping,omitempty"` // Поле импорта → заголовок колонки
+	DryRun  bool          `json:"dry_run"`           // Только проверить файл, ничего не сохраняя
+}
+
+// ImportRow — результат обработки строки файла
+type ImportRow struct {
+	Row      int               `json:"row"`                 // Номер строки в файле, заголовок — строка 1
+	Status   string            `json:"status"`              // created, existing, duplicate, invalid, failed
+	PersonID int               `json:"person_id,omitempty"` // ID добавленного или найденного клиента
+	Number   string            `json:"number,omitempty"`    // Номер оформленного абонемента
+	Errors   validation.Errors `json:"errors,omitempty"`    // Ошибки в полях строки
+	Values   []string          `json:"-"`                   // Исходные ячейки строки для отчета об ошибках
+}
+
+// Failed сообщает, что строку нужно исправить и загрузить снова
+func (r ImportRow) Failed() bool {
+	return r.Status == ImportInvalid || r.Status == ImportFailed
+}
+
+// ImportResult — итог импорта
+type ImportResult struct {
+	DryRun      bool        `json:"dry_run"`
+	Total       int         `json:"total"`       // Строк с данными
+	Created     int         `json:"created"`     // Добавлено клиентов
+	Existing    int         `json:"existing"`    // Клиентов, уже бывших в зале
+	Duplicates  int         `json:"duplicates"`  // Повторов внутри файла
+	Memberships int         `json:"memberships"` // Оформлено абонементов
+	Failed      int         `json:"failed"`      // Строк с ошибками
+	Rows        []ImportRow `json:"rows"`
+	Header      []string    `json:"-"` // Заголовок файла для отчета об ошибках
+}
@@ -1,52 +1,45 @@
 package models
 
-import (
-	"github.com/go-playground/validator/v10"
-)
+import "gym_app/internal/lib/validation"
 
 type Person struct {
 	Id    int    `json:"id,omitempty"`
 	Name  string `json:"name,omitempty" db:"full_name" validate:"required,min=2,max=50"`
-	Phone string `json:"phone,omitempty" validate:"required,len=11,number"`
+	Phone string `json:"phone,omitempty" validate:"required,phone"` // Телефон в формате 7XXXXXXXXXX
+	GymID int64  `json:"gym_id,omitempty" db:"gym_id"`
 	//Memberships []Subscription `json:"memberships,omitempty" required:"false"`
 }
 
-func (p *Person) Validate() map[string]string {
-	validate := validator.New()
-
-	err := validate.Struct(p)
-	if err == nil {
-		return nil
-	}
-
-	errs := make(map[string]string)
-
-	for _, err := range err.(validator.ValidationErrors) {
-		var msg string
-
-		switch err.Field() {
-		case "Name":
-			if err.Tag() == "required" {
-				msg = "ФИО обязательно для заполнения"
-			} else if err.Tag() == "min" {
-				msg = "ФИО должно содержать не менее 2 символов"
-			} else if err.Tag() == "max" {
-				msg = "ФИО должно содержать не более 50 символов"
-			}
-		case "Phone":
-			if err.Tag() == "required" {
-				msg = "Телефон обязателен для заполнения"
-			} else if err.Tag() == "len" {
-				msg = "Телефон должен содержать 11 цифр"
-			} else if err.Tag() == "number" {
-				msg = "Телефон должен содержать только цифры"
-			}
-		default:
-			msg = "Некорректное значение поля" + err.Field()
-		}
-
-		errs[err.Field()] = msg
-	}
-
-	return errs
+// Validate приводит телефон к формату 7XXXXXXXXXX и проверяет поля
+func (p *Person) Validate() validation.Errors {
+	p.Phone = validation.NormalizePhone(p.Phone)
+
+	return validation.Struct(p, "person")
+}
+
+// DuplicateGroup — клиенты с одним телефоном и похожими ФИО, вероятно один человек
+type DuplicateGroup struct {
+	Phone      string   `json:"phone"`      // Телефон в формате 7XXXXXXXXXX
+	Similarity float64  `json:"similarity"` // Наименьшая схожесть ФИО в группе, от 0 до 1
+	People     []Person `json:"people"`
+}
+
+// PersonMerge — запрос на слияние клиента-дубля с основным клиентом
+type PersonMerge struct {
+	FromID int `json:"from_id" validate:"required"`              // Дубль, удаляется после слияния
+	ToID   int `json:"to_id" validate:"required,nefield=FromID"` // Клиент, к которому переходят записи
+}
+
+// Validate проверяет поля запроса
+func (m *PersonMerge) Validate() validation.Errors {
+	return validation.Struct(m, "merge")
+}
+
+// PersonMergeResult — сколько записей перешло к основному клиенту
+type PersonMergeResult struct {
+	PersonID         int `json:"person_id"`         // Оставшийся клиент
+	Memberships      int `json:"memberships"`       // Абонементы
+	Bookings         int `json:"bookings"`          // Записи на занятия и посещения
+	SkippedBookings  int `json:"skipped_bookings"`  // Записи на занятия, которые уже были у основного клиента
+	TrainingPackages int `json:"training_packages"` // Проданные пакеты персональных тренировок
 }
@@ -1,7 +1,7 @@
 package models
 
 import (
-	"github.com/go-playground/validator/v10"
+	"gym_app/internal/lib/validation"
 	"time"
 )
 
@@ -13,49 +13,87 @@ type PersonSubscription struct {
 	StartDate      time.Time `json:"start_date,omitempty"`
 	EndDate        time.Time `json:"end_date,omitempty"`
 	Status         string    `json:"status,omitempty"`
+	GymID          int64     `json:"gym_id,omitempty"`
+}
+
+// PersonSubStatusCount is the number of memberships of a gym in a status
+type PersonSubStatusCount struct {
+	GymID  int64  `json:"gym_id"`
+	Status string `json:"status"`
+	Count  int64  `json:"count"`
 }
 
 type PersonSubStrDate struct {
-	Number         string `json:"number" validate:"required"`          // Номер абонемента
-	PersonID       int64  `json:"person_id" validate:"required"`       // ID клиента
-	SubscriptionID int64  `json:"subscription_id" validate:"required"` // ID абонемента
-	StartDate      string `json:"start_date,omitempty"`                // Дата начала
-	EndDate        string `json:"end_date,omitempty"`                  // Дата окончания
-	Status         string `json:"status,omitempty"`                    // Статус абонемента (active/frozen/completed)
+	Number         string `json:"number" validate:"omitempty,max=32"`             // Номер абонемента (генерируется, если не указан)
+	PersonID       int64  `json:"person_id" validate:"required"`                  // ID клиента
+	SubscriptionID int64  `json:"subscription_id" validate:"required"`            // ID абонемента
+	StartDate      string `json:"start_date,omitempty" validate:"omitempty,date"` // Дата начала (дд-мм-гггг)
+	EndDate        string `json:"end_date,omitempty" validate:"omitempty,date"`   // Дата окончания (дд-мм-гггг)
+	Status         string `json:"status,omitempty"`                               // Статус абонемента (active/frozen/completed)
+	GymID          int64  `json:"gym_id,omitempty"`                               // Зал, в котором оформлен абонемент
 }
 
-func (p *PersonSubStrDate) Validate() map[string]string {
-	validate := validator.New()
-	err := validate.Struct(p)
+func (p *PersonSubStrDate) Validate() validation.Errors {
+	return validation.Merge(
+		validation.Struct(p, "person_sub"),
+		validation.DateRange("person_sub", p.StartDate, p.EndDate, "end_date"),
+	)
+}
 
-	if err == nil {
-		return nil
-	}
+// PersonSubUpdate описывает изменение абонемента клиента.
+// При частичном обновлении (PATCH) незаданные поля не меняются.
+// Новый номер карты выдается перевыпуском, старый номер попадает в историю
+type PersonSubUpdate struct {
+	Number         *string `json:"number" validate:"omitempty,min=1,max=32"`   // Номер новой карты (при утере)
+	Reason         string  `json:"reason,omitempty" validate:"max=200"`        // Причина перевыпуска карты
+	SubscriptionID *int64  `json:"subscription_id" validate:"omitempty,min=1"` // ID тарифа
+	StartDate      *string `json:"start_date" validate:"omitempty,date"`       // Дата начала (дд-мм-гггг)
+	EndDate        *string `json:"end_date" validate:"omitempty,date"`         // Дата окончания (дд-мм-гггг)
+}
 
-	errs := make(map[string]string)
+// Validate проверяет изменение. При полном обновлении (PUT) обязательны все
+// поля, кроме номера карты
+func (u *PersonSubUpdate) Validate(partial bool) validation.Errors {
+	errs := validation.Struct(u, "person_sub")
 
-	for _, err := range err.(validator.ValidationErrors) {
-		var msg string
+	if !partial {
+		required := []struct {
+			field string
+			set   bool
+		}{
+			{"subscription_id", u.SubscriptionID != nil},
+			{"start_date", u.StartDate != nil},
+			{"end_date", u.EndDate != nil},
+		}
 
-		switch err.Field() {
-		case "Number":
-			if err.Tag() == "required" {
-				msg = "Номер абонемента обязателен для заполнения"
-			}
-		case "PersonID":
-			if err.Tag() == "required" {
-				msg = "ID клиента обязателен для заполнения"
-			}
-		case "SubscriptionID":
-			if err.Tag() == "required" {
-				msg = "ID абонемента обязателен для заполнения"
+		for _, r := range required {
+			if !r.set {
+				errs = append(errs, validation.NewFieldError("person_sub", r.field, "required", ""))
 			}
-		default:
-			msg = "Некорректное значение поля" + err.Field()
 		}
+	}
 
-		errs[err.Field()] = msg
+	if u.StartDate != nil && u.EndDate != nil {
+		errs = append(errs, validation.DateRange("person_sub", *u.StartDate, *u.EndDate, "end_date")...)
 	}
 
 	return errs
 }
+
+// CardReplacement описывает перевыпуск карты абонемента (утеря, порча)
+type CardReplacement struct {
+	NewNumber string `json:"new_number" validate:"required,max=32"` // Номер новой карты
+	Reason    string `json:"reason" validate:"max=200"`             // Причина перевыпуска
+}
+
+func (r *CardReplacement) Validate() validation.Errors {
+	return validation.Struct(r, "person_sub")
+}
+
+// CardNumberChange представляет отозванный номер карты абонемента
+type CardNumberChange struct {
+	Number        string    `json:"number"`         // Отозванный номер
+	CurrentNumber string    `json:"current_number"` // Текущий номер абонемента
+	Reason        string    `json:"reason"`         // Причина перевыпуска
+	RevokedAt     time.Time `json:"revoked_at"`     // Время отзыва
+}
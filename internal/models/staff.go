@@ -0,0 +1,16 @@
+package models
+
+import "gym_app/internal/lib/validation"
+
+// Staff представляет сотрудника, связанного с пользователем SSO
+type Staff struct {
+	ID       int64  `json:"id,omitempty"`
+	UserID   int64  `json:"user_id" validate:"required"`                 // ID пользователя в SSO
+	FullName string `json:"full_name" validate:"required,min=2,max=100"` // ФИО сотрудника
+	Position string `json:"position,omitempty" validate:"max=100"`       // Должность
+	GymID    int64  `json:"gym_id" validate:"required"`                  // Зал, в котором работает сотрудник
+}
+
+func (s *Staff) Validate() validation.Errors {
+	return validation.Struct(s, "staff")
+}
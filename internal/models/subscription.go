@@ -1,10 +1,18 @@
 package models
 
+import "gym_app/internal/lib/validation"
+
 // Subscription представляет абонемент
 type Subscription struct {
-	ID           string  `json:"id,omitempty"`  // Номер абонемента
-	Title        string  `json:"title"`         // Название тарифа
-	Price        float64 `json:"price"`         // Цена тарифа
-	DurationDays int     `json:"duration_days"` // Срок действия в днях
-	FreezeDays   int     `json:"freeze_days"`   // Количество допустимых дней заморозки
+	ID           string  `json:"id,omitempty"`                            // Номер абонемента
+	Title        string  `json:"title" validate:"required,max=100"`       // Название тарифа
+	Price        float64 `json:"price" validate:"min=0"`                  // Цена тарифа
+	DurationDays int     `json:"duration_days" validate:"required,min=1"` // Срок действия в днях
+	FreezeDays   int     `json:"freeze_days" validate:"min=0"`            // Количество допустимых дней заморозки
+	GymID        int64   `json:"gym_id,omitempty"`                        // Зал, в котором действует тариф
+	AllGyms      bool    `json:"all_gyms"`                                // Тариф действует во всех филиалах
+}
+
+func (s *Subscription) Validate() validation.Errors {
+	return validation.Struct(s, "subscription")
 }
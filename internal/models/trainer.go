@@ -0,0 +1,21 @@
+package models
+
+import "gym_app/internal/lib/validation"
+
+// Trainer представляет тренера зала
+type Trainer struct {
+	ID       int64  `json:"id,omitempty"`
+	FullName string `json:"full_name" validate:"required,min=2,max=100"` // ФИО тренера
+	Phone    string `json:"phone,omitempty" validate:"omitempty,phone"`  // Телефон в формате 7XXXXXXXXXX
+	StaffID  *int64 `json:"staff_id,omitempty"`                          // Сотрудник, если тренер работает в приложении
+	GymID    int64  `json:"gym_id,omitempty"`
+}
+
+// Validate приводит телефон к формату 7XXXXXXXXXX и проверяет поля
+func (t *Trainer) Validate() validation.Errors {
+	if t.Phone != "" {
+		t.Phone = validation.NormalizePhone(t.Phone)
+	}
+
+	return validation.Struct(t, "trainer")
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"gym_app/internal/lib/validation"
+	"time"
+)
+
+// TrainingPackage представляет пакет персональных тренировок (товар)
+type TrainingPackage struct {
+	ID             int64   `json:"id,omitempty"`
+	Title          string  `json:"title" validate:"required,max=100"`         // Название пакета
+	Sessions       int     `json:"sessions" validate:"required,min=1"`        // Количество тренировок
+	SessionMinutes int     `json:"session_minutes" validate:"required,min=1"` // Длительность тренировки в минутах
+	ValidityDays   int     `json:"validity_days" validate:"required,min=1"`   // Срок действия в днях
+	Price          float64 `json:"price" validate:"min=0"`                    // Цена пакета
+	GymID          int64   `json:"gym_id,omitempty"`
+}
+
+// PersonTrainingPackage представляет пакет тренировок, проданный клиенту
+type PersonTrainingPackage struct {
+	ID            int64  `json:"id,omitempty"`
+	PersonID      int64  `json:"person_id" validate:"required"`
+	PackageID     int64  `json:"package_id" validate:"required"`
+	TrainerID     int64  `json:"trainer_id" validate:"required"`
+	SessionsTotal int    `json:"sessions_total,omitempty"`
+	SessionsUsed  int    `json:"sessions_used"`
+	StartDate     string `json:"start_date,omitempty" validate:"omitempty,date"` // Дата начала (дд-мм-гггг)
+	ExpiryDate    string `json:"expiry_date,omitempty"`                          // Действует до (дд-мм-гггг)
+	GymID         int64  `json:"gym_id,omitempty"`
+}
+
+// TrainingSession представляет персональную тренировку
+type TrainingSession struct {
+	ID              int64     `json:"id,omitempty"`
+	PersonPackageID int64     `json:"person_package_id" validate:"required"`
+	PersonID        int64     `json:"person_id,omitempty"`
+	TrainerID       int64     `json:"trainer_id,omitempty"`
+	ScheduledAt     time.Time `json:"scheduled_at" validate:"required"` // Время начала
+	DurationMinutes int       `json:"duration_minutes,omitempty"`
+	Status          string    `json:"status,omitempty"` // booked / cancelled / completed
+}
+
+func (t *TrainingPackage) Validate() validation.Errors {
+	return validation.Struct(t, "training")
+}
+
+func (p *PersonTrainingPackage) Validate() validation.Errors {
+	return validation.Struct(p, "training")
+}
+
+func (s *TrainingSession) Validate() validation.Errors {
+	return validation.Struct(s, "training")
+}
@@ -2,8 +2,12 @@ package authService
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	ssoGrpc "gym_app/internal/clients/sso/grpc"
+	"gym_app/internal/lib/grpcerrors"
 	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/requestctx"
 	"log/slog"
 )
 
@@ -11,6 +15,14 @@ type SSOClient interface {
 	Login(ctx context.Context, appId int32, email, password string) (string, error)
 	RegisterNewUser(ctx context.Context, email, password string) (int64, error)
 }
+
+var (
+	ErrInvalidRequest     = errors.New("invalid request")
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrUserExists         = errors.New("user already exists")
+	ErrSSOUnavailable     = errors.New("authentication service is unavailable")
+)
+
 type AuthService struct {
 	log       *slog.Logger
 	appId     int32
@@ -32,7 +44,7 @@ func New(
 func (a *AuthService) Login(ctx context.Context, email, password string) (string, error) {
 	const op = "services.auth.login"
 
-	log := a.log.With(
+	log := requestctx.Logger(ctx, a.log).With(
 		slog.String("op", op),
 	)
 
@@ -41,6 +53,14 @@ func (a *AuthService) Login(ctx context.Context, email, password string) (string
 	token, err := a.ssoClient.Login(ctx, a.appId, email, password)
 	if err != nil {
 		log.Error("failed to login", slog.String("email", email), sl.Error(err))
+
+		// SSO answers a wrong password with a bare InvalidArgument, without
+		// field violations. It must look the same as an unknown email
+		err = mapSSOError(err)
+		if errors.Is(err, ErrInvalidRequest) && grpcerrors.FieldErrors(err, "") == nil {
+			err = ErrInvalidCredentials
+		}
+
 		return "", fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -51,7 +71,7 @@ func (a *AuthService) Login(ctx context.Context, email, password string) (string
 func (a *AuthService) RegisterNewUser(ctx context.Context, email, password string) (int64, error) {
 	const op = "services.auth.registerNewUser"
 
-	log := a.log.With(
+	log := requestctx.Logger(ctx, a.log).With(
 		slog.String("op", op),
 	)
 
@@ -60,9 +80,26 @@ func (a *AuthService) RegisterNewUser(ctx context.Context, email, password strin
 	userId, err := a.ssoClient.RegisterNewUser(ctx, email, password)
 	if err != nil {
 		log.Error("failed to register new user", slog.String("email", email), sl.Error(err))
-		return 0, err
+		return 0, fmt.Errorf("%s: %w", op, mapSSOError(err))
 	}
 
 	log.Info("user registered successfully")
 	return userId, nil
 }
+
+// mapSSOError keeps the original error in the chain, so the handler can
+// still read the field violations of an invalid request.
+func mapSSOError(err error) error {
+	switch {
+	case errors.Is(err, ssoGrpc.ErrInvalidArgument):
+		return fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+	case errors.Is(err, ssoGrpc.ErrAlreadyExists):
+		return fmt.Errorf("%w: %w", ErrUserExists, err)
+	case errors.Is(err, ssoGrpc.ErrUnauthenticated), errors.Is(err, ssoGrpc.ErrNotFound):
+		return fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	case errors.Is(err, ssoGrpc.ErrUnavailable):
+		return fmt.Errorf("%w: %w", ErrSSOUnavailable, err)
+	default:
+		return err
+	}
+}
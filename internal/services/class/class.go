@@ -0,0 +1,370 @@
+package classService
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/requestctx"
+	"gym_app/internal/models"
+	"gym_app/internal/storage"
+	"log/slog"
+	"time"
+)
+
+const (
+	cancelledStatus = "cancelled"
+	attendedStatus  = "attended"
+
+	dateLayout = "02-01-2006"
+
+	// maxTimetableDays limits the period a timetable can be requested for
+	maxTimetableDays = 31
+)
+
+type ClassStorage interface {
+	SaveClassType(ctx context.Context, gymID int64, classType models.ClassType) (int64, error)
+	UpdateClassType(ctx context.Context, gymID int64, classType models.ClassType, classTypeID int64) error
+	DeleteClassType(ctx context.Context, gymID int64, classTypeID int64) error
+	FindAllClassTypes(ctx context.Context, gymID int64) ([]models.ClassType, error)
+
+	SaveClassSchedule(ctx context.Context, gymID int64, schedule models.ClassSchedule) (int64, error)
+	UpdateClassSchedule(ctx context.Context, gymID int64, schedule models.ClassSchedule, scheduleID int64) error
+	DeleteClassSchedule(ctx context.Context, gymID int64, scheduleID int64) error
+	FindClassSchedule(ctx context.Context, gymID int64) ([]models.ClassSchedule, error)
+	FindTimetable(ctx context.Context, gymID int64, from, to time.Time) ([]models.ClassOccurrence, error)
+
+	BookClass(ctx context.Context, gymID, scheduleID, personID int64, classDate time.Time) (int64, error)
+	SetBookingStatus(ctx context.Context, gymID, bookingID int64, status string) error
+	FindClassBookings(ctx context.Context, gymID, scheduleID int64, classDate time.Time) ([]models.ClassBooking, error)
+}
+
+var (
+	ErrClassTypeExists    = errors.New("class type already exists")
+	ErrClassTypeNotFound  = errors.New("class type not found")
+	ErrClassTypeInUse     = errors.New("class type has scheduled classes")
+	ErrScheduleNotFound   = errors.New("scheduled class, class type or trainer not found")
+	ErrInvalidDate        = errors.New("date must be in dd-mm-yyyy format")
+	ErrInvalidPeriod      = errors.New("invalid timetable period")
+	ErrClassInPast        = errors.New("class has already taken place")
+	ErrWrongClassDate     = errors.New("class is not held on that date")
+	ErrNoActiveMembership = errors.New("person has no active membership for that date")
+	ErrClassFull          = errors.New("class is full")
+	ErrAlreadyBooked      = errors.New("person is already booked for this class")
+	ErrBookingNotFound    = errors.New("active booking not found")
+)
+
+type ClassService struct {
+	log          *slog.Logger
+	classStorage ClassStorage
+}
+
+func New(log *slog.Logger, classStorage ClassStorage) *ClassService {
+	return &ClassService{
+		log:          log,
+		classStorage: classStorage,
+	}
+}
+
+func (s *ClassService) AddClassType(ctx context.Context, gymID int64, classType models.ClassType) (int64, error) {
+	const op = "services.class.AddClassType"
+
+	log := requestctx.Logger(ctx, s.log).With(
+		slog.String("op", op),
+	)
+
+	log.Info("Adding class type")
+
+	classTypeID, err := s.classStorage.SaveClassType(ctx, gymID, classType)
+	if err != nil {
+		log.Warn("failed to add class type", sl.Error(err))
+
+		return 0, fmt.Errorf("%s: %w", op, mapStorageError(err))
+	}
+
+	log.Info("class type added", slog.Int64("class_type_id", classTypeID))
+
+	return classTypeID, nil
+}
+
+func (s *ClassService) UpdateClassType(ctx context.Context, gymID int64, classType models.ClassType, classTypeID int64) error {
+	const op = "services.class.UpdateClassType"
+
+	log := requestctx.Logger(ctx, s.log).With(
+		slog.String("op", op),
+		slog.Int64("class_type_id", classTypeID),
+	)
+
+	log.Info("Updating class type")
+
+	if err := s.classStorage.UpdateClassType(ctx, gymID, classType, classTypeID); err != nil {
+		log.Warn("failed to update class type", sl.Error(err))
+
+		return fmt.Errorf("%s: %w", op, mapStorageError(err))
+	}
+
+	log.Info("class type updated")
+
+	return nil
+}
+
+func (s *ClassService) DeleteClassType(ctx context.Context, gymID int64, classTypeID int64) error {
+	const op = "services.class.DeleteClassType"
+
+	log := requestctx.Logger(ctx, s.log).With(
+		slog.String("op", op),
+		slog.Int64("class_type_id", classTypeID),
+	)
+
+	log.Info("Deleting class type")
+
+	if err := s.classStorage.DeleteClassType(ctx, gymID, classTypeID); err != nil {
+		log.Warn("failed to delete class type", sl.Error(err))
+
+		return fmt.Errorf("%s: %w", op, mapStorageError(err))
+	}
+
+	log.Info("class type deleted")
+
+	return nil
+}
+
+func (s *ClassService) FindAllClassTypes(ctx context.Context, gymID int64) ([]models.ClassType, error) {
+	const op = "services.class.FindAllClassTypes"
+
+	classTypes, err := s.classStorage.FindAllClassTypes(ctx, gymID)
+	if err != nil {
+		s.log.Error("failed to get class types", slog.String("op", op), sl.Error(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return classTypes, nil
+}
+
+func (s *ClassService) AddClassSchedule(ctx context.Context, gymID int64, schedule models.ClassSchedule) (int64, error) {
+	const op = "services.class.AddClassSchedule"
+
+	log := requestctx.Logger(ctx, s.log).With(
+		slog.String("op", op),
+	)
+
+	log.Info("Adding class to schedule")
+
+	scheduleID, err := s.classStorage.SaveClassSchedule(ctx, gymID, schedule)
+	if err != nil {
+		log.Warn("failed to add class to schedule", sl.Error(err))
+
+		if errors.Is(err, storage.ErrClassTypeNotFound) {
+			return 0, fmt.Errorf("%s: %w", op, ErrScheduleNotFound)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("class added to schedule", slog.Int64("schedule_id", scheduleID))
+
+	return scheduleID, nil
+}
+
+func (s *ClassService) UpdateClassSchedule(ctx context.Context, gymID int64, schedule models.ClassSchedule, scheduleID int64) error {
+	const op = "services.class.UpdateClassSchedule"
+
+	log := requestctx.Logger(ctx, s.log).With(
+		slog.String("op", op),
+		slog.Int64("schedule_id", scheduleID),
+	)
+
+	log.Info("Updating scheduled class")
+
+	if err := s.classStorage.UpdateClassSchedule(ctx, gymID, schedule, scheduleID); err != nil {
+		log.Warn("failed to update scheduled class", sl.Error(err))
+
+		return fmt.Errorf("%s: %w", op, mapStorageError(err))
+	}
+
+	log.Info("scheduled class updated")
+
+	return nil
+}
+
+func (s *ClassService) DeleteClassSchedule(ctx context.Context, gymID int64, scheduleID int64) error {
+	const op = "services.class.DeleteClassSchedule"
+
+	log := requestctx.Logger(ctx, s.log).With(
+		slog.String("op", op),
+		slog.Int64("schedule_id", scheduleID),
+	)
+
+	log.Info("Deleting scheduled class")
+
+	if err := s.classStorage.DeleteClassSchedule(ctx, gymID, scheduleID); err != nil {
+		log.Warn("failed to delete scheduled class", sl.Error(err))
+
+		return fmt.Errorf("%s: %w", op, mapStorageError(err))
+	}
+
+	log.Info("scheduled class deleted")
+
+	return nil
+}
+
+func (s *ClassService) FindClassSchedule(ctx context.Context, gymID int64) ([]models.ClassSchedule, error) {
+	const op = "services.class.FindClassSchedule"
+
+	schedule, err := s.classStorage.FindClassSchedule(ctx, gymID)
+	if err != nil {
+		s.log.Error("failed to get class schedule", slog.String("op", op), sl.Error(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return schedule, nil
+}
+
+// FindTimetable returns classes between from and to (dd-mm-yyyy, inclusive).
+// Empty dates default to the coming week.
+func (s *ClassService) FindTimetable(ctx context.Context, gymID int64, fromStr, toStr string) ([]models.ClassOccurrence, error) {
+	const op = "services.class.FindTimetable"
+
+	log := requestctx.Logger(ctx, s.log).With(
+		slog.String("op", op),
+	)
+
+	today := time.Now().Truncate(24 * time.Hour)
+
+	from, to := today, today.AddDate(0, 0, 6)
+
+	if fromStr != "" {
+		d, err := time.Parse(dateLayout, fromStr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, ErrInvalidDate)
+		}
+		from = d
+	}
+
+	if toStr != "" {
+		d, err := time.Parse(dateLayout, toStr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, ErrInvalidDate)
+		}
+		to = d
+	}
+
+	if to.Before(from) || to.Sub(from) > maxTimetableDays*24*time.Hour {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidPeriod)
+	}
+
+	timetable, err := s.classStorage.FindTimetable(ctx, gymID, from, to)
+	if err != nil {
+		log.Error("failed to get timetable", sl.Error(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return timetable, nil
+}
+
+func (s *ClassService) BookClass(ctx context.Context, gymID int64, booking models.ClassBooking) (int64, error) {
+	const op = "services.class.BookClass"
+
+	log := requestctx.Logger(ctx, s.log).With(
+		slog.String("op", op),
+		slog.Int64("schedule_id", booking.ScheduleID),
+		slog.Int64("person_id", booking.PersonID),
+		slog.String("class_date", booking.ClassDate),
+	)
+
+	log.Info("Booking class")
+
+	classDate, err := time.Parse(dateLayout, booking.ClassDate)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, ErrInvalidDate)
+	}
+
+	if classDate.Before(time.Now().Truncate(24 * time.Hour)) {
+		return 0, fmt.Errorf("%s: %w", op, ErrClassInPast)
+	}
+
+	bookingID, err := s.classStorage.BookClass(ctx, gymID, booking.ScheduleID, booking.PersonID, classDate)
+	if err != nil {
+		log.Warn("failed to book class", sl.Error(err))
+
+		return 0, fmt.Errorf("%s: %w", op, mapStorageError(err))
+	}
+
+	log.Info("class booked", slog.Int64("booking_id", bookingID))
+
+	return bookingID, nil
+}
+
+func (s *ClassService) CancelBooking(ctx context.Context, gymID, bookingID int64) error {
+	return s.setBookingStatus(ctx, "services.class.CancelBooking", gymID, bookingID, cancelledStatus)
+}
+
+func (s *ClassService) MarkAttendance(ctx context.Context, gymID, bookingID int64) error {
+	return s.setBookingStatus(ctx, "services.class.MarkAttendance", gymID, bookingID, attendedStatus)
+}
+
+func (s *ClassService) setBookingStatus(ctx context.Context, op string, gymID, bookingID int64, status string) error {
+	log := requestctx.Logger(ctx, s.log).With(
+		slog.String("op", op),
+		slog.Int64("booking_id", bookingID),
+		slog.String("status", status),
+	)
+
+	log.Info("Changing booking status")
+
+	if err := s.classStorage.SetBookingStatus(ctx, gymID, bookingID, status); err != nil {
+		log.Warn("failed to change booking status", sl.Error(err))
+
+		return fmt.Errorf("%s: %w", op, mapStorageError(err))
+	}
+
+	log.Info("booking status changed")
+
+	return nil
+}
+
+func (s *ClassService) FindClassBookings(ctx context.Context, gymID, scheduleID int64, classDateStr string) ([]models.ClassBooking, error) {
+	const op = "services.class.FindClassBookings"
+
+	classDate, err := time.Parse(dateLayout, classDateStr)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvalidDate)
+	}
+
+	bookings, err := s.classStorage.FindClassBookings(ctx, gymID, scheduleID, classDate)
+	if err != nil {
+		s.log.Error("failed to get class bookings", slog.String("op", op), sl.Error(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return bookings, nil
+}
+
+func mapStorageError(err error) error {
+	switch {
+	case errors.Is(err, storage.ErrClassTypeExists):
+		return ErrClassTypeExists
+	case errors.Is(err, storage.ErrClassTypeNotFound):
+		return ErrClassTypeNotFound
+	case errors.Is(err, storage.ErrClassTypeInUse):
+		return ErrClassTypeInUse
+	case errors.Is(err, storage.ErrScheduleNotFound):
+		return ErrScheduleNotFound
+	case errors.Is(err, storage.ErrWrongClassDate):
+		return ErrWrongClassDate
+	case errors.Is(err, storage.ErrNoActiveMembership):
+		return ErrNoActiveMembership
+	case errors.Is(err, storage.ErrClassFull):
+		return ErrClassFull
+	case errors.Is(err, storage.ErrAlreadyBooked):
+		return ErrAlreadyBooked
+	case errors.Is(err, storage.ErrBookingNotFound):
+		return ErrBookingNotFound
+	default:
+		return err
+	}
+}
@@ -0,0 +1,130 @@
+package exportService
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gym_app/internal/lib/i18n"
+	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/requestctx"
+	"gym_app/internal/lib/spreadsheet"
+	"gym_app/internal/models"
+	"io"
+	"log/slog"
+)
+
+type ExportStorage interface {
+	ExportPeople(ctx context.Context, gymID int64, filter models.ExportFilter, fn func(models.Person) error) error
+	ExportPersonSubs(ctx context.Context, gymID int64, filter models.ExportFilter, fn func(models.PersonSubExport) error) error
+	ExportTrainingSales(ctx context.Context, gymID int64, filter models.ExportFilter, fn func(models.TrainingSaleExport) error) error
+}
+
+// Exported tables
+const (
+	TablePeople        = "people"
+	TableMemberships   = "memberships"
+	TableTrainingSales = "training_sales"
+)
+
+var ErrUnknownTable = errors.New("unknown export table")
+
+type ExportService struct {
+	log           *slog.Logger
+	exportStorage ExportStorage
+}
+
+func New(
+	log *slog.Logger,
+	exportStorage ExportStorage,
+) *ExportService {
+	return &ExportService{
+		log:           log,
+		exportStorage: exportStorage,
+	}
+}
+
+// Export writes a table of the gym to w as CSV or XLSX, with column titles
+// in the given language. Rows go from the database to w one at a time.
+func (e *ExportService) Export(
+	ctx context.Context,
+	gymID int64,
+	table, format, lang string,
+	filter models.ExportFilter,
+	w io.Writer,
+) error {
+	const op = "services.export.Export"
+
+	log := requestctx.Logger(ctx, e.log).With(
+		slog.String("op", op),
+		slog.String("table", table),
+		slog.String("format", format),
+	)
+
+	log.Info("Exporting table")
+
+	sw, err := spreadsheet.NewWriter(w, format)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer sw.Close()
+
+	header := func(titles ...string) error {
+		cells := make([]any, len(titles))
+		for i, title := range titles {
+			cells[i] = i18n.T(lang, title)
+		}
+
+		return sw.Write(cells...)
+	}
+
+	rows := 0
+
+	switch table {
+	case TablePeople:
+		if err = header("ID", "Full name", "Phone"); err != nil {
+			break
+		}
+
+		err = e.exportStorage.ExportPeople(ctx, gymID, filter, func(p models.Person) error {
+			rows++
+			return sw.Write(p.Id, p.Name, p.Phone)
+		})
+	case TableMemberships:
+		if err = header("Card number", "Person ID", "Full name", "Phone", "Plan ID", "Plan",
+			"Price", "Start date", "End date", "Status"); err != nil {
+			break
+		}
+
+		err = e.exportStorage.ExportPersonSubs(ctx, gymID, filter, func(ps models.PersonSubExport) error {
+			rows++
+			return sw.Write(ps.Number, ps.PersonID, ps.FullName, ps.Phone, ps.SubscriptionID, ps.PlanTitle,
+				ps.Price, ps.StartDate, ps.EndDate, ps.Status)
+		})
+	case TableTrainingSales:
+		if err = header("ID", "Person ID", "Full name", "Phone", "Package", "Trainer",
+			"Price", "Sessions", "Sessions used", "Start date", "Expiry date"); err != nil {
+			break
+		}
+
+		err = e.exportStorage.ExportTrainingSales(ctx, gymID, filter, func(ts models.TrainingSaleExport) error {
+			rows++
+			return sw.Write(ts.ID, ts.PersonID, ts.FullName, ts.Phone, ts.PackageTitle, ts.TrainerName,
+				ts.Price, ts.SessionsTotal, ts.SessionsUsed, ts.StartDate, ts.ExpiryDate)
+		})
+	default:
+		return fmt.Errorf("%s: %w: %q", op, ErrUnknownTable, table)
+	}
+	if err != nil {
+		log.Error("failed to export table", sl.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := sw.Flush(); err != nil {
+		log.Error("failed to write export", sl.Error(err))
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("table exported", slog.Int("rows", rows))
+
+	return nil
+}
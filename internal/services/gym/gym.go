@@ -0,0 +1,143 @@
+package gymService
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/requestctx"
+	"gym_app/internal/models"
+	"gym_app/internal/storage"
+	"log/slog"
+)
+
+type GymStorage interface {
+	SaveGym(ctx context.Context, gym models.Gym) (int64, error)
+	UpdateGym(ctx context.Context, gym models.Gym, gymID int64) error
+	DeleteGym(ctx context.Context, gymID int64) error
+	FindAllGyms(ctx context.Context) ([]models.Gym, error)
+}
+
+var (
+	ErrGymExists   = errors.New("gym already exists")
+	ErrGymNotFound = errors.New("gym not found")
+	ErrGymInUse    = errors.New("gym has clients, tariffs, memberships or staff")
+)
+
+type GymService struct {
+	log        *slog.Logger
+	gymStorage GymStorage
+}
+
+func New(log *slog.Logger, gymStorage GymStorage) *GymService {
+	return &GymService{
+		log:        log,
+		gymStorage: gymStorage,
+	}
+}
+
+func (g *GymService) AddGym(ctx context.Context, gym models.Gym) (int64, error) {
+	const op = "services.gym.AddGym"
+
+	log := requestctx.Logger(ctx, g.log).With(
+		slog.String("op", op),
+	)
+
+	log.Info("Adding new gym")
+
+	gymID, err := g.gymStorage.SaveGym(ctx, gym)
+	if err != nil {
+		if errors.Is(err, storage.ErrGymExists) {
+			log.Warn("gym already exists", sl.Error(err))
+
+			return 0, fmt.Errorf("%s: %w", op, ErrGymExists)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("gym added", slog.Int64("gym_id", gymID))
+
+	return gymID, nil
+}
+
+func (g *GymService) UpdateGym(ctx context.Context, gym models.Gym, gymID int64) error {
+	const op = "services.gym.UpdateGym"
+
+	log := requestctx.Logger(ctx, g.log).With(
+		slog.String("op", op),
+		slog.Int64("gym_id", gymID),
+	)
+
+	log.Info("Updating gym")
+
+	if err := g.gymStorage.UpdateGym(ctx, gym, gymID); err != nil {
+		if errors.Is(err, storage.ErrGymNotFound) {
+			log.Warn("gym not found", sl.Error(err))
+
+			return fmt.Errorf("%s: %w", op, ErrGymNotFound)
+		}
+
+		if errors.Is(err, storage.ErrGymExists) {
+			log.Warn("gym already exists", sl.Error(err))
+
+			return fmt.Errorf("%s: %w", op, ErrGymExists)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("gym updated")
+
+	return nil
+}
+
+func (g *GymService) DeleteGym(ctx context.Context, gymID int64) error {
+	const op = "services.gym.DeleteGym"
+
+	log := requestctx.Logger(ctx, g.log).With(
+		slog.String("op", op),
+		slog.Int64("gym_id", gymID),
+	)
+
+	log.Info("Deleting gym")
+
+	if err := g.gymStorage.DeleteGym(ctx, gymID); err != nil {
+		if errors.Is(err, storage.ErrGymNotFound) {
+			log.Warn("gym not found", sl.Error(err))
+
+			return fmt.Errorf("%s: %w", op, ErrGymNotFound)
+		}
+
+		if errors.Is(err, storage.ErrGymInUse) {
+			log.Warn("gym is in use", sl.Error(err))
+
+			return fmt.Errorf("%s: %w", op, ErrGymInUse)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("gym deleted")
+
+	return nil
+}
+
+func (g *GymService) FindAllGyms(ctx context.Context) ([]models.Gym, error) {
+	const op = "services.gym.FindAllGyms"
+
+	log := requestctx.Logger(ctx, g.log).With(
+		slog.String("op", op),
+	)
+
+	gyms, err := g.gymStorage.FindAllGyms(ctx)
+	if err != nil {
+		log.Error("failed to get gyms", sl.Error(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("gyms found")
+
+	return gyms, nil
+}
@@ -0,0 +1,466 @@
+package importService
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gym_app/internal/lib/i18n"
+	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/requestctx"
+	"gym_app/internal/lib/spreadsheet"
+	"gym_app/internal/lib/validation"
+	"gym_app/internal/models"
+	personService "gym_app/internal/services/person"
+	personSubService "gym_app/internal/services/person_sub"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// The import goes through the services rather than the storage, so that
+// rows get the same checks, card numbers and end dates as the API requests.
+
+type PersonService interface {
+	AddPerson(ctx context.Context, gymID int64, person models.Person) (int, error)
+	FindAllPeople(ctx context.Context, gymID int64) ([]models.Person, error)
+}
+
+type SubscriptionService interface {
+	FindAllSubscriptions(ctx context.Context, gymID int64) ([]models.Subscription, error)
+}
+
+type PersonSubService interface {
+	AddPersonSub(ctx context.Context, gymID int64, personSub models.PersonSubStrDate) (string, error)
+}
+
+var (
+	ErrEmptyFile      = errors.New("file has no header row")
+	ErrInvalidMapping = errors.New("invalid column mapping")
+)
+
+// Validation scopes of the import errors
+const (
+	mappingScope = "import_mapping"
+	rowScope     = "import"
+)
+
+// Codes of the row checks made by the import itself
+const (
+	codeInteger  = "integer"
+	codeNotFound = "not_found"
+	codeExists   = "exists"
+	codeColumn   = "column"
+	codeUnknown  = "unknown_field"
+)
+
+type ImportService struct {
+	log                 *slog.Logger
+	personService       PersonService
+	subscriptionService SubscriptionService
+	personSubService    PersonSubService
+}
+
+func New(
+	log *slog.Logger,
+	personService PersonService,
+	subscriptionService SubscriptionService,
+	personSubService PersonSubService,
+) *ImportService {
+	return &ImportService{
+		log:                 log,
+		personService:       personService,
+		subscriptionService: subscriptionService,
+		personSubService:    personSubService,
+	}
+}
+
+// Import adds the people of a CSV or XLSX file to the gym and issues the
+// memberships given in the same rows. The first row is the header.
+//
+// People already in the gym, or repeated in the file, are matched by full
+// name and phone and not added again; their memberships are still issued.
+// Invalid rows are skipped and reported, the others are imported. With
+// DryRun nothing is saved.
+//
+// A bad mapping is returned as validation.Errors wrapped in ErrInvalidMapping.
+func (s *ImportService) Import(
+	ctx context.Context,
+	gymID int64,
+	file io.Reader,
+	format string,
+	opts models.ImportOptions,
+) (models.ImportResult, error) {
+	const op = "services.import.Import"
+
+	log := requestctx.Logger(ctx, s.log).With(
+		slog.String("op", op),
+		slog.Bool("dry_run", opts.DryRun),
+	)
+
+	log.Info("Importing people")
+
+	rows, err := spreadsheet.Read(file, format)
+	if err != nil {
+		return models.ImportResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(rows) == 0 {
+		return models.ImportResult{}, fmt.Errorf("%s: %w", op, ErrEmptyFile)
+	}
+
+	columns, errs := resolveColumns(rows[0], opts.Mapping)
+	if errs != nil {
+		return models.ImportResult{}, fmt.Errorf("%s: %w: %w", op, ErrInvalidMapping, errs)
+	}
+
+	people, err := s.personService.FindAllPeople(ctx, gymID)
+	if err != nil {
+		log.Error("failed to load people", sl.Error(err))
+		return models.ImportResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	known := make(map[string]int, len(people))
+	for _, p := range people {
+		known[personKey(p.Name, p.Phone)] = p.Id
+	}
+
+	plans := make(map[int64]bool)
+	if _, ok := columns[models.ImportSubscriptionID]; ok {
+		subs, err := s.subscriptionService.FindAllSubscriptions(ctx, gymID)
+		if err != nil {
+			log.Error("failed to load subscription plans", sl.Error(err))
+			return models.ImportResult{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		for _, sub := range subs {
+			if id, err := strconv.ParseInt(sub.ID, 10, 64); err == nil {
+				plans[id] = true
+			}
+		}
+	}
+
+	imp := &importRun{
+		ImportService: s,
+		log:           log,
+		gymID:         gymID,
+		dryRun:        opts.DryRun,
+		columns:       columns,
+		plans:         plans,
+		known:         known,
+		inFile:        make(map[string]int),
+		numbers:       make(map[string]bool),
+	}
+
+	result := models.ImportResult{DryRun: opts.DryRun, Header: rows[0]}
+
+	for i, values := range rows[1:] {
+		if blank(values) {
+			continue
+		}
+
+		row := imp.row(ctx, i+2, values)
+
+		result.Total++
+		switch row.Status {
+		case models.ImportCreated:
+			result.Created++
+		case models.ImportExisting:
+			result.Existing++
+		case models.ImportDuplicate:
+			result.Duplicates++
+		}
+		if row.Failed() {
+			result.Failed++
+		}
+		if row.Number != "" {
+			result.Memberships++
+		}
+
+		result.Rows = append(result.Rows, row)
+	}
+
+	log.Info("people imported",
+		slog.Int("total", result.Total),
+		slog.Int("created", result.Created),
+		slog.Int("memberships", result.Memberships),
+		slog.Int("failed", result.Failed),
+	)
+
+	return result, nil
+}
+
+// ErrorReport writes the rows that were not imported, with the original
+// columns and an error column in the given language. The file can be fixed
+// and imported again.
+func (s *ImportService) ErrorReport(w io.Writer, format, lang string, result models.ImportResult) error {
+	const op = "services.import.ErrorReport"
+
+	width := len(result.Header)
+	report := [][]string{append(append([]string{}, result.Header...), i18n.T(lang, "Import error"))}
+
+	for _, row := range result.Rows {
+		if !row.Failed() {
+			continue
+		}
+
+		msgs := make([]string, 0, len(row.Errors))
+		for _, fe := range row.Errors.Localize(lang) {
+			msgs = append(msgs, fe.Field+": "+fe.Message)
+		}
+
+		values := make([]string, width, width+1)
+		copy(values, row.Values)
+
+		report = append(report, append(values, strings.Join(msgs, "; ")))
+	}
+
+	if err := spreadsheet.Write(w, format, report); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// importRun is the state of one import: what is known about the gym and
+// what was seen in the file so far.
+type importRun struct {
+	*ImportService
+
+	log     *slog.Logger
+	gymID   int64
+	dryRun  bool
+	columns map[string]int
+	plans   map[int64]bool
+	known   map[string]int // people of the gym by personKey
+	inFile  map[string]int // first row of each person of the file
+	numbers map[string]bool
+}
+
+func (imp *importRun) row(ctx context.Context, n int, values []string) models.ImportRow {
+	row := models.ImportRow{Row: n, Values: values}
+
+	person := models.Person{
+		Name:  imp.cell(values, models.ImportName),
+		Phone: imp.cell(values, models.ImportPhone),
+	}
+
+	row.Errors = person.Validate()
+
+	personSub, hasSub, errs := imp.personSub(values)
+	row.Errors = validation.Merge(row.Errors, errs)
+
+	if row.Errors != nil {
+		row.Status = models.ImportInvalid
+		return row
+	}
+
+	key := personKey(person.Name, person.Phone)
+
+	switch id, ok := imp.known[key]; {
+	case imp.inFile[key] != 0:
+		row.Status, row.PersonID = models.ImportDuplicate, id
+	case ok:
+		row.Status, row.PersonID = models.ImportExisting, id
+	default:
+		row.Status = models.ImportCreated
+	}
+
+	if imp.dryRun {
+		imp.seen(key, n)
+		return row
+	}
+
+	if row.Status == models.ImportCreated {
+		id, err := imp.personService.AddPerson(ctx, imp.gymID, person)
+		if err != nil {
+			imp.log.Error("failed to add person", slog.Int("row", n), sl.Error(err))
+
+			// Not marked as seen: the next rows of the person try to add it
+			// again instead of passing as duplicates without a person
+			row.Status = models.ImportFailed
+			row.Errors = validation.Errors{saveError(err)}
+			return row
+		}
+
+		row.PersonID = id
+		imp.known[key] = id
+	}
+
+	imp.seen(key, n)
+
+	if !hasSub || row.PersonID == 0 {
+		return row
+	}
+
+	personSub.PersonID = int64(row.PersonID)
+
+	number, err := imp.personSubService.AddPersonSub(ctx, imp.gymID, personSub)
+	if err != nil {
+		imp.log.Error("failed to add membership", slog.Int("row", n), sl.Error(err))
+
+		row.Status = models.ImportFailed
+		row.Errors = validation.Errors{saveError(err)}
+		return row
+	}
+
+	row.Number = number
+
+	return row
+}
+
+// personSub reads the membership of a row. A row without a subscription
+// plan has none.
+func (imp *importRun) personSub(values []string) (models.PersonSubStrDate, bool, validation.Errors) {
+	planID := imp.cell(values, models.ImportSubscriptionID)
+	if planID == "" {
+		return models.PersonSubStrDate{}, false, nil
+	}
+
+	personSub := models.PersonSubStrDate{
+		Number:    imp.cell(values, models.ImportNumber),
+		StartDate: normalizeDate(imp.cell(values, models.ImportStartDate)),
+		EndDate:   normalizeDate(imp.cell(values, models.ImportEndDate)),
+	}
+
+	var errs validation.Errors
+
+	id, err := strconv.ParseInt(planID, 10, 64)
+	switch {
+	case err != nil:
+		errs = append(errs, validation.NewFieldError(rowScope, models.ImportSubscriptionID, codeInteger, ""))
+	case !imp.plans[id]:
+		errs = append(errs, validation.NewFieldError(rowScope, models.ImportSubscriptionID, codeNotFound, ""))
+	default:
+		personSub.SubscriptionID = id
+	}
+
+	// The person is not known yet, person_id is checked when it is saved
+	for _, fe := range personSub.Validate() {
+		if fe.Field != "person_id" && fe.Field != models.ImportSubscriptionID {
+			errs = append(errs, fe)
+		}
+	}
+
+	if personSub.Number != "" {
+		if imp.numbers[personSub.Number] {
+			errs = append(errs, validation.NewFieldError(rowScope, models.ImportNumber, codeExists, ""))
+		}
+		imp.numbers[personSub.Number] = true
+	}
+
+	return personSub, true, errs
+}
+
+// seen records the first row of a person of the file.
+func (imp *importRun) seen(key string, n int) {
+	if _, ok := imp.inFile[key]; !ok {
+		imp.inFile[key] = n
+	}
+}
+
+func (imp *importRun) cell(values []string, field string) string {
+	i, ok := imp.columns[field]
+	if !ok || i >= len(values) {
+		return ""
+	}
+
+	return strings.TrimSpace(values[i])
+}
+
+// resolveColumns finds the column of every import field. Name and phone
+// are required, the membership fields are optional.
+func resolveColumns(header []string, mapping models.ImportMapping) (map[string]int, validation.Errors) {
+	index := make(map[string]int, len(header))
+	for i, title := range header {
+		title = strings.ToLower(strings.TrimSpace(title))
+		if _, ok := index[title]; !ok && title != "" {
+			index[title] = i
+		}
+	}
+
+	var errs validation.Errors
+
+	for field := range mapping {
+		if !isImportField(field) {
+			errs = append(errs, validation.NewFieldError(mappingScope, field, codeUnknown, ""))
+		}
+	}
+
+	columns := make(map[string]int)
+
+	for _, field := range models.ImportFields {
+		title, mapped := mapping[field]
+		if !mapped {
+			title = field
+		}
+
+		i, ok := index[strings.ToLower(strings.TrimSpace(title))]
+		switch {
+		case ok:
+			columns[field] = i
+		case mapped:
+			errs = append(errs, validation.NewFieldError(mappingScope, field, codeColumn, title))
+		case field == models.ImportName || field == models.ImportPhone:
+			errs = append(errs, validation.NewFieldError(mappingScope, field, "required", ""))
+		}
+	}
+
+	return columns, errs
+}
+
+func isImportField(field string) bool {
+	for _, f := range models.ImportFields {
+		if f == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// saveError turns a service error into an error of the row.
+func saveError(err error) validation.FieldError {
+	switch {
+	case errors.Is(err, personService.ErrPersonExists):
+		return validation.NewFieldError(rowScope, models.ImportName, codeExists, "")
+	case errors.Is(err, personSubService.ErrSubExists), errors.Is(err, personSubService.ErrCardRevoked):
+		return validation.NewFieldError(rowScope, models.ImportNumber, codeExists, "")
+	case errors.Is(err, personSubService.ErrPlanNotFound):
+		return validation.NewFieldError(rowScope, models.ImportSubscriptionID, codeNotFound, "")
+	case errors.Is(err, personSubService.ErrInvalidPeriod):
+		return validation.NewFieldError(rowScope, models.ImportEndDate, validation.CodeDateOrder, "")
+	default:
+		return validation.NewFieldError(rowScope, "", "save", "")
+	}
+}
+
+// personKey identifies a person for duplicate detection: the full name
+// without case and extra spaces, and the normalized phone.
+func personKey(name, phone string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " ")) + "|" + validation.NormalizePhone(phone)
+}
+
+// normalizeDate brings a spreadsheet date to dd-mm-yyyy. Values that are
+// not dates are left for the validation to report.
+func normalizeDate(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	t, err := spreadsheet.ParseDate(value)
+	if err != nil {
+		return value
+	}
+
+	return t.Format(validation.DateLayout)
+}
+
+func blank(values []string) bool {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,200 @@
+package importService
+
+import (
+	"context"
+	"errors"
+	"gym_app/internal/models"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResolveColumns(t *testing.T) {
+	header := []string{" ФИО ", "Телефон", "subscription_id", "Number", "", "start_date"}
+
+	tests := []struct {
+		name     string
+		header   []string
+		mapping  models.ImportMapping
+		want     map[string]int
+		wantErrs []string // field:code
+	}{
+		{
+			name:   "field names as headers",
+			header: []string{"name", "phone"},
+			want:   map[string]int{"name": 0, "phone": 1},
+		},
+		{
+			name:    "mapping, case and spaces are ignored",
+			header:  header,
+			mapping: models.ImportMapping{"name": "фио", "phone": " ТЕЛЕФОН"},
+			want:    map[string]int{"name": 0, "phone": 1, "subscription_id": 2, "number": 3, "start_date": 5},
+		},
+		{
+			name:     "required fields missing",
+			header:   []string{"subscription_id"},
+			want:     map[string]int{"subscription_id": 0},
+			wantErrs: []string{"name:required", "phone:required"},
+		},
+		{
+			name:     "mapped column not in the file",
+			header:   header,
+			mapping:  models.ImportMapping{"name": "ФИО", "phone": "Тел."},
+			want:     map[string]int{"name": 0, "subscription_id": 2, "number": 3, "start_date": 5},
+			wantErrs: []string{"phone:column"},
+		},
+		{
+			name:     "unknown field in mapping",
+			header:   []string{"name", "phone", "email"},
+			mapping:  models.ImportMapping{"email": "email"},
+			want:     map[string]int{"name": 0, "phone": 1},
+			wantErrs: []string{"email:unknown_field"},
+		},
+		{
+			name:   "first of repeated headers wins",
+			header: []string{"name", "phone", "name"},
+			want:   map[string]int{"name": 0, "phone": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errs := resolveColumns(tt.header, tt.mapping)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("columns = %v, want %v", got, tt.want)
+			}
+
+			var codes []string
+			for _, fe := range errs {
+				codes = append(codes, fe.Field+":"+fe.Code)
+			}
+			if !reflect.DeepEqual(codes, tt.wantErrs) {
+				t.Errorf("errors = %v, want %v", codes, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestPersonKey(t *testing.T) {
+	tests := []struct {
+		a, b [2]string // name, phone
+		same bool
+	}{
+		{[2]string{"Иванов Иван", "79001112233"}, [2]string{"иванов  иван ", "+7 (900) 111-22-33"}, true},
+		{[2]string{"Иванов Иван", "79001112233"}, [2]string{"ИВАНОВ ИВАН", "89001112233"}, true},
+		{[2]string{"Иванов Иван", "79001112233"}, [2]string{"Иванов Иван", "79001112234"}, false},
+		// Порядок слов важен: это разные записи, их находит поиск дублей
+		{[2]string{"Иванов Иван", "79001112233"}, [2]string{"Иван Иванов", "79001112233"}, false},
+	}
+
+	for _, tt := range tests {
+		ka, kb := personKey(tt.a[0], tt.a[1]), personKey(tt.b[0], tt.b[1])
+		if (ka == kb) != tt.same {
+			t.Errorf("personKey(%q) = %q, personKey(%q) = %q, same = %v, want %v", tt.a, ka, tt.b, kb, ka == kb, tt.same)
+		}
+	}
+}
+
+type fakePeople struct {
+	existing []models.Person
+	failing  string // AddPerson fails for this name
+	added    []models.Person
+}
+
+func (f *fakePeople) AddPerson(_ context.Context, _ int64, p models.Person) (int, error) {
+	if p.Name == f.failing {
+		return 0, errors.New("connection reset")
+	}
+
+	f.added = append(f.added, p)
+
+	return 100 + len(f.added), nil
+}
+
+func (f *fakePeople) FindAllPeople(context.Context, int64) ([]models.Person, error) {
+	return f.existing, nil
+}
+
+type fakePlans struct{}
+
+func (fakePlans) FindAllSubscriptions(context.Context, int64) ([]models.Subscription, error) {
+	return []models.Subscription{{ID: "1", Title: "Месяц", DurationDays: 30}}, nil
+}
+
+type fakeMemberships struct {
+	added []models.PersonSubStrDate
+}
+
+func (f *fakeMemberships) AddPersonSub(_ context.Context, _ int64, ps models.PersonSubStrDate) (string, error) {
+	f.added = append(f.added, ps)
+
+	return "C" + strings.Repeat("0", len(f.added)), nil
+}
+
+func TestImport(t *testing.T) {
+	file := strings.Join([]string{
+		"name;phone;subscription_id;start_date",
+		"Иванов Иван;89001112233;1;01.02.2025", // 2: новый клиент
+		"иванов иван;79001112233;1;01.03.2025", // 3: повтор строки 2
+		"Петров Петр;79002223344;;",            // 4: уже есть в зале
+		"Сидоров Сидор;79003334455;1;",         // 5: не сохраняется
+		"Сидоров Сидор;79003334455;1;",         // 6: тот же клиент, не повтор
+		"Козлов;123;7;31.02.2025",              // 7: ошибки в полях
+		";;;",                                  // пустая строка пропускается
+	}, "\n")
+
+	people := &fakePeople{
+		existing: []models.Person{{Id: 7, Name: "Петров Петр", Phone: "79002223344"}},
+		failing:  "Сидоров Сидор",
+	}
+	memberships := &fakeMemberships{}
+	s := New(slog.New(slog.NewTextHandler(io.Discard, nil)), people, fakePlans{}, memberships)
+
+	result, err := s.Import(context.Background(), 1, strings.NewReader(file), "csv", models.ImportOptions{})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+
+	want := []struct {
+		status   string
+		personID int
+		withSub  bool
+	}{
+		{models.ImportCreated, 101, true},
+		{models.ImportDuplicate, 101, true},
+		{models.ImportExisting, 7, false},
+		{models.ImportFailed, 0, false},
+		{models.ImportFailed, 0, false},
+		{models.ImportInvalid, 0, false},
+	}
+
+	if len(result.Rows) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(result.Rows), len(want))
+	}
+
+	for i, w := range want {
+		row := result.Rows[i]
+		if row.Status != w.status || row.PersonID != w.personID || (row.Number != "") != w.withSub {
+			t.Errorf("row %d = %s, person %d, number %q; want %s, person %d, membership %v",
+				row.Row, row.Status, row.PersonID, row.Number, w.status, w.personID, w.withSub)
+		}
+		if row.Failed() && len(row.Errors) == 0 {
+			t.Errorf("row %d failed without errors", row.Row)
+		}
+	}
+
+	if result.Total != 6 || result.Created != 1 || result.Existing != 1 || result.Duplicates != 1 ||
+		result.Memberships != 2 || result.Failed != 3 {
+		t.Errorf("result = %+v", result)
+	}
+
+	if len(people.added) != 1 {
+		t.Errorf("added people = %v, want one", people.added)
+	}
+	if len(memberships.added) != 2 || memberships.added[0].StartDate != "01-02-2025" {
+		t.Errorf("added memberships = %+v", memberships.added)
+	}
+}
@@ -0,0 +1,234 @@
+package personService
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/requestctx"
+	"gym_app/internal/lib/validation"
+	"gym_app/internal/models"
+	"gym_app/internal/storage"
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// DefaultSimilarity is the lowest name similarity reported as a duplicate.
+// It lets through a typo or two in a full name, not a different first name.
+const DefaultSimilarity = 0.8
+
+// FindDuplicates groups the people of the gym who share a phone and whose
+// names have at least minSimilarity (0..1) in common. The order of the words
+// in a name and the letter case are ignored.
+func (p *PersonService) FindDuplicates(ctx context.Context, gymID int64, minSimilarity float64) ([]models.DuplicateGroup, error) {
+	const op = "services.person.FindDuplicates"
+
+	log := requestctx.Logger(ctx, p.log).With(
+		slog.String("op", op),
+	)
+
+	log.Info("Finding duplicate people")
+
+	people, err := p.personStorage.FindAllPeople(ctx, gymID)
+	if err != nil {
+		log.Error("failed to get people", sl.Error(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Старые записи могут хранить телефон в формате 8XXXXXXXXXX
+	byPhone := make(map[string][]models.Person)
+	for _, person := range people {
+		phone := validation.NormalizePhone(person.Phone)
+		byPhone[phone] = append(byPhone[phone], person)
+	}
+
+	groups := make([]models.DuplicateGroup, 0)
+	for phone, samePhone := range byPhone {
+		if len(samePhone) < 2 {
+			continue
+		}
+
+		groups = append(groups, similarGroups(phone, samePhone, minSimilarity)...)
+	}
+
+	slices.SortFunc(groups, func(a, b models.DuplicateGroup) int {
+		return cmp.Compare(a.Phone, b.Phone)
+	})
+
+	log.Info("duplicates found", slog.Int("groups", len(groups)))
+
+	return groups, nil
+}
+
+// MergePeople moves everything of person fromID to person toID and deletes
+// fromID.
+func (p *PersonService) MergePeople(ctx context.Context, gymID int64, fromID, toID int) (models.PersonMergeResult, error) {
+	const op = "services.person.MergePeople"
+
+	log := requestctx.Logger(ctx, p.log).With(
+		slog.String("op", op),
+		slog.Int("from_id", fromID),
+		slog.Int("to_id", toID),
+	)
+
+	if fromID == toID {
+		return models.PersonMergeResult{}, fmt.Errorf("%s: %w", op, ErrSamePerson)
+	}
+
+	log.Info("Merging people")
+
+	result, err := p.personStorage.MergePeople(ctx, gymID, fromID, toID)
+	if err != nil {
+		if errors.Is(err, storage.ErrPersonNotFound) {
+			log.Warn("person not found", sl.Error(err))
+
+			return models.PersonMergeResult{}, fmt.Errorf("%s: %w", op, ErrPersonNotFound)
+		}
+
+		log.Error("failed to merge people", sl.Error(err))
+
+		return models.PersonMergeResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("people merged",
+		slog.Int("memberships", result.Memberships),
+		slog.Int("bookings", result.Bookings),
+		slog.Int("skipped_bookings", result.SkippedBookings),
+		slog.Int("training_packages", result.TrainingPackages),
+	)
+
+	return result, nil
+}
+
+// similarGroups splits people with the same phone into groups of similar
+// names. Two people are in one group when a chain of similar names links
+// them.
+func similarGroups(phone string, people []models.Person, minSimilarity float64) []models.DuplicateGroup {
+	slices.SortFunc(people, func(a, b models.Person) int {
+		return cmp.Compare(a.Id, b.Id)
+	})
+
+	names := make([]string, len(people))
+	for i, person := range people {
+		names[i] = nameKey(person.Name)
+	}
+
+	// Номер группы для каждого клиента, -1 — пока без группы
+	group := make([]int, len(people))
+	for i := range group {
+		group[i] = -1
+	}
+
+	var groups []models.DuplicateGroup
+
+	for i := range people {
+		for j := i + 1; j < len(people); j++ {
+			similarity := nameSimilarity(names[i], names[j])
+			if similarity < minSimilarity {
+				continue
+			}
+
+			switch gi, gj := group[i], group[j]; {
+			case gi < 0 && gj < 0:
+				group[i], group[j] = len(groups), len(groups)
+				groups = append(groups, models.DuplicateGroup{Phone: phone, Similarity: similarity})
+			case gj < 0:
+				group[j] = gi
+			case gi < 0:
+				group[i] = gj
+			case gi != gj:
+				for k := range group {
+					if group[k] == gj {
+						group[k] = gi
+					}
+				}
+				groups[gi].Similarity = min(groups[gi].Similarity, groups[gj].Similarity)
+				groups[gj].Similarity = -1
+			}
+
+			g := group[i]
+			groups[g].Similarity = min(groups[g].Similarity, similarity)
+		}
+	}
+
+	for i, g := range group {
+		if g >= 0 {
+			groups[g].People = append(groups[g].People, people[i])
+		}
+	}
+
+	for i := range groups {
+		groups[i].Similarity = math.Round(groups[i].Similarity*100) / 100
+	}
+
+	// Группы, поглощенные другими, остаются пустыми
+	return slices.DeleteFunc(groups, func(g models.DuplicateGroup) bool {
+		return len(g.People) == 0
+	})
+}
+
+// nameKey brings a full name to lower case words in alphabetical order, so
+// "Иванов Иван" and "иван  ИВАНОВ" give the same key.
+func nameKey(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '-'
+	})
+	for i, w := range words {
+		words[i] = strings.ReplaceAll(w, "ё", "е")
+	}
+	slices.Sort(words)
+
+	return strings.Join(words, " ")
+}
+
+// nameSimilarity returns 1 minus the edit distance of two name keys divided
+// by the length of the longer one. A name that only lacks words of the other,
+// e.g. the patronymic, counts as the same name.
+func nameSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+
+	wa, wb := strings.Fields(a), strings.Fields(b)
+	if len(wa) > len(wb) {
+		wa, wb = wb, wa
+	}
+	if len(wa) >= 2 && !slices.ContainsFunc(wa, func(w string) bool { return !slices.Contains(wb, w) }) {
+		return 1
+	}
+
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(b)]
+}
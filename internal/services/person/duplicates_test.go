@@ -0,0 +1,176 @@
+package personService
+
+import (
+	"gym_app/internal/models"
+	"testing"
+)
+
+func TestNameKey(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Иванов Иван", "иван иванов"},
+		{"  иван   ИВАНОВ ", "иван иванов"},
+		{"Пётр Петров", "петр петров"},
+		{"Римский-Корсаков, Николай", "николай римский-корсаков"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := nameKey(tt.name); got != tt.want {
+			t.Errorf("nameKey(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "иван", 4},
+		{"иван", "иван", 0},
+		{"иванов", "иваноф", 1},
+		{"kitten", "sitting", 3},
+	}
+
+	for _, tt := range tests {
+		if got := levenshtein([]rune(tt.a), []rune(tt.b)); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestNameSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"иван иванов", "иван иванов", 1},
+		// Отчество есть только у одного из клиентов
+		{"иван иванов", "иван иванов петрович", 1},
+		// Одно совпавшее слово не делает имена одинаковыми
+		{"иван", "иван иванов", 1 - 7.0/11},
+		{"иван иванов", "иван иваноф", 1 - 1.0/11},
+		{"анна сидорова", "иван иванов", 1 - 9.0/13},
+		{"", "", 1},
+	}
+
+	for _, tt := range tests {
+		got := nameSimilarity(tt.a, tt.b)
+		if diff := got - tt.want; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("nameSimilarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSimilarGroups(t *testing.T) {
+	const phone = "79001112233"
+
+	tests := []struct {
+		name   string
+		people []models.Person
+		want   []models.DuplicateGroup
+	}{
+		{
+			name: "one group",
+			people: []models.Person{
+				{Id: 3, Name: "Иванов Иван Петрович"},
+				{Id: 1, Name: "Иванов Иван"},
+				{Id: 2, Name: "Иван Иванов"},
+			},
+			want: []models.DuplicateGroup{
+				{Similarity: 1, People: []models.Person{
+					{Id: 1, Name: "Иванов Иван"},
+					{Id: 2, Name: "Иван Иванов"},
+					{Id: 3, Name: "Иванов Иван Петрович"},
+				}},
+			},
+		},
+		{
+			name: "different people on one phone",
+			people: []models.Person{
+				{Id: 1, Name: "Петров Пётр"},
+				{Id: 2, Name: "Сидорова Анна"},
+				{Id: 3, Name: "Петров Петр"},
+			},
+			want: []models.DuplicateGroup{
+				{Similarity: 1, People: []models.Person{
+					{Id: 1, Name: "Петров Пётр"},
+					{Id: 3, Name: "Петров Петр"},
+				}},
+			},
+		},
+		{
+			name: "typo",
+			people: []models.Person{
+				{Id: 1, Name: "Иванов Иван"},
+				{Id: 2, Name: "Иваноф Иван"},
+			},
+			want: []models.DuplicateGroup{
+				{Similarity: 0.91, People: []models.Person{
+					{Id: 1, Name: "Иванов Иван"},
+					{Id: 2, Name: "Иваноф Иван"},
+				}},
+			},
+		},
+		{
+			// Пары 1-3 и 2-4 сначала попадают в разные группы,
+			// а похожие 3 и 4 сливают их в одну
+			name: "chain joins groups",
+			people: []models.Person{
+				{Id: 1, Name: "Иванов Иван Петрович"},
+				{Id: 2, Name: "Иваноф Иван Сергеевич"},
+				{Id: 3, Name: "Иванов Иван"},
+				{Id: 4, Name: "Иваноф Иван"},
+				{Id: 5, Name: "Сидорова Анна"},
+			},
+			want: []models.DuplicateGroup{
+				{Similarity: 0.91, People: []models.Person{
+					{Id: 1, Name: "Иванов Иван Петрович"},
+					{Id: 2, Name: "Иваноф Иван Сергеевич"},
+					{Id: 3, Name: "Иванов Иван"},
+					{Id: 4, Name: "Иваноф Иван"},
+				}},
+			},
+		},
+		{
+			name: "no duplicates",
+			people: []models.Person{
+				{Id: 1, Name: "Иванов Иван"},
+				{Id: 2, Name: "Сидорова Анна"},
+			},
+			want: []models.DuplicateGroup{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := similarGroups(phone, tt.people, DefaultSimilarity)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d groups %+v, want %d", len(got), got, len(tt.want))
+			}
+			for i, g := range got {
+				want := tt.want[i]
+				if g.Phone != phone || g.Similarity != want.Similarity || !samePeople(g.People, want.People) {
+					t.Errorf("group %d = %+v, want %+v", i, g, want)
+				}
+			}
+		})
+	}
+}
+
+func samePeople(a, b []models.Person) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/requestctx"
 	"gym_app/internal/models"
 	"gym_app/internal/storage"
 	"log/slog"
@@ -16,16 +17,18 @@ type PersonService struct {
 }
 
 type PersonStorage interface {
-	SavePerson(ctx context.Context, person models.Person) (int, error)
-	FindAllPeople(ctx context.Context) ([]models.Person, error)
-	UpdatePerson(ctx context.Context, person models.Person, pID int) (int, error)
-	DeletePerson(ctx context.Context, pID int) error
-	FindPersonByName(ctx context.Context, name string) (models.Person, error)
+	SavePerson(ctx context.Context, gymID int64, person models.Person) (int, error)
+	FindAllPeople(ctx context.Context, gymID int64) ([]models.Person, error)
+	UpdatePerson(ctx context.Context, gymID int64, person models.Person, pID int) (int, error)
+	DeletePerson(ctx context.Context, gymID int64, pID int) error
+	FindPersonByName(ctx context.Context, gymID int64, name string) (models.Person, error)
+	MergePeople(ctx context.Context, gymID int64, fromID, toID int) (models.PersonMergeResult, error)
 }
 
 var (
 	ErrPersonExists   = errors.New("person already exists")
 	ErrPersonNotFound = errors.New("person not found")
+	ErrSamePerson     = errors.New("cannot merge a person with itself")
 )
 
 func New(
@@ -38,17 +41,17 @@ func New(
 	}
 }
 
-func (p *PersonService) AddPerson(ctx context.Context, person models.Person) (int, error) {
+func (p *PersonService) AddPerson(ctx context.Context, gymID int64, person models.Person) (int, error) {
 
 	const op = "services.person.addPerson"
 
-	log := p.log.With(
+	log := requestctx.Logger(ctx, p.log).With(
 		slog.String("op", op),
 	)
 
 	log.Info("Registering new user")
 
-	personId, err := p.personStorage.SavePerson(ctx, person)
+	personId, err := p.personStorage.SavePerson(ctx, gymID, person)
 	if err != nil {
 		if errors.Is(err, storage.ErrUserExists) {
 			log.Warn("user already exists", sl.Error(err))
@@ -64,16 +67,16 @@ func (p *PersonService) AddPerson(ctx context.Context, person models.Person) (in
 	return personId, nil
 }
 
-func (p *PersonService) UpdatePerson(ctx context.Context, person models.Person, pID int) (int, error) {
+func (p *PersonService) UpdatePerson(ctx context.Context, gymID int64, person models.Person, pID int) (int, error) {
 	const op = "services.person.UpdatePerson"
 
-	log := p.log.With(
+	log := requestctx.Logger(ctx, p.log).With(
 		slog.String("op", op),
 	)
 
 	log.Info("Updating user")
 
-	personId, err := p.personStorage.UpdatePerson(ctx, person, pID)
+	personId, err := p.personStorage.UpdatePerson(ctx, gymID, person, pID)
 	if err != nil {
 		if errors.Is(err, storage.ErrPersonNotFound) {
 			log.Warn("user not found", sl.Error(err))
@@ -95,16 +98,16 @@ func (p *PersonService) UpdatePerson(ctx context.Context, person models.Person,
 	return personId, nil
 }
 
-func (p *PersonService) DeletePerson(ctx context.Context, pID int) error {
+func (p *PersonService) DeletePerson(ctx context.Context, gymID int64, pID int) error {
 	const op = "services.person.DeletePerson"
 
-	log := p.log.With(
+	log := requestctx.Logger(ctx, p.log).With(
 		slog.String("op", op),
 	)
 
 	log.Info("Deleting user")
 
-	err := p.personStorage.DeletePerson(ctx, pID)
+	err := p.personStorage.DeletePerson(ctx, gymID, pID)
 	if err != nil {
 		if errors.Is(err, storage.ErrPersonNotFound) {
 			log.Warn("user not found", sl.Error(err))
@@ -118,16 +121,16 @@ func (p *PersonService) DeletePerson(ctx context.Context, pID int) error {
 	return nil
 }
 
-func (p *PersonService) FindPersonByName(ctx context.Context, name string) (models.Person, error) {
+func (p *PersonService) FindPersonByName(ctx context.Context, gymID int64, name string) (models.Person, error) {
 	const op = "services.person.FindPersonByName"
 
-	log := p.log.With(
+	log := requestctx.Logger(ctx, p.log).With(
 		slog.String("op", op),
 	)
 
 	log.Info("Finding user by name")
 
-	person, err := p.personStorage.FindPersonByName(ctx, name)
+	person, err := p.personStorage.FindPersonByName(ctx, gymID, name)
 	if err != nil {
 		if errors.Is(err, storage.ErrPersonNotFound) {
 			log.Warn("user not found", sl.Error(err))
@@ -141,16 +144,16 @@ func (p *PersonService) FindPersonByName(ctx context.Context, name string) (mode
 	return person, nil
 }
 
-func (p *PersonService) FindAllPeople(ctx context.Context) ([]models.Person, error) {
+func (p *PersonService) FindAllPeople(ctx context.Context, gymID int64) ([]models.Person, error) {
 	const op = "services.person.FindAllPeople"
 
-	log := p.log.With(
+	log := requestctx.Logger(ctx, p.log).With(
 		slog.String("op", op),
 	)
 
 	log.Info("Starting to find people")
 
-	allPeople, err := p.personStorage.FindAllPeople(ctx)
+	allPeople, err := p.personStorage.FindAllPeople(ctx, gymID)
 	if err != nil {
 		log.Warn("error", sl.Error(err))
 
@@ -1,10 +1,13 @@
 package personSubService
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"gym_app/internal/lib/cardnumber"
 	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/requestctx"
 	"gym_app/internal/models"
 	"gym_app/internal/storage"
 	"log/slog"
@@ -15,47 +18,97 @@ const (
 	activeStatus  = "active"
 	frozenStatus  = "frozen"
 	expiredStatus = "expired"
+
+	dateLayout = "02-01-2006"
 )
 
 type PersonSubStorage interface {
-	AddPersonSub(ctx context.Context, personSub models.PersonSubscription) (string, error)
-	GetPersonSubByNumber(ctx context.Context, number string) (models.PersonSubscription, error)
-	GetAllPersonSubs(ctx context.Context) ([]models.PersonSubscription, error)
-	DeletePersonSub(ctx context.Context, number string) error
-	FindPersonSubByPersonName(ctx context.Context, name string) ([]models.PersonSubscription, error)
+	AddPersonSub(ctx context.Context, gymID int64, personSub models.PersonSubscription) (string, error)
+	GetPersonSubByNumber(ctx context.Context, gymID int64, number string) (models.PersonSubscription, error)
+	GetAllPersonSubs(ctx context.Context, gymID int64) ([]models.PersonSubscription, error)
+	GetPersonSubsAcrossGyms(ctx context.Context) ([]models.PersonSubscription, error)
+	DeletePersonSub(ctx context.Context, gymID int64, number string) error
+	FindPersonSubByPersonName(ctx context.Context, gymID int64, name string) ([]models.PersonSubscription, error)
 	UpdatePersonSubStatus(ctx context.Context, number string, status string) error
+	UpdatePersonSub(ctx context.Context, gymID int64, number string, personSub models.PersonSubscription, reason string) error
+	FindSubscriptionByID(ctx context.Context, gymID int64, subscriptionID int64) (models.Subscription, error)
+	ReplaceCard(ctx context.Context, gymID int64, number, newNumber, reason string) error
+	FindCardHistory(ctx context.Context, gymID int64, number string) ([]models.CardNumberChange, error)
+	IsCardRevoked(ctx context.Context, number string) (bool, error)
+	NextCardSequence(ctx context.Context) (int64, error)
 }
 
 var (
 	ErrSubExists      = errors.New("subscription with that number already exists")
 	ErrSubNotFound    = errors.New("subscription not found")
 	ErrPersonNotFound = errors.New("person not found")
+	ErrPlanNotFound   = errors.New("subscription plan not found in this gym")
+	ErrInvalidDate    = errors.New("date must be in dd-mm-yyyy format")
+	ErrInvalidPeriod  = errors.New("end date must not be before start date")
+	ErrCardRevoked    = errors.New("card number has been revoked")
+	ErrSameCardNumber = errors.New("new card number must differ from the current one")
+	ErrSubNotActive   = errors.New("subscription is not active")
+	ErrInvalidImage   = errors.New("unknown barcode kind or image format")
 )
 
 type PersonSubService struct {
 	log              *slog.Logger
 	personSubStorage PersonSubStorage
+	cardNumbers      *cardnumber.Generator
 }
 
-func New(log *slog.Logger, personSubStorage PersonSubStorage) *PersonSubService {
+func New(log *slog.Logger, personSubStorage PersonSubStorage, cardNumbers *cardnumber.Generator) *PersonSubService {
 	return &PersonSubService{
 		log:              log,
 		personSubStorage: personSubStorage,
+		cardNumbers:      cardNumbers,
 	}
 }
 
-func (p *PersonSubService) AddPersonSub(ctx context.Context, personSubStrDate models.PersonSubStrDate) (string, error) {
+// maxGenerateAttempts limits how many generated card numbers AddPersonSub
+// tries before giving up on collisions with hand-entered numbers.
+const maxGenerateAttempts = 5
+
+func (p *PersonSubService) AddPersonSub(ctx context.Context, gymID int64, personSubStrDate models.PersonSubStrDate) (string, error) {
 	const op = "services.personSub.AddPersonSub"
 
-	log := p.log.With(
+	log := requestctx.Logger(ctx, p.log).With(
 		slog.String("op", op),
 	)
 
 	log.Info("Adding new person subscription")
 
-	personSub := convertToPersonSub(personSubStrDate)
+	generate := personSubStrDate.Number == ""
+
+	var (
+		personSub       models.PersonSubscription
+		personSubNumber string
+		err             error
+	)
+
+	// Сгенерированный номер может совпасть с введенным вручную, тогда берем
+	// следующее значение последовательности
+	for attempt := 1; ; attempt++ {
+		if generate {
+			seq, err := p.personSubStorage.NextCardSequence(ctx)
+			if err != nil {
+				log.Error("failed to generate card number", sl.Error(err))
+
+				return "", fmt.Errorf("%s: %w", op, err)
+			}
+
+			personSubStrDate.Number = p.cardNumbers.Format(seq)
+		}
+
+		personSub = convertToPersonSub(personSubStrDate)
+
+		personSubNumber, err = p.personSubStorage.AddPersonSub(ctx, gymID, personSub)
+		if !generate || attempt == maxGenerateAttempts || !errors.Is(err, storage.ErrSubscriptionExists) {
+			break
+		}
 
-	personSubNumber, err := p.personSubStorage.AddPersonSub(ctx, personSub)
+		log.Warn("generated card number is taken", slog.String("number", personSub.Number))
+	}
 	if err != nil {
 
 		if errors.Is(err, storage.ErrSubscriptionExists) {
@@ -78,16 +131,16 @@ func (p *PersonSubService) AddPersonSub(ctx context.Context, personSubStrDate mo
 	return personSubNumber, nil
 }
 
-func (p *PersonSubService) DeletePersonSub(ctx context.Context, number string) error {
+func (p *PersonSubService) DeletePersonSub(ctx context.Context, gymID int64, number string) error {
 	const op = "services.personSub.DeletePersonSub"
 
-	log := p.log.With(
+	log := requestctx.Logger(ctx, p.log).With(
 		slog.String("op", op),
 	)
 
 	log.Info("Deleting person subscription")
 
-	err := p.personSubStorage.DeletePersonSub(ctx, number)
+	err := p.personSubStorage.DeletePersonSub(ctx, gymID, number)
 	if err != nil {
 
 		if errors.Is(err, storage.ErrSubscriptionNotFound) {
@@ -106,21 +159,25 @@ func (p *PersonSubService) DeletePersonSub(ctx context.Context, number string) e
 	return nil
 }
 
-func (p *PersonSubService) GetPersonSubByNumber(ctx context.Context, number string) (models.PersonSubStrDate, error) {
+func (p *PersonSubService) GetPersonSubByNumber(ctx context.Context, gymID int64, number string) (models.PersonSubStrDate, error) {
 	const op = "services.personSub.FindPersonSubByNumber"
 
-	log := p.log.With(
+	log := requestctx.Logger(ctx, p.log).With(
 		slog.String("op", op),
 	)
 
 	log.Info("Getting person subscription by number")
 
-	personSub, err := p.personSubStorage.GetPersonSubByNumber(ctx, number)
+	personSub, err := p.personSubStorage.GetPersonSubByNumber(ctx, gymID, number)
 	if err != nil {
-		return models.PersonSubStrDate{}, err
-	}
+		if errors.Is(err, storage.ErrSubscriptionNotFound) {
+			log.Warn("subscription not found", slog.String("number", number), sl.Error(err))
 
-	log.Info("dsd", personSub.SubscriptionID)
+			return models.PersonSubStrDate{}, fmt.Errorf("%s: %w", op, ErrSubNotFound)
+		}
+
+		return models.PersonSubStrDate{}, fmt.Errorf("%s: %w", op, err)
+	}
 
 	log.Info("person subscription found", "number", number)
 
@@ -129,16 +186,16 @@ func (p *PersonSubService) GetPersonSubByNumber(ctx context.Context, number stri
 	return personSubStrDate, nil
 }
 
-func (p *PersonSubService) GetAllPersonSubs(ctx context.Context) ([]models.PersonSubStrDate, error) {
+func (p *PersonSubService) GetAllPersonSubs(ctx context.Context, gymID int64) ([]models.PersonSubStrDate, error) {
 	const op = "services.personSub.GetAllPersonSubs"
 
-	log := p.log.With(
+	log := requestctx.Logger(ctx, p.log).With(
 		slog.String("op", op),
 	)
 
 	log.Info("Getting all person subscriptions")
 
-	personSubs, err := p.personSubStorage.GetAllPersonSubs(ctx)
+	personSubs, err := p.personSubStorage.GetAllPersonSubs(ctx, gymID)
 	if err != nil {
 		return nil, err
 	}
@@ -154,16 +211,16 @@ func (p *PersonSubService) GetAllPersonSubs(ctx context.Context) ([]models.Perso
 	return personSubsStrDate, nil
 }
 
-func (p *PersonSubService) FindPersonSubByPersonName(ctx context.Context, name string) ([]models.PersonSubStrDate, error) {
+func (p *PersonSubService) FindPersonSubByPersonName(ctx context.Context, gymID int64, name string) ([]models.PersonSubStrDate, error) {
 	const op = "services.personSub.FindPersonSubByPersonName"
 
-	log := p.log.With(
+	log := requestctx.Logger(ctx, p.log).With(
 		slog.String("op", op),
 	)
 
 	log.Info("Finding person subscription by person name")
 
-	personSubs, err := p.personSubStorage.FindPersonSubByPersonName(ctx, name)
+	personSubs, err := p.personSubStorage.FindPersonSubByPersonName(ctx, gymID, name)
 	if err != nil {
 
 		return nil, fmt.Errorf("%s: %w", op, err)
@@ -179,16 +236,280 @@ func (p *PersonSubService) FindPersonSubByPersonName(ctx context.Context, name s
 	return personSubsStrDate, nil
 }
 
+// UpdatePersonSub changes the plan, dates or card number of a person
+// subscription. Fields left nil keep their values. When the plan changes
+// without an explicit end date, the end date is derived from the plan
+// duration; when only the start date moves, the subscription keeps its
+// length. The status is recomputed from the resulting dates. A new card
+// number replaces the card like ReplaceCard does: the old number is kept in
+// the history and revoked.
+func (p *PersonSubService) UpdatePersonSub(
+	ctx context.Context,
+	gymID int64,
+	number string,
+	update models.PersonSubUpdate,
+) (models.PersonSubStrDate, error) {
+	const op = "services.personSub.UpdatePersonSub"
+
+	log := requestctx.Logger(ctx, p.log).With(
+		slog.String("op", op),
+		slog.String("number", number),
+	)
+
+	log.Info("Updating person subscription")
+
+	current, err := p.personSubStorage.GetPersonSubByNumber(ctx, gymID, number)
+	if err != nil {
+		if errors.Is(err, storage.ErrSubscriptionNotFound) {
+			log.Warn("subscription not found", sl.Error(err))
+
+			return models.PersonSubStrDate{}, fmt.Errorf("%s: %w", op, ErrSubNotFound)
+		}
+
+		log.Error("failed to get person subscription", sl.Error(err))
+
+		return models.PersonSubStrDate{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Абонемент мог быть найден по отозванному номеру, меняем по текущему
+	updated := current
+
+	if update.StartDate != nil {
+		updated.StartDate, err = time.Parse(dateLayout, *update.StartDate)
+		if err != nil {
+			return models.PersonSubStrDate{}, fmt.Errorf("%s: %w", op, ErrInvalidDate)
+		}
+	}
+
+	if update.SubscriptionID != nil && *update.SubscriptionID != current.SubscriptionID {
+		plan, err := p.personSubStorage.FindSubscriptionByID(ctx, gymID, *update.SubscriptionID)
+		if err != nil {
+			if errors.Is(err, storage.ErrPlanNotFound) {
+				log.Warn("subscription plan not found", sl.Error(err))
+
+				return models.PersonSubStrDate{}, fmt.Errorf("%s: %w", op, ErrPlanNotFound)
+			}
+
+			log.Error("failed to get subscription plan", sl.Error(err))
+
+			return models.PersonSubStrDate{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		updated.SubscriptionID = *update.SubscriptionID
+		updated.EndDate = updated.StartDate.AddDate(0, 0, plan.DurationDays)
+	} else if update.StartDate != nil {
+		updated.EndDate = current.EndDate.Add(updated.StartDate.Sub(current.StartDate))
+	}
+
+	if update.EndDate != nil {
+		updated.EndDate, err = time.Parse(dateLayout, *update.EndDate)
+		if err != nil {
+			return models.PersonSubStrDate{}, fmt.Errorf("%s: %w", op, ErrInvalidDate)
+		}
+	}
+
+	if updated.EndDate.Before(updated.StartDate) {
+		return models.PersonSubStrDate{}, fmt.Errorf("%s: %w", op, ErrInvalidPeriod)
+	}
+
+	updated.Status = subStatus(updated.StartDate, updated.EndDate, time.Now().Truncate(24*time.Hour))
+
+	if update.Number != nil {
+		updated.Number = *update.Number
+	}
+
+	if err := p.personSubStorage.UpdatePersonSub(ctx, gymID, current.Number, updated, update.Reason); err != nil {
+		switch {
+		case errors.Is(err, storage.ErrSubscriptionNotFound):
+			log.Warn("subscription not found", sl.Error(err))
+
+			return models.PersonSubStrDate{}, fmt.Errorf("%s: %w", op, ErrSubNotFound)
+		case errors.Is(err, storage.ErrSubscriptionExists):
+			log.Warn("new card number already taken", sl.Error(err))
+
+			return models.PersonSubStrDate{}, fmt.Errorf("%s: %w", op, ErrSubExists)
+		case errors.Is(err, storage.ErrPlanNotFound):
+			log.Warn("subscription plan not found", sl.Error(err))
+
+			return models.PersonSubStrDate{}, fmt.Errorf("%s: %w", op, ErrPlanNotFound)
+		}
+
+		log.Error("failed to update person subscription", sl.Error(err))
+
+		return models.PersonSubStrDate{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("person subscription updated", slog.String("current_number", updated.Number))
+
+	return convertToPersonSubStrDate(updated), nil
+}
+
+// ReplaceCard issues a new card number for a subscription. The old number
+// is revoked: it still resolves to the subscription in lookups but can no
+// longer be used for check-ins or issued again.
+func (p *PersonSubService) ReplaceCard(ctx context.Context, gymID int64, number string, replacement models.CardReplacement) error {
+	const op = "services.personSub.ReplaceCard"
+
+	log := requestctx.Logger(ctx, p.log).With(
+		slog.String("op", op),
+		slog.String("number", number),
+		slog.String("new_number", replacement.NewNumber),
+	)
+
+	log.Info("Replacing subscription card")
+
+	if replacement.NewNumber == number {
+		return fmt.Errorf("%s: %w", op, ErrSameCardNumber)
+	}
+
+	revoked, err := p.personSubStorage.IsCardRevoked(ctx, number)
+	if err != nil {
+		log.Error("failed to check card number", sl.Error(err))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if revoked {
+		log.Warn("card number already revoked")
+
+		return fmt.Errorf("%s: %w", op, ErrCardRevoked)
+	}
+
+	err = p.personSubStorage.ReplaceCard(ctx, gymID, number, replacement.NewNumber, replacement.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrSubscriptionNotFound):
+			log.Warn("subscription not found", sl.Error(err))
+
+			return fmt.Errorf("%s: %w", op, ErrSubNotFound)
+		case errors.Is(err, storage.ErrSubscriptionExists):
+			log.Warn("new card number already taken", sl.Error(err))
+
+			return fmt.Errorf("%s: %w", op, ErrSubExists)
+		}
+
+		log.Error("failed to replace card", sl.Error(err))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("subscription card replaced")
+
+	return nil
+}
+
+func (p *PersonSubService) FindCardHistory(ctx context.Context, gymID int64, number string) ([]models.CardNumberChange, error) {
+	const op = "services.personSub.FindCardHistory"
+
+	log := requestctx.Logger(ctx, p.log).With(
+		slog.String("op", op),
+		slog.String("number", number),
+	)
+
+	history, err := p.personSubStorage.FindCardHistory(ctx, gymID, number)
+	if err != nil {
+		log.Error("failed to get card history", sl.Error(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return history, nil
+}
+
+// CheckIn verifies a card presented at the entrance: the number must not be
+// revoked and the subscription must be active today.
+func (p *PersonSubService) CheckIn(ctx context.Context, gymID int64, number string) (models.PersonSubStrDate, error) {
+	const op = "services.personSub.CheckIn"
+
+	log := requestctx.Logger(ctx, p.log).With(
+		slog.String("op", op),
+		slog.String("number", number),
+	)
+
+	revoked, err := p.personSubStorage.IsCardRevoked(ctx, number)
+	if err != nil {
+		log.Error("failed to check card number", sl.Error(err))
+
+		return models.PersonSubStrDate{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if revoked {
+		log.Warn("check-in with revoked card")
+
+		return models.PersonSubStrDate{}, fmt.Errorf("%s: %w", op, ErrCardRevoked)
+	}
+
+	personSub, err := p.personSubStorage.GetPersonSubByNumber(ctx, gymID, number)
+	if err != nil {
+		if errors.Is(err, storage.ErrSubscriptionNotFound) {
+			log.Warn("subscription not found", sl.Error(err))
+
+			return models.PersonSubStrDate{}, fmt.Errorf("%s: %w", op, ErrSubNotFound)
+		}
+
+		log.Error("failed to get person subscription", sl.Error(err))
+
+		return models.PersonSubStrDate{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if subStatus(personSub.StartDate, personSub.EndDate, time.Now().Truncate(24*time.Hour)) != activeStatus {
+		log.Warn("check-in with inactive subscription")
+
+		return models.PersonSubStrDate{}, fmt.Errorf("%s: %w", op, ErrSubNotActive)
+	}
+
+	log.Info("person checked in")
+
+	return convertToPersonSubStrDate(personSub), nil
+}
+
+// CardImage renders the current number of a subscription as a Code128
+// barcode or a QR code for printing on the membership card.
+func (p *PersonSubService) CardImage(ctx context.Context, gymID int64, number, kind, format string) ([]byte, error) {
+	const op = "services.personSub.CardImage"
+
+	log := requestctx.Logger(ctx, p.log).With(
+		slog.String("op", op),
+		slog.String("number", number),
+	)
+
+	personSub, err := p.personSubStorage.GetPersonSubByNumber(ctx, gymID, number)
+	if err != nil {
+		if errors.Is(err, storage.ErrSubscriptionNotFound) {
+			log.Warn("subscription not found", sl.Error(err))
+
+			return nil, fmt.Errorf("%s: %w", op, ErrSubNotFound)
+		}
+
+		log.Error("failed to get person subscription", sl.Error(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var buf bytes.Buffer
+	if err := cardnumber.Render(&buf, personSub.Number, kind, format); err != nil {
+		if errors.Is(err, cardnumber.ErrUnknownKind) || errors.Is(err, cardnumber.ErrUnknownFormat) {
+			return nil, fmt.Errorf("%s: %w", op, ErrInvalidImage)
+		}
+
+		log.Error("failed to render card", sl.Error(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
 func (p *PersonSubService) UpdateStatuses(ctx context.Context) error {
 	const op = "services.personSub.UpdateStatuses"
 
-	log := p.log.With(
+	log := requestctx.Logger(ctx, p.log).With(
 		slog.String("op", op),
 	)
 
 	log.Info("Updating person subscription statuses")
 
-	subs, err := p.personSubStorage.GetAllPersonSubs(ctx)
+	subs, err := p.personSubStorage.GetPersonSubsAcrossGyms(ctx)
 	if err != nil {
 		log.Error("failed to get all person subscriptions", sl.Error(err))
 		return fmt.Errorf("%s: %w", op, err)
@@ -197,15 +518,7 @@ func (p *PersonSubService) UpdateStatuses(ctx context.Context) error {
 	today := time.Now().Truncate(24 * time.Hour)
 
 	for _, sub := range subs {
-		newStatus := ""
-
-		if sub.StartDate.After(today) {
-			newStatus = frozenStatus
-		} else if sub.EndDate.Before(today) {
-			newStatus = expiredStatus
-		} else {
-			newStatus = activeStatus
-		}
+		newStatus := subStatus(sub.StartDate, sub.EndDate, today)
 
 		if sub.Status != newStatus {
 			err := p.personSubStorage.UpdatePersonSubStatus(ctx, sub.Number, newStatus)
@@ -220,6 +533,19 @@ func (p *PersonSubService) UpdateStatuses(ctx context.Context) error {
 	return nil
 }
 
+// subStatus returns the status a subscription with the given dates has on
+// the given day: frozen before the start, expired after the end.
+func subStatus(startDate, endDate, today time.Time) string {
+	switch {
+	case startDate.After(today):
+		return frozenStatus
+	case endDate.Before(today):
+		return expiredStatus
+	default:
+		return activeStatus
+	}
+}
+
 func convertToPersonSubStrDate(personSub models.PersonSubscription) models.PersonSubStrDate {
 	return models.PersonSubStrDate{
 		PersonID:       personSub.PersonID,
@@ -228,6 +554,7 @@ func convertToPersonSubStrDate(personSub models.PersonSubscription) models.Perso
 		StartDate:      personSub.StartDate.Format("02-01-2006"),
 		EndDate:        personSub.EndDate.Format("02-01-2006"),
 		Status:         personSub.Status,
+		GymID:          personSub.GymID,
 	}
 }
 
@@ -261,5 +588,6 @@ func convertToPersonSub(personSubStrDate models.PersonSubStrDate) models.PersonS
 		StartDate:      startDate,
 		EndDate:        endDate,
 		Status:         personSubStrDate.Status,
+		GymID:          personSubStrDate.GymID,
 	}
 }
@@ -0,0 +1,146 @@
+package staffService
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/requestctx"
+	"gym_app/internal/models"
+	"gym_app/internal/storage"
+	"log/slog"
+)
+
+type StaffStorage interface {
+	SaveStaff(ctx context.Context, staff models.Staff) (int64, error)
+	UpdateStaff(ctx context.Context, staff models.Staff, staffID int64) error
+	DeleteStaff(ctx context.Context, staffID int64) error
+	FindAllStaff(ctx context.Context, gymID int64) ([]models.Staff, error)
+	FindStaffByUserID(ctx context.Context, userID int64) (models.Staff, error)
+}
+
+var (
+	ErrStaffExists   = errors.New("staff member with that user id already exists")
+	ErrStaffNotFound = errors.New("staff member not found")
+	ErrGymNotFound   = errors.New("gym not found")
+)
+
+type StaffService struct {
+	log          *slog.Logger
+	staffStorage StaffStorage
+}
+
+func New(log *slog.Logger, staffStorage StaffStorage) *StaffService {
+	return &StaffService{
+		log:          log,
+		staffStorage: staffStorage,
+	}
+}
+
+func (s *StaffService) AddStaff(ctx context.Context, staff models.Staff) (int64, error) {
+	const op = "services.staff.AddStaff"
+
+	log := requestctx.Logger(ctx, s.log).With(
+		slog.String("op", op),
+		slog.Int64("user_id", staff.UserID),
+	)
+
+	log.Info("Adding staff member")
+
+	staffID, err := s.staffStorage.SaveStaff(ctx, staff)
+	if err != nil {
+		log.Warn("failed to add staff member", sl.Error(err))
+
+		return 0, fmt.Errorf("%s: %w", op, mapStorageError(err))
+	}
+
+	log.Info("staff member added", slog.Int64("staff_id", staffID))
+
+	return staffID, nil
+}
+
+func (s *StaffService) UpdateStaff(ctx context.Context, staff models.Staff, staffID int64) error {
+	const op = "services.staff.UpdateStaff"
+
+	log := requestctx.Logger(ctx, s.log).With(
+		slog.String("op", op),
+		slog.Int64("staff_id", staffID),
+	)
+
+	log.Info("Updating staff member")
+
+	if err := s.staffStorage.UpdateStaff(ctx, staff, staffID); err != nil {
+		log.Warn("failed to update staff member", sl.Error(err))
+
+		return fmt.Errorf("%s: %w", op, mapStorageError(err))
+	}
+
+	log.Info("staff member updated")
+
+	return nil
+}
+
+func (s *StaffService) DeleteStaff(ctx context.Context, staffID int64) error {
+	const op = "services.staff.DeleteStaff"
+
+	log := requestctx.Logger(ctx, s.log).With(
+		slog.String("op", op),
+		slog.Int64("staff_id", staffID),
+	)
+
+	log.Info("Deleting staff member")
+
+	if err := s.staffStorage.DeleteStaff(ctx, staffID); err != nil {
+		log.Warn("failed to delete staff member", sl.Error(err))
+
+		return fmt.Errorf("%s: %w", op, mapStorageError(err))
+	}
+
+	log.Info("staff member deleted")
+
+	return nil
+}
+
+func (s *StaffService) FindAllStaff(ctx context.Context, gymID int64) ([]models.Staff, error) {
+	const op = "services.staff.FindAllStaff"
+
+	log := requestctx.Logger(ctx, s.log).With(
+		slog.String("op", op),
+	)
+
+	staff, err := s.staffStorage.FindAllStaff(ctx, gymID)
+	if err != nil {
+		log.Error("failed to get staff", sl.Error(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("staff found")
+
+	return staff, nil
+}
+
+// FindStaffByUserID resolves the staff member behind an SSO user.
+func (s *StaffService) FindStaffByUserID(ctx context.Context, userID int64) (models.Staff, error) {
+	const op = "services.staff.FindStaffByUserID"
+
+	staff, err := s.staffStorage.FindStaffByUserID(ctx, userID)
+	if err != nil {
+		return models.Staff{}, fmt.Errorf("%s: %w", op, mapStorageError(err))
+	}
+
+	return staff, nil
+}
+
+func mapStorageError(err error) error {
+	switch {
+	case errors.Is(err, storage.ErrStaffExists):
+		return ErrStaffExists
+	case errors.Is(err, storage.ErrStaffNotFound):
+		return ErrStaffNotFound
+	case errors.Is(err, storage.ErrGymNotFound):
+		return ErrGymNotFound
+	default:
+		return err
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/requestctx"
 	"gym_app/internal/models"
 	"gym_app/internal/storage"
 	"log/slog"
@@ -16,10 +17,10 @@ type SubscriptionService struct {
 }
 
 type SubscriptionStorage interface {
-	SaveSubscription(ctx context.Context, subscription models.Subscription) (int, error)
-	FindAllSubscriptions(ctx context.Context) ([]models.Subscription, error)
-	UpdateSubscription(ctx context.Context, subscription models.Subscription, subID int) (int, error)
-	DeleteSubscription(ctx context.Context, subID int) error
+	SaveSubscription(ctx context.Context, gymID int64, subscription models.Subscription) (int, error)
+	FindAllSubscriptions(ctx context.Context, gymID int64) ([]models.Subscription, error)
+	UpdateSubscription(ctx context.Context, gymID int64, subscription models.Subscription, subID int) (int, error)
+	DeleteSubscription(ctx context.Context, gymID int64, subID int, allGyms bool) error
 }
 
 var (
@@ -37,23 +38,23 @@ func New(
 	}
 }
 
-func (m *SubscriptionService) AddSubscription(ctx context.Context, subscription models.Subscription) (int, error) {
+func (m *SubscriptionService) AddSubscription(ctx context.Context, gymID int64, subscription models.Subscription) (int, error) {
 	const op = "services.subscription.AddSubscription"
 
-	log := m.log.With(
+	log := requestctx.Logger(ctx, m.log).With(
 		slog.String("op", op),
 	)
 
 	log.Info("Adding new membership")
 
-	subId, err := m.subscriptionStorage.SaveSubscription(ctx, subscription)
+	subId, err := m.subscriptionStorage.SaveSubscription(ctx, gymID, subscription)
 	if err != nil {
 
 		if errors.Is(err, storage.ErrSubscriptionExists) {
 			log.Warn("subscription already exists", sl.Error(err))
 			return 0, fmt.Errorf("%s: %w", op, ErrSubExists)
 		}
-		return 0, fmt.Errorf("%s: %w", op, sl.Error(err))
+		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 
 	log.Info("subscription registered", "mid", subId)
@@ -61,23 +62,23 @@ func (m *SubscriptionService) AddSubscription(ctx context.Context, subscription
 	return subId, nil
 }
 
-func (m *SubscriptionService) UpdateSubscription(ctx context.Context, subscription models.Subscription, subID int) (int, error) {
+func (m *SubscriptionService) UpdateSubscription(ctx context.Context, gymID int64, subscription models.Subscription, subID int) (int, error) {
 	const op = "services.subscription.UpdateSubscription"
 
-	log := m.log.With(
+	log := requestctx.Logger(ctx, m.log).With(
 		slog.String("op", op),
 	)
 
 	log.Info("Updating subscription")
 
-	subId, err := m.subscriptionStorage.UpdateSubscription(ctx, subscription, subID)
+	subId, err := m.subscriptionStorage.UpdateSubscription(ctx, gymID, subscription, subID)
 	if err != nil {
 		if errors.Is(err, storage.ErrSubscriptionNotFound) {
 			log.Warn("subscription not found", sl.Error(err))
 			return 0, fmt.Errorf("%s: %w", op, ErrSubNotFound)
 		}
 
-		return 0, fmt.Errorf("%s: %w", op, sl.Error(err))
+		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 
 	log.Info("subscription updated", "mid", subId)
@@ -85,18 +86,25 @@ func (m *SubscriptionService) UpdateSubscription(ctx context.Context, subscripti
 	return subId, nil
 }
 
-func (m *SubscriptionService) DeleteSubscription(ctx context.Context, subID int) error {
+// DeleteSubscription deletes a tariff of the gym, or a tariff of all gyms
+// when allGyms is set.
+func (m *SubscriptionService) DeleteSubscription(ctx context.Context, gymID int64, subID int, allGyms bool) error {
 	const op = "services.subscription.DeleteSubscription"
 
-	log := m.log.With(
+	log := requestctx.Logger(ctx, m.log).With(
 		slog.String("op", op),
 	)
 
 	log.Info("Deleting subscription")
 
-	err := m.subscriptionStorage.DeleteSubscription(ctx, subID)
+	err := m.subscriptionStorage.DeleteSubscription(ctx, gymID, subID, allGyms)
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, sl.Error(err))
+		if errors.Is(err, storage.ErrSubscriptionNotFound) {
+			log.Warn("subscription not found", sl.Error(err))
+			return fmt.Errorf("%s: %w", op, ErrSubNotFound)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
 	}
 
 	log.Info("subscription deleted")
@@ -104,14 +112,14 @@ func (m *SubscriptionService) DeleteSubscription(ctx context.Context, subID int)
 	return nil
 }
 
-func (m *SubscriptionService) FindAllSubscriptions(ctx context.Context) ([]models.Subscription, error) {
+func (m *SubscriptionService) FindAllSubscriptions(ctx context.Context, gymID int64) ([]models.Subscription, error) {
 	const op = "services.subscription.FindAllSubscriptions"
 
-	log := m.log.With(
+	log := requestctx.Logger(ctx, m.log).With(
 		slog.String("op", op),
 	)
 
-	subscriptions, err := m.subscriptionStorage.FindAllSubscriptions(ctx)
+	subscriptions, err := m.subscriptionStorage.FindAllSubscriptions(ctx, gymID)
 	if err != nil {
 		log.Warn("error", sl.Error(err))
 
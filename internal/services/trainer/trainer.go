@@ -0,0 +1,135 @@
+package trainerService
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/requestctx"
+	"gym_app/internal/models"
+	"gym_app/internal/storage"
+	"log/slog"
+)
+
+type TrainerStorage interface {
+	SaveTrainer(ctx context.Context, gymID int64, trainer models.Trainer) (int64, error)
+	UpdateTrainer(ctx context.Context, gymID int64, trainer models.Trainer, trainerID int64) error
+	DeleteTrainer(ctx context.Context, gymID int64, trainerID int64) error
+	FindAllTrainers(ctx context.Context, gymID int64) ([]models.Trainer, error)
+}
+
+var (
+	ErrTrainerNotFound = errors.New("trainer not found")
+	ErrTrainerInUse    = errors.New("trainer has scheduled classes")
+	ErrStaffNotFound   = errors.New("staff member not found")
+	ErrStaffTaken      = errors.New("staff member is already linked to another trainer")
+)
+
+type TrainerService struct {
+	log            *slog.Logger
+	trainerStorage TrainerStorage
+}
+
+func New(log *slog.Logger, trainerStorage TrainerStorage) *TrainerService {
+	return &TrainerService{
+		log:            log,
+		trainerStorage: trainerStorage,
+	}
+}
+
+func (t *TrainerService) AddTrainer(ctx context.Context, gymID int64, trainer models.Trainer) (int64, error) {
+	const op = "services.trainer.AddTrainer"
+
+	log := requestctx.Logger(ctx, t.log).With(
+		slog.String("op", op),
+	)
+
+	log.Info("Adding trainer")
+
+	trainerID, err := t.trainerStorage.SaveTrainer(ctx, gymID, trainer)
+	if err != nil {
+		log.Warn("failed to add trainer", sl.Error(err))
+
+		return 0, fmt.Errorf("%s: %w", op, mapStorageError(err))
+	}
+
+	log.Info("trainer added", slog.Int64("trainer_id", trainerID))
+
+	return trainerID, nil
+}
+
+func (t *TrainerService) UpdateTrainer(ctx context.Context, gymID int64, trainer models.Trainer, trainerID int64) error {
+	const op = "services.trainer.UpdateTrainer"
+
+	log := requestctx.Logger(ctx, t.log).With(
+		slog.String("op", op),
+		slog.Int64("trainer_id", trainerID),
+	)
+
+	log.Info("Updating trainer")
+
+	if err := t.trainerStorage.UpdateTrainer(ctx, gymID, trainer, trainerID); err != nil {
+		log.Warn("failed to update trainer", sl.Error(err))
+
+		return fmt.Errorf("%s: %w", op, mapStorageError(err))
+	}
+
+	log.Info("trainer updated")
+
+	return nil
+}
+
+func (t *TrainerService) DeleteTrainer(ctx context.Context, gymID int64, trainerID int64) error {
+	const op = "services.trainer.DeleteTrainer"
+
+	log := requestctx.Logger(ctx, t.log).With(
+		slog.String("op", op),
+		slog.Int64("trainer_id", trainerID),
+	)
+
+	log.Info("Deleting trainer")
+
+	if err := t.trainerStorage.DeleteTrainer(ctx, gymID, trainerID); err != nil {
+		log.Warn("failed to delete trainer", sl.Error(err))
+
+		return fmt.Errorf("%s: %w", op, mapStorageError(err))
+	}
+
+	log.Info("trainer deleted")
+
+	return nil
+}
+
+func (t *TrainerService) FindAllTrainers(ctx context.Context, gymID int64) ([]models.Trainer, error) {
+	const op = "services.trainer.FindAllTrainers"
+
+	log := requestctx.Logger(ctx, t.log).With(
+		slog.String("op", op),
+	)
+
+	trainers, err := t.trainerStorage.FindAllTrainers(ctx, gymID)
+	if err != nil {
+		log.Error("failed to get trainers", sl.Error(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("trainers found")
+
+	return trainers, nil
+}
+
+func mapStorageError(err error) error {
+	switch {
+	case errors.Is(err, storage.ErrTrainerNotFound):
+		return ErrTrainerNotFound
+	case errors.Is(err, storage.ErrTrainerInUse):
+		return ErrTrainerInUse
+	case errors.Is(err, storage.ErrStaffNotFound):
+		return ErrStaffNotFound
+	case errors.Is(err, storage.ErrStaffExists):
+		return ErrStaffTaken
+	default:
+		return err
+	}
+}
@@ -0,0 +1,327 @@
+package trainingService
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gym_app/internal/lib/logger/sl"
+	"gym_app/internal/lib/requestctx"
+	"gym_app/internal/models"
+	"gym_app/internal/storage"
+	"log/slog"
+	"time"
+)
+
+const dateLayout = "02-01-2006"
+
+type TrainingStorage interface {
+	SaveTrainingPackage(ctx context.Context, gymID int64, pkg models.TrainingPackage) (int64, error)
+	UpdateTrainingPackage(ctx context.Context, gymID int64, pkg models.TrainingPackage, pkgID int64) error
+	DeleteTrainingPackage(ctx context.Context, gymID int64, pkgID int64) error
+	FindAllTrainingPackages(ctx context.Context, gymID int64) ([]models.TrainingPackage, error)
+
+	SellTrainingPackage(ctx context.Context, gymID, personID, pkgID, trainerID int64, startDate time.Time) (int64, error)
+	FindPersonTrainingPackages(ctx context.Context, gymID, personID int64) ([]models.PersonTrainingPackage, error)
+
+	BookTrainingSession(ctx context.Context, gymID, personPkgID int64, scheduledAt time.Time) (int64, error)
+	CancelTrainingSession(ctx context.Context, gymID, sessionID int64) error
+	CompleteTrainingSession(ctx context.Context, gymID, sessionID int64) error
+	FindUpcomingTrainingSessions(ctx context.Context, gymID, trainerID int64) ([]models.TrainingSession, error)
+
+	FindTrainerIDByStaffID(ctx context.Context, gymID, staffID int64) (int64, error)
+}
+
+var (
+	ErrPackageNotFound       = errors.New("training package not found")
+	ErrPackageInUse          = errors.New("training package has been sold")
+	ErrSaleRefsNotFound      = errors.New("person, training package or trainer not found")
+	ErrPersonPackageNotFound = errors.New("person training package not found")
+	ErrPackageExpired        = errors.New("training package expires before the session")
+	ErrPackageNotStarted     = errors.New("training package starts after the session")
+	ErrNoSessionsLeft        = errors.New("no training sessions left in the package")
+	ErrTrainerBusy           = errors.New("trainer already has a session at that time")
+	ErrSessionNotFound       = errors.New("booked training session not found")
+	ErrSessionInPast         = errors.New("session time has already passed")
+	ErrInvalidDate           = errors.New("date must be in dd-mm-yyyy format")
+	ErrNotATrainer           = errors.New("current user is not a trainer")
+)
+
+type TrainingService struct {
+	log             *slog.Logger
+	trainingStorage TrainingStorage
+}
+
+func New(log *slog.Logger, trainingStorage TrainingStorage) *TrainingService {
+	return &TrainingService{
+		log:             log,
+		trainingStorage: trainingStorage,
+	}
+}
+
+func (s *TrainingService) AddPackage(ctx context.Context, gymID int64, pkg models.TrainingPackage) (int64, error) {
+	const op = "services.training.AddPackage"
+
+	log := requestctx.Logger(ctx, s.log).With(
+		slog.String("op", op),
+	)
+
+	log.Info("Adding training package")
+
+	pkgID, err := s.trainingStorage.SaveTrainingPackage(ctx, gymID, pkg)
+	if err != nil {
+		log.Error("failed to add training package", sl.Error(err))
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("training package added", slog.Int64("package_id", pkgID))
+
+	return pkgID, nil
+}
+
+func (s *TrainingService) UpdatePackage(ctx context.Context, gymID int64, pkg models.TrainingPackage, pkgID int64) error {
+	const op = "services.training.UpdatePackage"
+
+	log := requestctx.Logger(ctx, s.log).With(
+		slog.String("op", op),
+		slog.Int64("package_id", pkgID),
+	)
+
+	log.Info("Updating training package")
+
+	if err := s.trainingStorage.UpdateTrainingPackage(ctx, gymID, pkg, pkgID); err != nil {
+		log.Warn("failed to update training package", sl.Error(err))
+
+		return fmt.Errorf("%s: %w", op, mapStorageError(err))
+	}
+
+	log.Info("training package updated")
+
+	return nil
+}
+
+func (s *TrainingService) DeletePackage(ctx context.Context, gymID int64, pkgID int64) error {
+	const op = "services.training.DeletePackage"
+
+	log := requestctx.Logger(ctx, s.log).With(
+		slog.String("op", op),
+		slog.Int64("package_id", pkgID),
+	)
+
+	log.Info("Deleting training package")
+
+	if err := s.trainingStorage.DeleteTrainingPackage(ctx, gymID, pkgID); err != nil {
+		log.Warn("failed to delete training package", sl.Error(err))
+
+		return fmt.Errorf("%s: %w", op, mapStorageError(err))
+	}
+
+	log.Info("training package deleted")
+
+	return nil
+}
+
+func (s *TrainingService) FindAllPackages(ctx context.Context, gymID int64) ([]models.TrainingPackage, error) {
+	const op = "services.training.FindAllPackages"
+
+	log := requestctx.Logger(ctx, s.log).With(
+		slog.String("op", op),
+	)
+
+	packages, err := s.trainingStorage.FindAllTrainingPackages(ctx, gymID)
+	if err != nil {
+		log.Error("failed to get training packages", sl.Error(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return packages, nil
+}
+
+// SellPackage sells a package to a client. The package starts today
+// unless a start date is given.
+func (s *TrainingService) SellPackage(ctx context.Context, gymID int64, sale models.PersonTrainingPackage) (int64, error) {
+	const op = "services.training.SellPackage"
+
+	log := requestctx.Logger(ctx, s.log).With(
+		slog.String("op", op),
+		slog.Int64("person_id", sale.PersonID),
+		slog.Int64("package_id", sale.PackageID),
+	)
+
+	log.Info("Selling training package")
+
+	startDate := time.Now().Truncate(24 * time.Hour)
+	if sale.StartDate != "" {
+		var err error
+		startDate, err = time.Parse(dateLayout, sale.StartDate)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", op, ErrInvalidDate)
+		}
+	}
+
+	personPkgID, err := s.trainingStorage.SellTrainingPackage(ctx, gymID, sale.PersonID, sale.PackageID, sale.TrainerID, startDate)
+	if err != nil {
+		log.Warn("failed to sell training package", sl.Error(err))
+
+		if errors.Is(err, storage.ErrTrainingPkgNotFound) {
+			return 0, fmt.Errorf("%s: %w", op, ErrSaleRefsNotFound)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("training package sold", slog.Int64("person_package_id", personPkgID))
+
+	return personPkgID, nil
+}
+
+func (s *TrainingService) FindPersonPackages(ctx context.Context, gymID, personID int64) ([]models.PersonTrainingPackage, error) {
+	const op = "services.training.FindPersonPackages"
+
+	log := requestctx.Logger(ctx, s.log).With(
+		slog.String("op", op),
+		slog.Int64("person_id", personID),
+	)
+
+	packages, err := s.trainingStorage.FindPersonTrainingPackages(ctx, gymID, personID)
+	if err != nil {
+		log.Error("failed to get person training packages", sl.Error(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return packages, nil
+}
+
+func (s *TrainingService) BookSession(ctx context.Context, gymID int64, session models.TrainingSession) (int64, error) {
+	const op = "services.training.BookSession"
+
+	log := requestctx.Logger(ctx, s.log).With(
+		slog.String("op", op),
+		slog.Int64("person_package_id", session.PersonPackageID),
+		slog.Time("scheduled_at", session.ScheduledAt),
+	)
+
+	log.Info("Booking training session")
+
+	if session.ScheduledAt.Before(time.Now()) {
+		return 0, fmt.Errorf("%s: %w", op, ErrSessionInPast)
+	}
+
+	sessionID, err := s.trainingStorage.BookTrainingSession(ctx, gymID, session.PersonPackageID, session.ScheduledAt)
+	if err != nil {
+		log.Warn("failed to book training session", sl.Error(err))
+
+		return 0, fmt.Errorf("%s: %w", op, mapStorageError(err))
+	}
+
+	log.Info("training session booked", slog.Int64("session_id", sessionID))
+
+	return sessionID, nil
+}
+
+func (s *TrainingService) CancelSession(ctx context.Context, gymID, sessionID int64) error {
+	const op = "services.training.CancelSession"
+
+	log := requestctx.Logger(ctx, s.log).With(
+		slog.String("op", op),
+		slog.Int64("session_id", sessionID),
+	)
+
+	if err := s.trainingStorage.CancelTrainingSession(ctx, gymID, sessionID); err != nil {
+		log.Warn("failed to cancel training session", sl.Error(err))
+
+		return fmt.Errorf("%s: %w", op, mapStorageError(err))
+	}
+
+	log.Info("training session cancelled")
+
+	return nil
+}
+
+// CompleteSession marks a session as held and consumes it from the client's package.
+func (s *TrainingService) CompleteSession(ctx context.Context, gymID, sessionID int64) error {
+	const op = "services.training.CompleteSession"
+
+	log := requestctx.Logger(ctx, s.log).With(
+		slog.String("op", op),
+		slog.Int64("session_id", sessionID),
+	)
+
+	if err := s.trainingStorage.CompleteTrainingSession(ctx, gymID, sessionID); err != nil {
+		log.Warn("failed to complete training session", sl.Error(err))
+
+		return fmt.Errorf("%s: %w", op, mapStorageError(err))
+	}
+
+	log.Info("training session completed")
+
+	return nil
+}
+
+// FindUpcomingSessions returns upcoming sessions of a trainer, or of every
+// trainer of the gym when trainerID is 0.
+func (s *TrainingService) FindUpcomingSessions(ctx context.Context, gymID, trainerID int64) ([]models.TrainingSession, error) {
+	const op = "services.training.FindUpcomingSessions"
+
+	log := requestctx.Logger(ctx, s.log).With(
+		slog.String("op", op),
+		slog.Int64("trainer_id", trainerID),
+	)
+
+	sessions, err := s.trainingStorage.FindUpcomingTrainingSessions(ctx, gymID, trainerID)
+	if err != nil {
+		log.Error("failed to get upcoming training sessions", sl.Error(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return sessions, nil
+}
+
+// FindStaffSessions returns upcoming sessions of the trainer linked to the staff member.
+func (s *TrainingService) FindStaffSessions(ctx context.Context, gymID, staffID int64) ([]models.TrainingSession, error) {
+	const op = "services.training.FindStaffSessions"
+
+	log := requestctx.Logger(ctx, s.log).With(
+		slog.String("op", op),
+		slog.Int64("staff_id", staffID),
+	)
+
+	trainerID, err := s.trainingStorage.FindTrainerIDByStaffID(ctx, gymID, staffID)
+	if err != nil {
+		if errors.Is(err, storage.ErrTrainerNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, ErrNotATrainer)
+		}
+
+		log.Error("failed to find trainer", sl.Error(err))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return s.FindUpcomingSessions(ctx, gymID, trainerID)
+}
+
+func mapStorageError(err error) error {
+	switch {
+	case errors.Is(err, storage.ErrTrainingPkgNotFound):
+		return ErrPackageNotFound
+	case errors.Is(err, storage.ErrTrainingPkgInUse):
+		return ErrPackageInUse
+	case errors.Is(err, storage.ErrPersonPkgNotFound):
+		return ErrPersonPackageNotFound
+	case errors.Is(err, storage.ErrPackageExpired):
+		return ErrPackageExpired
+	case errors.Is(err, storage.ErrPackageNotStarted):
+		return ErrPackageNotStarted
+	case errors.Is(err, storage.ErrNoSessionsLeft):
+		return ErrNoSessionsLeft
+	case errors.Is(err, storage.ErrTrainerBusy):
+		return ErrTrainerBusy
+	case errors.Is(err, storage.ErrSessionNotFound):
+		return ErrSessionNotFound
+	default:
+		return err
+	}
+}
@@ -0,0 +1,357 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gym_app/internal/models"
+	"gym_app/internal/storage"
+	"time"
+)
+
+func (s *Storage) SaveClassType(ctx context.Context, gymID int64, classType models.ClassType) (int64, error) {
+	const op = "storage.postgres.SaveClassType"
+
+	query := `INSERT INTO class_types(title, description, duration_minutes, gym_id) VALUES($1, $2, $3, $4) RETURNING id`
+
+	var classTypeID int64
+	err := s.db.QueryRow(ctx, query, classType.Title, classType.Description, classType.DurationMinutes, gymID).Scan(&classTypeID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrClassTypeExists)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return classTypeID, nil
+}
+
+func (s *Storage) UpdateClassType(ctx context.Context, gymID int64, classType models.ClassType, classTypeID int64) error {
+	const op = "storage.postgres.UpdateClassType"
+
+	query := `UPDATE class_types SET title = $1, description = $2, duration_minutes = $3 WHERE id = $4 AND gym_id = $5`
+
+	result, err := s.db.Exec(ctx, query, classType.Title, classType.Description, classType.DurationMinutes, classTypeID, gymID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return fmt.Errorf("%s: %w", op, storage.ErrClassTypeExists)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrClassTypeNotFound)
+	}
+
+	return nil
+}
+
+func (s *Storage) DeleteClassType(ctx context.Context, gymID int64, classTypeID int64) error {
+	const op = "storage.postgres.DeleteClassType"
+
+	query := `DELETE FROM class_types WHERE id = $1 AND gym_id = $2`
+
+	result, err := s.db.Exec(ctx, query, classTypeID, gymID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return fmt.Errorf("%s: %w", op, storage.ErrClassTypeInUse)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrClassTypeNotFound)
+	}
+
+	return nil
+}
+
+func (s *Storage) FindAllClassTypes(ctx context.Context, gymID int64) ([]models.ClassType, error) {
+	const op = "storage.postgres.FindAllClassTypes"
+
+	query := `SELECT id, title, description, duration_minutes, gym_id FROM class_types WHERE gym_id = $1 ORDER BY title`
+
+	rows, err := s.db.Query(ctx, query, gymID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.ClassType])
+}
+
+func (s *Storage) SaveClassSchedule(ctx context.Context, gymID int64, schedule models.ClassSchedule) (int64, error) {
+	const op = "storage.postgres.SaveClassSchedule"
+
+	// Вид занятия и тренер должны принадлежать тому же залу
+	query := `
+		INSERT INTO class_schedule (class_type_id, trainer_id, weekday, start_time, capacity, gym_id)
+		SELECT ct.id, t.id, $3, $4::time, $5, $6
+		FROM class_types ct, trainers t
+		WHERE ct.id = $1 AND ct.gym_id = $6
+		  AND t.id = $2 AND t.gym_id = $6
+		RETURNING id
+	`
+
+	var scheduleID int64
+	err := s.db.QueryRow(ctx, query,
+		schedule.ClassTypeID,
+		schedule.TrainerID,
+		schedule.Weekday,
+		schedule.StartTime,
+		schedule.Capacity,
+		gymID,
+	).Scan(&scheduleID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrClassTypeNotFound)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return scheduleID, nil
+}
+
+func (s *Storage) UpdateClassSchedule(ctx context.Context, gymID int64, schedule models.ClassSchedule, scheduleID int64) error {
+	const op = "storage.postgres.UpdateClassSchedule"
+
+	query := `
+		UPDATE class_schedule cs
+		SET class_type_id = ct.id, trainer_id = t.id, weekday = $3, start_time = $4::time, capacity = $5
+		FROM class_types ct, trainers t
+		WHERE cs.id = $7 AND cs.gym_id = $6
+		  AND ct.id = $1 AND ct.gym_id = $6
+		  AND t.id = $2 AND t.gym_id = $6
+	`
+
+	result, err := s.db.Exec(ctx, query,
+		schedule.ClassTypeID,
+		schedule.TrainerID,
+		schedule.Weekday,
+		schedule.StartTime,
+		schedule.Capacity,
+		gymID,
+		scheduleID,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrScheduleNotFound)
+	}
+
+	return nil
+}
+
+func (s *Storage) DeleteClassSchedule(ctx context.Context, gymID int64, scheduleID int64) error {
+	const op = "storage.postgres.DeleteClassSchedule"
+
+	query := `DELETE FROM class_schedule WHERE id = $1 AND gym_id = $2`
+
+	result, err := s.db.Exec(ctx, query, scheduleID, gymID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrScheduleNotFound)
+	}
+
+	return nil
+}
+
+func (s *Storage) FindClassSchedule(ctx context.Context, gymID int64) ([]models.ClassSchedule, error) {
+	const op = "storage.postgres.FindClassSchedule"
+
+	query := `
+		SELECT id, class_type_id, trainer_id, weekday, to_char(start_time, 'HH24:MI') AS start_time, capacity, gym_id
+		FROM class_schedule
+		WHERE gym_id = $1
+		ORDER BY weekday, start_time
+	`
+
+	rows, err := s.db.Query(ctx, query, gymID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.ClassSchedule])
+}
+
+// FindTimetable expands the weekly schedule into concrete classes between
+// from and to inclusive.
+func (s *Storage) FindTimetable(ctx context.Context, gymID int64, from, to time.Time) ([]models.ClassOccurrence, error) {
+	const op = "storage.postgres.FindTimetable"
+
+	query := `
+		SELECT
+			cs.id AS schedule_id,
+			to_char(d, 'DD-MM-YYYY') AS date,
+			to_char(cs.start_time, 'HH24:MI') AS start_time,
+			ct.id AS class_type_id,
+			ct.title,
+			t.id AS trainer_id,
+			t.full_name AS trainer_name,
+			cs.capacity,
+			(
+				SELECT count(*) FROM class_bookings b
+				WHERE b.schedule_id = cs.id AND b.class_date = d::date AND b.status <> 'cancelled'
+			)::int AS booked
+		FROM generate_series($2::date, $3::date, interval '1 day') d
+		JOIN class_schedule cs ON cs.weekday = EXTRACT(ISODOW FROM d)
+		JOIN class_types ct ON ct.id = cs.class_type_id
+		JOIN trainers t ON t.id = cs.trainer_id
+		WHERE cs.gym_id = $1
+		ORDER BY d, cs.start_time
+	`
+
+	rows, err := s.db.Query(ctx, query, gymID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.ClassOccurrence])
+}
+
+// BookClass records a person for a class after checking, under a lock on the
+// scheduled class, that the date matches the schedule, the person holds an
+// active membership for that date and there are free places left.
+func (s *Storage) BookClass(ctx context.Context, gymID, scheduleID, personID int64, classDate time.Time) (int64, error) {
+	const op = "storage.postgres.BookClass"
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var weekday, capacity int
+	err = tx.QueryRow(ctx,
+		`SELECT weekday, capacity FROM class_schedule WHERE id = $1 AND gym_id = $2 FOR UPDATE`,
+		scheduleID, gymID,
+	).Scan(&weekday, &capacity)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrScheduleNotFound)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if weekday != isoWeekday(classDate) {
+		return 0, fmt.Errorf("%s: %w", op, storage.ErrWrongClassDate)
+	}
+
+	var hasMembership bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM person_subscriptions
+			WHERE person_id = $1 AND gym_id = $2 AND status = 'active'
+			  AND start_date <= $3 AND end_date >= $3
+		)`,
+		personID, gymID, classDate,
+	).Scan(&hasMembership)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !hasMembership {
+		return 0, fmt.Errorf("%s: %w", op, storage.ErrNoActiveMembership)
+	}
+
+	var booked int
+	err = tx.QueryRow(ctx,
+		`SELECT count(*) FROM class_bookings WHERE schedule_id = $1 AND class_date = $2 AND status <> 'cancelled'`,
+		scheduleID, classDate,
+	).Scan(&booked)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if booked >= capacity {
+		return 0, fmt.Errorf("%s: %w", op, storage.ErrClassFull)
+	}
+
+	// Отмененную запись можно восстановить
+	var bookingID int64
+	err = tx.QueryRow(ctx, `
+		INSERT INTO class_bookings (schedule_id, class_date, person_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (schedule_id, class_date, person_id)
+		DO UPDATE SET status = 'booked', created_at = now()
+		WHERE class_bookings.status = 'cancelled'
+		RETURNING id`,
+		scheduleID, classDate, personID,
+	).Scan(&bookingID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrAlreadyBooked)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return bookingID, nil
+}
+
+// SetBookingStatus moves a booking out of the "booked" state.
+func (s *Storage) SetBookingStatus(ctx context.Context, gymID, bookingID int64, status string) error {
+	const op = "storage.postgres.SetBookingStatus"
+
+	query := `
+		UPDATE class_bookings b SET status = $1
+		FROM class_schedule cs
+		WHERE b.id = $2 AND cs.id = b.schedule_id AND cs.gym_id = $3 AND b.status = 'booked'
+	`
+
+	result, err := s.db.Exec(ctx, query, status, bookingID, gymID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrBookingNotFound)
+	}
+
+	return nil
+}
+
+func (s *Storage) FindClassBookings(ctx context.Context, gymID, scheduleID int64, classDate time.Time) ([]models.ClassBooking, error) {
+	const op = "storage.postgres.FindClassBookings"
+
+	query := `
+		SELECT b.id, b.schedule_id, to_char(b.class_date, 'DD-MM-YYYY') AS class_date, b.person_id, b.status, b.created_at
+		FROM class_bookings b
+		JOIN class_schedule cs ON cs.id = b.schedule_id
+		WHERE b.schedule_id = $1 AND b.class_date = $2 AND cs.gym_id = $3
+		ORDER BY b.created_at
+	`
+
+	rows, err := s.db.Query(ctx, query, scheduleID, classDate, gymID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.ClassBooking])
+}
+
+func isoWeekday(t time.Time) int {
+	if t.Weekday() == time.Sunday {
+		return 7
+	}
+
+	return int(t.Weekday())
+}
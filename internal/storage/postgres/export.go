@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"gym_app/internal/models"
+)
+
+// The export methods pass rows to fn as they are read, so that whole tables
+// are never held in memory. An error returned by fn stops the export.
+
+func (s *Storage) ExportPeople(ctx context.Context, gymID int64, filter models.ExportFilter, fn func(models.Person) error) error {
+	const op = "storage.postgres.ExportPeople"
+
+	query := `
+		SELECT id, full_name, phone, gym_id FROM person
+		WHERE gym_id = $1 AND ($2 = '' OR full_name = $2)
+		ORDER BY id
+	`
+
+	if err := forEachRow(ctx, s, fn, query, gymID, filter.Name); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) ExportPersonSubs(ctx context.Context, gymID int64, filter models.ExportFilter, fn func(models.PersonSubExport) error) error {
+	const op = "storage.postgres.ExportPersonSubs"
+
+	query := `
+		SELECT ps.number, ps.person_id, p.full_name, p.phone, ps.subscription_id,
+			s.title AS plan_title, s.price::float8 AS price,
+			to_char(ps.start_date, 'DD-MM-YYYY') AS start_date,
+			to_char(ps.end_date, 'DD-MM-YYYY') AS end_date,
+			ps.status
+		FROM person_subscriptions ps
+		JOIN person p ON p.id = ps.person_id
+		JOIN subscriptions s ON s.id = ps.subscription_id
+		WHERE ps.gym_id = $1 AND ($2 = '' OR p.full_name = $2)
+		ORDER BY ps.start_date, ps.number
+	`
+
+	if err := forEachRow(ctx, s, fn, query, gymID, filter.Name); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) ExportTrainingSales(ctx context.Context, gymID int64, filter models.ExportFilter, fn func(models.TrainingSaleExport) error) error {
+	const op = "storage.postgres.ExportTrainingSales"
+
+	query := `
+		SELECT ptp.id, ptp.person_id, p.full_name, p.phone,
+			tp.title AS package_title, t.full_name AS trainer_name, tp.price::float8 AS price,
+			ptp.sessions_total, ptp.sessions_used,
+			to_char(ptp.start_date, 'DD-MM-YYYY') AS start_date,
+			to_char(ptp.expiry_date, 'DD-MM-YYYY') AS expiry_date
+		FROM person_training_packages ptp
+		JOIN person p ON p.id = ptp.person_id
+		JOIN training_packages tp ON tp.id = ptp.package_id
+		JOIN trainers t ON t.id = ptp.trainer_id
+		WHERE ptp.gym_id = $1 AND ($2 = 0 OR ptp.person_id = $2)
+		ORDER BY ptp.start_date, ptp.id
+	`
+
+	if err := forEachRow(ctx, s, fn, query, gymID, filter.PersonID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// forEachRow scans the rows of a query into T one at a time and passes them
+// to fn.
+func forEachRow[T any](ctx context.Context, s *Storage, fn func(T) error, query string, args ...any) error {
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		v, err := pgx.RowToStructByName[T](rows)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gym_app/internal/models"
+	"gym_app/internal/storage"
+)
+
+func (s *Storage) SaveGym(ctx context.Context, gym models.Gym) (int64, error) {
+	const op = "storage.postgres.SaveGym"
+
+	query := `INSERT INTO gyms(name, address) VALUES($1, $2) RETURNING id`
+
+	var gymID int64
+	if err := s.db.QueryRow(ctx, query, gym.Name, gym.Address).Scan(&gymID); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrGymExists)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return gymID, nil
+}
+
+func (s *Storage) UpdateGym(ctx context.Context, gym models.Gym, gymID int64) error {
+	const op = "storage.postgres.UpdateGym"
+
+	query := `UPDATE gyms SET name = $1, address = $2 WHERE id = $3`
+
+	result, err := s.db.Exec(ctx, query, gym.Name, gym.Address, gymID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return fmt.Errorf("%s: %w", op, storage.ErrGymExists)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrGymNotFound)
+	}
+
+	return nil
+}
+
+func (s *Storage) DeleteGym(ctx context.Context, gymID int64) error {
+	const op = "storage.postgres.DeleteGym"
+
+	query := `DELETE FROM gyms WHERE id = $1`
+
+	result, err := s.db.Exec(ctx, query, gymID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return fmt.Errorf("%s: %w", op, storage.ErrGymInUse)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrGymNotFound)
+	}
+
+	return nil
+}
+
+func (s *Storage) FindAllGyms(ctx context.Context) ([]models.Gym, error) {
+	const op = "storage.postgres.FindAllGyms"
+
+	query := `SELECT id, name, address FROM gyms ORDER BY id`
+
+	rows, err := s.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.Gym])
+}
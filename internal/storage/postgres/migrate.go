@@ -0,0 +1,271 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// Migration is one schema change with its rollback.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+var (
+	ErrDirtyMigration = errors.New("database is dirty, fix the failed migration and reset the version by hand")
+	ErrNoMigration    = errors.New("no migration with this version")
+)
+
+// migrationsLock is the advisory lock key that keeps two migrators apart.
+const migrationsLock = 7147393
+
+var migrationFile = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// LoadMigrations reads NNNN_name.up.sql and NNNN_name.down.sql files from
+// fsys and returns them ordered by version.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	const op = "storage.postgres.LoadMigrations"
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		m := migrationFile.FindStringSubmatch(entry.Name())
+		if m == nil || entry.IsDir() {
+			continue
+		}
+
+		version, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", op, entry.Name(), err)
+		}
+
+		body, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		}
+
+		if m[3] == "up" {
+			mig.Up = string(body)
+		} else {
+			mig.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" {
+			return nil, fmt.Errorf("%s: migration %d has no up file", op, mig.Version)
+		}
+		migrations = append(migrations, *mig)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// MigrationVersion returns the version of the last applied migration, zero
+// if none was applied. The schema_migrations table is the one used by
+// golang-migrate, so databases migrated with its CLI keep their version.
+func (s *Storage) MigrationVersion(ctx context.Context) (int64, bool, error) {
+	const op = "storage.postgres.MigrationVersion"
+
+	if err := s.ensureMigrationsTable(ctx); err != nil {
+		return 0, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	version, dirty, err := migrationVersion(ctx, s.db)
+	if err != nil {
+		return 0, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return version, dirty, nil
+}
+
+// MigrateUp applies up to steps pending migrations, all of them when steps
+// is zero, and returns the applied ones. Every migration runs in its own
+// transaction together with the version update.
+func (s *Storage) MigrateUp(ctx context.Context, migrations []Migration, steps int) ([]Migration, error) {
+	const op = "storage.postgres.MigrateUp"
+
+	var applied []Migration
+
+	err := s.withMigrationsLock(ctx, func(conn *pgxpool.Conn) error {
+		version, dirty, err := migrationVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return ErrDirtyMigration
+		}
+
+		for _, mig := range migrations {
+			if mig.Version <= version {
+				continue
+			}
+			if steps > 0 && len(applied) == steps {
+				break
+			}
+
+			if err := runMigration(ctx, conn, mig.Up, mig.Version); err != nil {
+				return fmt.Errorf("migration %d_%s: %w", mig.Version, mig.Name, err)
+			}
+
+			applied = append(applied, mig)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return applied, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return applied, nil
+}
+
+// MigrateDown rolls back up to steps applied migrations, all of them when
+// steps is zero, and returns the rolled back ones.
+func (s *Storage) MigrateDown(ctx context.Context, migrations []Migration, steps int) ([]Migration, error) {
+	const op = "storage.postgres.MigrateDown"
+
+	var reverted []Migration
+
+	err := s.withMigrationsLock(ctx, func(conn *pgxpool.Conn) error {
+		version, dirty, err := migrationVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return ErrDirtyMigration
+		}
+
+		for i := len(migrations) - 1; i >= 0; i-- {
+			mig := migrations[i]
+			if mig.Version > version {
+				continue
+			}
+			if mig.Version < version && len(reverted) == 0 {
+				return fmt.Errorf("version %d: %w", version, ErrNoMigration)
+			}
+			if steps > 0 && len(reverted) == steps {
+				break
+			}
+
+			var previous int64
+			if i > 0 {
+				previous = migrations[i-1].Version
+			}
+
+			if err := runMigration(ctx, conn, mig.Down, previous); err != nil {
+				return fmt.Errorf("migration %d_%s: %w", mig.Version, mig.Name, err)
+			}
+
+			reverted = append(reverted, mig)
+			version = previous
+		}
+
+		return nil
+	})
+	if err != nil {
+		return reverted, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return reverted, nil
+}
+
+func (s *Storage) ensureMigrationsTable(ctx context.Context) error {
+	_, err := s.db.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT NOT NULL PRIMARY KEY,
+		dirty BOOLEAN NOT NULL
+	)`)
+
+	return err
+}
+
+// withMigrationsLock runs fn on a connection holding a session advisory
+// lock, so migrations applied before a failure stay committed.
+func (s *Storage) withMigrationsLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	if err := s.ensureMigrationsTable(ctx); err != nil {
+		return err
+	}
+
+	conn, err := s.db.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationsLock); err != nil {
+		return err
+	}
+	defer conn.Exec(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, migrationsLock)
+
+	return fn(conn)
+}
+
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func migrationVersion(ctx context.Context, q querier) (int64, bool, error) {
+	var (
+		version int64
+		dirty   bool
+	)
+
+	err := q.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+
+	return version, dirty, err
+}
+
+// runMigration executes a migration script in a transaction and records
+// the new version. A failed script leaves neither schema nor version changed.
+func runMigration(ctx context.Context, conn *pgxpool.Conn, script string, version int64) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if script != "" {
+		if _, err := tx.Exec(ctx, script); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM schema_migrations`); err != nil {
+		return err
+	}
+
+	if version > 0 {
+		if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations(version, dirty) VALUES($1, false)`, version); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
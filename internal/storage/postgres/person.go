@@ -12,12 +12,13 @@ import (
 
 func (s *Storage) SavePerson(
 	ctx context.Context,
+	gymID int64,
 	person models.Person,
 ) (int, error) {
 	const op = "postgres.savePerson"
 
-	query := `INSERT INTO person(full_name, phone) VALUES($1, $2) RETURNING id`
-	row := s.db.QueryRow(ctx, query, person.Name, person.Phone)
+	query := `INSERT INTO person(full_name, phone, gym_id) VALUES($1, $2, $3) RETURNING id`
+	row := s.db.QueryRow(ctx, query, person.Name, person.Phone, gymID)
 
 	var personId int
 	if err := row.Scan(&personId); err != nil {
@@ -37,16 +38,21 @@ func (s *Storage) SavePerson(
 
 func (s *Storage) UpdatePerson(
 	ctx context.Context,
+	gymID int64,
 	person models.Person,
 	pID int,
 ) (int, error) {
 	const op = "postgres.updatePerson"
 
-	query := `UPDATE person SET full_name = $1, phone = $2 WHERE id = $3 RETURNING id`
-	row := s.db.QueryRow(ctx, query, person.Name, person.Phone, pID)
+	query := `UPDATE person SET full_name = $1, phone = $2 WHERE id = $3 AND gym_id = $4 RETURNING id`
+	row := s.db.QueryRow(ctx, query, person.Name, person.Phone, pID, gymID)
 
 	var personId int
 	if err := row.Scan(&personId); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrPersonNotFound)
+		}
+
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) {
 			if pgErr.Code == "23505" {
@@ -60,11 +66,11 @@ func (s *Storage) UpdatePerson(
 	return personId, nil
 }
 
-func (s *Storage) DeletePerson(ctx context.Context, pID int) error {
+func (s *Storage) DeletePerson(ctx context.Context, gymID int64, pID int) error {
 	const op = "postgres.deletePerson"
 
-	query := `DELETE FROM person WHERE id = $1`
-	result, err := s.db.Exec(ctx, query, pID)
+	query := `DELETE FROM person WHERE id = $1 AND gym_id = $2`
+	result, err := s.db.Exec(ctx, query, pID, gymID)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
@@ -76,15 +82,16 @@ func (s *Storage) DeletePerson(ctx context.Context, pID int) error {
 	return nil
 }
 
-func (s *Storage) FindPersonByName(ctx context.Context, name string) (models.Person, error) {
+func (s *Storage) FindPersonByName(ctx context.Context, gymID int64, name string) (models.Person, error) {
 	const op = "postgres.findPersonByName"
 
-	query := `SELECT * FROM person WHERE full_name = $1`
+	query := `SELECT id, full_name, phone, gym_id FROM person WHERE full_name = $1 AND gym_id = $2`
 	var person models.Person
-	err := s.db.QueryRow(ctx, query, name).Scan(
+	err := s.db.QueryRow(ctx, query, name, gymID).Scan(
 		&person.Id,
 		&person.Name,
 		&person.Phone,
+		&person.GymID,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -96,12 +103,12 @@ func (s *Storage) FindPersonByName(ctx context.Context, name string) (models.Per
 	return person, nil
 }
 
-func (s *Storage) FindAllPeople(ctx context.Context) ([]models.Person, error) {
+func (s *Storage) FindAllPeople(ctx context.Context, gymID int64) ([]models.Person, error) {
 	const op = "postgres.findAllPeople"
 
-	query := `SELECT * FROM person`
+	query := `SELECT id, full_name, phone, gym_id FROM person WHERE gym_id = $1`
 
-	rows, err := s.db.Query(ctx, query)
+	rows, err := s.db.Query(ctx, query, gymID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, fmt.Errorf("%s: %w", op, storage.ErrPersonNotFound)
@@ -111,3 +118,139 @@ func (s *Storage) FindAllPeople(ctx context.Context) ([]models.Person, error) {
 
 	return pgx.CollectRows(rows, pgx.RowToStructByName[models.Person])
 }
+
+// MergePeople moves the memberships, class bookings and training packages
+// of person fromID to person toID and deletes fromID, all in one transaction.
+// A booking of fromID for a class toID is already booked for is dropped,
+// the kept booking taking the stronger of the two statuses.
+func (s *Storage) MergePeople(ctx context.Context, gymID int64, fromID, toID int) (models.PersonMergeResult, error) {
+	const op = "postgres.mergePeople"
+
+	result := models.PersonMergeResult{PersonID: toID}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return result, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Блокируем обоих клиентов, чтобы им не оформили абонемент во время слияния
+	rows, err := tx.Query(ctx,
+		`SELECT id FROM person WHERE id = ANY($1) AND gym_id = $2 ORDER BY id FOR UPDATE`,
+		[]int{fromID, toID}, gymID,
+	)
+	if err != nil {
+		return result, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return result, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(ids) != 2 {
+		return result, fmt.Errorf("%s: %w", op, storage.ErrPersonNotFound)
+	}
+
+	moved, err := tx.Exec(ctx,
+		`UPDATE person_subscriptions SET person_id = $1 WHERE person_id = $2`,
+		toID, fromID,
+	)
+	if err != nil {
+		return result, fmt.Errorf("%s: %w", op, err)
+	}
+	result.Memberships = int(moved.RowsAffected())
+
+	// Перед удалением пересекающихся записей дубля переносим на оставшуюся
+	// запись более сильный статус, чтобы клиент не потерял место или отметку
+	rows, err = tx.Query(ctx, `
+		SELECT t.id, t.status, f.status
+		FROM class_bookings t
+		JOIN class_bookings f ON f.schedule_id = t.schedule_id AND f.class_date = t.class_date
+		WHERE t.person_id = $1 AND f.person_id = $2`,
+		toID, fromID,
+	)
+	if err != nil {
+		return result, fmt.Errorf("%s: %w", op, err)
+	}
+
+	type bookingConflict struct {
+		id         int
+		keptStatus string
+		dupStatus  string
+	}
+	conflicts, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (bookingConflict, error) {
+		var c bookingConflict
+		err := row.Scan(&c.id, &c.keptStatus, &c.dupStatus)
+		return c, err
+	})
+	if err != nil {
+		return result, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, c := range conflicts {
+		status := strongerBookingStatus(c.keptStatus, c.dupStatus)
+		if status == c.keptStatus {
+			continue
+		}
+		if _, err := tx.Exec(ctx, `UPDATE class_bookings SET status = $1 WHERE id = $2`, status, c.id); err != nil {
+			return result, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	skipped, err := tx.Exec(ctx, `
+		DELETE FROM class_bookings f
+		WHERE f.person_id = $2 AND EXISTS (
+			SELECT 1 FROM class_bookings t
+			WHERE t.person_id = $1 AND t.schedule_id = f.schedule_id AND t.class_date = f.class_date
+		)`,
+		toID, fromID,
+	)
+	if err != nil {
+		return result, fmt.Errorf("%s: %w", op, err)
+	}
+	result.SkippedBookings = int(skipped.RowsAffected())
+
+	moved, err = tx.Exec(ctx,
+		`UPDATE class_bookings SET person_id = $1 WHERE person_id = $2`,
+		toID, fromID,
+	)
+	if err != nil {
+		return result, fmt.Errorf("%s: %w", op, err)
+	}
+	result.Bookings = int(moved.RowsAffected())
+
+	moved, err = tx.Exec(ctx,
+		`UPDATE person_training_packages SET person_id = $1 WHERE person_id = $2`,
+		toID, fromID,
+	)
+	if err != nil {
+		return result, fmt.Errorf("%s: %w", op, err)
+	}
+	result.TrainingPackages = int(moved.RowsAffected())
+
+	if _, err := tx.Exec(ctx, `DELETE FROM person WHERE id = $1`, fromID); err != nil {
+		return result, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return result, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return result, nil
+}
+
+// bookingStatusRank orders booking statuses from the weakest to the strongest.
+var bookingStatusRank = map[string]int{
+	"cancelled": 1,
+	"booked":    2,
+	"attended":  3,
+}
+
+// strongerBookingStatus returns the status that wins when two bookings
+// of the same class are merged: attended > booked > cancelled.
+func strongerBookingStatus(a, b string) string {
+	if bookingStatusRank[b] > bookingStatusRank[a] {
+		return b
+	}
+	return a
+}
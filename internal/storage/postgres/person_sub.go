@@ -10,12 +10,18 @@ import (
 	"gym_app/internal/storage"
 )
 
-func (s *Storage) AddPersonSub(ctx context.Context, personSub models.PersonSubscription) (string, error) {
+const personSubColumns = `ps.number, ps.person_id, ps.subscription_id, ps.start_date, ps.end_date, ps.status, ps.gym_id`
+
+func (s *Storage) AddPersonSub(ctx context.Context, gymID int64, personSub models.PersonSubscription) (string, error) {
 	const op = "storage.postgres.AddPersonSub"
 
+	// Клиент должен принадлежать залу, а тариф - действовать в нем
 	query := `
-		INSERT INTO person_subscriptions (number, person_id, subscription_id, start_date, end_date, status)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO person_subscriptions (number, person_id, subscription_id, start_date, end_date, status, gym_id)
+		SELECT $1, p.id, s.id, $4, $5, $6, p.gym_id
+		FROM person p, subscriptions s
+		WHERE p.id = $2 AND p.gym_id = $7
+		  AND s.id = $3 AND (s.gym_id = $7 OR s.gym_id IS NULL)
 		RETURNING number
 	`
 
@@ -27,9 +33,13 @@ func (s *Storage) AddPersonSub(ctx context.Context, personSub models.PersonSubsc
 		personSub.StartDate,
 		personSub.EndDate,
 		personSub.Status,
+		gymID,
 	).Scan(&number)
 
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", fmt.Errorf("%s: %w", op, storage.ErrPersonNotFound)
+		}
 
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) {
@@ -47,34 +57,43 @@ func (s *Storage) AddPersonSub(ctx context.Context, personSub models.PersonSubsc
 	return number, nil
 }
 
-func (s *Storage) GetPersonSubByNumber(ctx context.Context, number string) (models.PersonSubscription, error) {
+func (s *Storage) GetPersonSubByNumber(ctx context.Context, gymID int64, number string) (models.PersonSubscription, error) {
 	const op = "storage.postgres.FindPersonSubByNumber"
 
-	query := `SELECT * FROM person_subscriptions WHERE number = $1`
+	// Отозванные номера карт указывают на тот же абонемент
+	query := `
+		SELECT ` + personSubColumns + ` FROM person_subscriptions ps
+		WHERE ps.gym_id = $2
+		  AND (ps.number = $1 OR ps.number = (SELECT h.current_number FROM card_number_history h WHERE h.number = $1))
+	`
 
 	var personSub models.PersonSubscription
-	err := s.db.QueryRow(ctx, query, number).Scan(
+	err := s.db.QueryRow(ctx, query, number, gymID).Scan(
 		&personSub.Number,
 		&personSub.PersonID,
 		&personSub.SubscriptionID,
 		&personSub.StartDate,
 		&personSub.EndDate,
 		&personSub.Status,
+		&personSub.GymID,
 	)
 
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.PersonSubscription{}, fmt.Errorf("%s: %w", op, storage.ErrSubscriptionNotFound)
+		}
 		return models.PersonSubscription{}, fmt.Errorf("%s: %w", op, err)
 	}
 
 	return personSub, nil
 }
 
-func (s *Storage) DeletePersonSub(ctx context.Context, number string) error {
+func (s *Storage) DeletePersonSub(ctx context.Context, gymID int64, number string) error {
 	const op = "storage.postgres.DeletePersonSub"
 
-	query := `DELETE FROM person_subscriptions WHERE number = $1`
+	query := `DELETE FROM person_subscriptions WHERE number = $1 AND gym_id = $2`
 
-	result, err := s.db.Exec(ctx, query, number)
+	result, err := s.db.Exec(ctx, query, number, gymID)
 	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
@@ -86,12 +105,12 @@ func (s *Storage) DeletePersonSub(ctx context.Context, number string) error {
 	return nil
 }
 
-func (s *Storage) GetAllPersonSubs(ctx context.Context) ([]models.PersonSubscription, error) {
+func (s *Storage) GetAllPersonSubs(ctx context.Context, gymID int64) ([]models.PersonSubscription, error) {
 	const op = "storage.postgres.GetAllPersonSubs"
 
-	query := `SELECT * FROM person_subscriptions`
+	query := `SELECT ` + personSubColumns + ` FROM person_subscriptions ps WHERE ps.gym_id = $1`
 
-	rows, err := s.db.Query(ctx, query)
+	rows, err := s.db.Query(ctx, query, gymID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, fmt.Errorf("%s: %w", op, storage.ErrPersonNotFound)
@@ -102,16 +121,51 @@ func (s *Storage) GetAllPersonSubs(ctx context.Context) ([]models.PersonSubscrip
 	return pgx.CollectRows(rows, pgx.RowToStructByName[models.PersonSubscription])
 }
 
-func (s *Storage) FindPersonSubByPersonName(ctx context.Context, name string) ([]models.PersonSubscription, error) {
+// GetPersonSubsAcrossGyms is used by background jobs which process every branch.
+func (s *Storage) GetPersonSubsAcrossGyms(ctx context.Context) ([]models.PersonSubscription, error) {
+	const op = "storage.postgres.GetPersonSubsAcrossGyms"
+
+	query := `SELECT ` + personSubColumns + ` FROM person_subscriptions ps`
+
+	rows, err := s.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.PersonSubscription])
+}
+
+// CountPersonSubsByStatus counts memberships of all gyms by status.
+func (s *Storage) CountPersonSubsByStatus(ctx context.Context) ([]models.PersonSubStatusCount, error) {
+	const op = "storage.postgres.CountPersonSubsByStatus"
+
+	query := `SELECT gym_id, status, COUNT(*) AS count
+		FROM person_subscriptions
+		GROUP BY gym_id, status`
+
+	rows, err := s.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	counts, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.PersonSubStatusCount])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return counts, nil
+}
+
+func (s *Storage) FindPersonSubByPersonName(ctx context.Context, gymID int64, name string) ([]models.PersonSubscription, error) {
 	const op = "storage.postgres.FindPersonSubByPersonName"
 
 	query := `
-		SELECT ps.* FROM person_subscriptions ps
+		SELECT ` + personSubColumns + ` FROM person_subscriptions ps
 		JOIN person p ON ps.person_id = p.id
-		WHERE p.full_name = $1
+		WHERE p.full_name = $1 AND ps.gym_id = $2
 	`
 
-	rows, err := s.db.Query(ctx, query, name)
+	rows, err := s.db.Query(ctx, query, name, gymID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, fmt.Errorf("%s: %w", op, storage.ErrPersonNotFound)
@@ -122,6 +176,162 @@ func (s *Storage) FindPersonSubByPersonName(ctx context.Context, name string) ([
 	return pgx.CollectRows(rows, pgx.RowToStructByName[models.PersonSubscription])
 }
 
+// UpdatePersonSub replaces the plan, dates and status of the person
+// subscription with the given number. If personSub.Number differs, the card
+// is replaced in the same transaction as by ReplaceCard, keeping the old
+// number in the history.
+func (s *Storage) UpdatePersonSub(ctx context.Context, gymID int64, number string, personSub models.PersonSubscription, reason string) error {
+	const op = "storage.postgres.UpdatePersonSub"
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	if personSub.Number != number {
+		if err := replaceCard(ctx, tx, gymID, number, personSub.Number, reason); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	query := `
+		UPDATE person_subscriptions
+		SET subscription_id = $1, start_date = $2, end_date = $3, status = $4
+		WHERE number = $5 AND gym_id = $6
+	`
+
+	result, err := tx.Exec(ctx, query,
+		personSub.SubscriptionID,
+		personSub.StartDate,
+		personSub.EndDate,
+		personSub.Status,
+		personSub.Number,
+		gymID,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return fmt.Errorf("%s: %w", op, storage.ErrPlanNotFound)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrSubscriptionNotFound)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ReplaceCard moves a person subscription to a new card number and keeps
+// the old one in the history.
+func (s *Storage) ReplaceCard(ctx context.Context, gymID int64, number, newNumber, reason string) error {
+	const op = "storage.postgres.ReplaceCard"
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := replaceCard(ctx, tx, gymID, number, newNumber, reason); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func replaceCard(ctx context.Context, tx pgx.Tx, gymID int64, number, newNumber, reason string) error {
+	const op = "storage.postgres.replaceCard"
+
+	// Прежние записи истории переходят на новый номер через ON UPDATE CASCADE
+	result, err := tx.Exec(ctx,
+		`UPDATE person_subscriptions SET number = $1 WHERE number = $2 AND gym_id = $3`,
+		newNumber, number, gymID,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return fmt.Errorf("%s: %w", op, storage.ErrSubscriptionExists)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrSubscriptionNotFound)
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO card_number_history (number, current_number, gym_id, reason) VALUES ($1, $2, $3, $4)`,
+		number, newNumber, gymID, reason,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// FindCardHistory returns the revoked numbers of the subscription the given
+// number (current or revoked) belongs to.
+func (s *Storage) FindCardHistory(ctx context.Context, gymID int64, number string) ([]models.CardNumberChange, error) {
+	const op = "storage.postgres.FindCardHistory"
+
+	query := `
+		SELECT h.number, h.current_number, h.reason, h.revoked_at
+		FROM card_number_history h
+		WHERE h.gym_id = $2 AND h.current_number = COALESCE(
+			(SELECT current_number FROM card_number_history WHERE number = $1), $1
+		)
+		ORDER BY h.revoked_at
+	`
+
+	rows, err := s.db.Query(ctx, query, number, gymID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.CardNumberChange])
+}
+
+// IsCardRevoked reports whether the number belongs to a replaced card.
+func (s *Storage) IsCardRevoked(ctx context.Context, number string) (bool, error) {
+	const op = "storage.postgres.IsCardRevoked"
+
+	var revoked bool
+	err := s.db.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM card_number_history WHERE number = $1)`,
+		number,
+	).Scan(&revoked)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return revoked, nil
+}
+
+func (s *Storage) NextCardSequence(ctx context.Context) (int64, error) {
+	const op = "storage.postgres.NextCardSequence"
+
+	var seq int64
+	if err := s.db.QueryRow(ctx, `SELECT nextval('card_number_seq')`).Scan(&seq); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return seq, nil
+}
+
 func (s *Storage) UpdatePersonSubStatus(ctx context.Context, number string, status string) error {
 	const op = "storage.postgres.UpdatePersonSubStatus"
 
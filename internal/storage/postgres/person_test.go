@@ -0,0 +1,26 @@
+package postgres
+
+import "testing"
+
+func TestStrongerBookingStatus(t *testing.T) {
+	tests := []struct {
+		kept, dup string
+		want      string
+	}{
+		// Живая запись дубля не должна пропадать из-за отмененной у оставшегося
+		{"cancelled", "booked", "booked"},
+		{"cancelled", "attended", "attended"},
+		{"booked", "attended", "attended"},
+		{"booked", "cancelled", "booked"},
+		{"attended", "booked", "attended"},
+		{"attended", "cancelled", "attended"},
+		{"booked", "booked", "booked"},
+		{"cancelled", "cancelled", "cancelled"},
+	}
+
+	for _, tt := range tests {
+		if got := strongerBookingStatus(tt.kept, tt.dup); got != tt.want {
+			t.Errorf("strongerBookingStatus(%q, %q) = %q, want %q", tt.kept, tt.dup, got, tt.want)
+		}
+	}
+}
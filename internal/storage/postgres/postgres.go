@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"github.com/exaring/otelpgx"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"gym_app/internal/config"
 )
@@ -23,10 +24,44 @@ func New(ctx context.Context, cfg config.DB) (*Storage, error) {
 		cfg.DBName,
 	)
 
-	db, err := pgxpool.New(ctx, dsn)
+	poolCfg, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
+	poolCfg.ConnConfig.Tracer = otelpgx.NewTracer()
+
+	db, err := pgxpool.NewWithConfig(ctx, poolCfg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// The pool connects lazily, fail at startup if the database is down
+	if err := db.Ping(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
 	return &Storage{db: db}, nil
 }
+
+// Ping checks that a connection to the database can be acquired and used.
+func (s *Storage) Ping(ctx context.Context) error {
+	const op = "storage.postgres.Ping"
+
+	if err := s.db.Ping(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Close waits for acquired connections to be released and closes the pool.
+func (s *Storage) Close() {
+	s.db.Close()
+}
+
+// PoolStat returns a snapshot of the connection pool statistics.
+func (s *Storage) PoolStat() *pgxpool.Stat {
+	return s.db.Stat()
+}
@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gym_app/internal/models"
+	"gym_app/internal/storage"
+)
+
+func (s *Storage) SaveStaff(ctx context.Context, staff models.Staff) (int64, error) {
+	const op = "storage.postgres.SaveStaff"
+
+	query := `INSERT INTO staff(user_id, full_name, position, gym_id) VALUES($1, $2, $3, $4) RETURNING id`
+
+	var staffID int64
+	err := s.db.QueryRow(ctx, query, staff.UserID, staff.FullName, staff.Position, staff.GymID).Scan(&staffID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, staffError(err))
+	}
+
+	return staffID, nil
+}
+
+func (s *Storage) UpdateStaff(ctx context.Context, staff models.Staff, staffID int64) error {
+	const op = "storage.postgres.UpdateStaff"
+
+	query := `UPDATE staff SET user_id = $1, full_name = $2, position = $3, gym_id = $4 WHERE id = $5`
+
+	result, err := s.db.Exec(ctx, query, staff.UserID, staff.FullName, staff.Position, staff.GymID, staffID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, staffError(err))
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrStaffNotFound)
+	}
+
+	return nil
+}
+
+func (s *Storage) DeleteStaff(ctx context.Context, staffID int64) error {
+	const op = "storage.postgres.DeleteStaff"
+
+	query := `DELETE FROM staff WHERE id = $1`
+
+	result, err := s.db.Exec(ctx, query, staffID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrStaffNotFound)
+	}
+
+	return nil
+}
+
+// FindAllStaff returns staff of the given gym, or of every gym when gymID is 0.
+func (s *Storage) FindAllStaff(ctx context.Context, gymID int64) ([]models.Staff, error) {
+	const op = "storage.postgres.FindAllStaff"
+
+	query := `
+		SELECT id, user_id, full_name, position, gym_id FROM staff
+		WHERE $1 = 0 OR gym_id = $1
+		ORDER BY full_name
+	`
+
+	rows, err := s.db.Query(ctx, query, gymID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.Staff])
+}
+
+func (s *Storage) FindStaffByUserID(ctx context.Context, userID int64) (models.Staff, error) {
+	const op = "storage.postgres.FindStaffByUserID"
+
+	query := `SELECT id, user_id, full_name, position, gym_id FROM staff WHERE user_id = $1`
+
+	var staff models.Staff
+	err := s.db.QueryRow(ctx, query, userID).Scan(
+		&staff.ID,
+		&staff.UserID,
+		&staff.FullName,
+		&staff.Position,
+		&staff.GymID,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Staff{}, fmt.Errorf("%s: %w", op, storage.ErrStaffNotFound)
+		}
+
+		return models.Staff{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return staff, nil
+}
+
+func staffError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505":
+			return storage.ErrStaffExists
+		case "23503":
+			return storage.ErrGymNotFound
+		}
+	}
+
+	return err
+}
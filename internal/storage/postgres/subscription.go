@@ -9,15 +9,21 @@ import (
 	"gym_app/internal/storage"
 )
 
+// Tariffs with an empty gym_id are valid in every branch, so they are visible
+// from any gym alongside the gym's own tariffs. Writes only match the scope
+// of the tariff: a branch changes its own tariffs, the all gyms ones are
+// changed with AllGyms set, and a tariff never moves between the two.
+
 func (s *Storage) SaveSubscription(
 	ctx context.Context,
+	gymID int64,
 	subscription models.Subscription,
 ) (int, error) {
 	const op = "postgres.addSubscription"
 
-	query := `INSERT INTO subscriptions(title, price, duration_days, freeze_days) VALUES($1, $2, $3, $4) RETURNING id`
+	query := `INSERT INTO subscriptions(title, price, duration_days, freeze_days, gym_id) VALUES($1, $2, $3, $4, $5) RETURNING id`
 
-	row := s.db.QueryRow(ctx, query, subscription.Title, subscription.Price, subscription.DurationDays, subscription.FreezeDays)
+	row := s.db.QueryRow(ctx, query, subscription.Title, subscription.Price, subscription.DurationDays, subscription.FreezeDays, subscriptionGymID(gymID, subscription))
 
 	var subId int
 	if err := row.Scan(&subId); err != nil {
@@ -29,17 +35,25 @@ func (s *Storage) SaveSubscription(
 
 func (s *Storage) UpdateSubscription(
 	ctx context.Context,
+	gymID int64,
 	subscription models.Subscription,
 	subID int,
 ) (int, error) {
 	const op = "postgres.updateSubscription"
 
-	query := `UPDATE subscriptions SET title = $1, price = $2, duration_days = $3, freeze_days = $4 WHERE id = $5 RETURNING id`
+	query := `
+		UPDATE subscriptions SET title = $1, price = $2, duration_days = $3, freeze_days = $4, gym_id = $5
+		WHERE id = $6 AND gym_id IS NOT DISTINCT FROM $5
+		RETURNING id
+	`
 
-	row := s.db.QueryRow(ctx, query, subscription.Title, subscription.Price, subscription.DurationDays, subscription.FreezeDays, subID)
+	row := s.db.QueryRow(ctx, query, subscription.Title, subscription.Price, subscription.DurationDays, subscription.FreezeDays, subscriptionGymID(gymID, subscription), subID)
 
 	var subId int
 	if err := row.Scan(&subId); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrSubscriptionNotFound)
+		}
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -48,46 +62,101 @@ func (s *Storage) UpdateSubscription(
 
 func (s *Storage) DeleteSubscription(
 	ctx context.Context,
+	gymID int64,
 	subID int,
+	allGyms bool,
 ) error {
 	const op = "postgres.deleteSubscription"
 
-	query := `DELETE FROM subscriptions WHERE id = $1`
+	query := `DELETE FROM subscriptions WHERE id = $1 AND gym_id IS NOT DISTINCT FROM $2`
 
-	_, err := s.db.Exec(ctx, query, subID)
+	result, err := s.db.Exec(ctx, query, subID, subscriptionGymID(gymID, models.Subscription{AllGyms: allGyms}))
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return fmt.Errorf("%s: %w", op, storage.ErrPersonNotFound)
-		}
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrSubscriptionNotFound)
+	}
+
 	return nil
 }
 
-func (s *Storage) FindAllSubscriptions(ctx context.Context) ([]models.Subscription, error) {
+func (s *Storage) FindAllSubscriptions(ctx context.Context, gymID int64) ([]models.Subscription, error) {
 	const op = "postgres.FindAllSubscriptions"
 
-	query := `SELECT * FROM subscriptions`
+	query := `
+		SELECT id, title, price, duration_days, freeze_days, gym_id FROM subscriptions
+		WHERE gym_id = $1 OR gym_id IS NULL
+	`
 
-	rows, err := s.db.Query(ctx, query)
+	rows, err := s.db.Query(ctx, query, gymID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, fmt.Errorf("%s: %w", op, storage.ErrPersonNotFound)
 		}
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+	defer rows.Close()
 
 	var subs []models.Subscription
 	for rows.Next() {
 		sub := models.Subscription{}
-		err := rows.Scan(&sub.ID, &sub.Title, &sub.Price, &sub.DurationDays, &sub.FreezeDays)
+		var subGymID *int64
+		err := rows.Scan(&sub.ID, &sub.Title, &sub.Price, &sub.DurationDays, &sub.FreezeDays, &subGymID)
 
 		if err != nil {
 			return nil, fmt.Errorf("unable to scan row: %w", err)
 		}
+
+		if subGymID == nil {
+			sub.AllGyms = true
+		} else {
+			sub.GymID = *subGymID
+		}
+
 		subs = append(subs, sub)
 	}
 
 	return subs, nil
 }
+
+// FindSubscriptionByID returns a plan available in the gym.
+func (s *Storage) FindSubscriptionByID(ctx context.Context, gymID int64, subscriptionID int64) (models.Subscription, error) {
+	const op = "postgres.FindSubscriptionByID"
+
+	query := `
+		SELECT id, title, price, duration_days, freeze_days, gym_id FROM subscriptions
+		WHERE id = $1 AND (gym_id = $2 OR gym_id IS NULL)
+	`
+
+	var (
+		sub      models.Subscription
+		subGymID *int64
+	)
+	err := s.db.QueryRow(ctx, query, subscriptionID, gymID).Scan(
+		&sub.ID, &sub.Title, &sub.Price, &sub.DurationDays, &sub.FreezeDays, &subGymID,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Subscription{}, fmt.Errorf("%s: %w", op, storage.ErrPlanNotFound)
+		}
+		return models.Subscription{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if subGymID == nil {
+		sub.AllGyms = true
+	} else {
+		sub.GymID = *subGymID
+	}
+
+	return sub, nil
+}
+
+func subscriptionGymID(gymID int64, subscription models.Subscription) *int64 {
+	if subscription.AllGyms {
+		return nil
+	}
+
+	return &gymID
+}
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gym_app/internal/models"
+	"gym_app/internal/storage"
+)
+
+func (s *Storage) SaveTrainer(ctx context.Context, gymID int64, trainer models.Trainer) (int64, error) {
+	const op = "storage.postgres.SaveTrainer"
+
+	query := `INSERT INTO trainers(full_name, phone, staff_id, gym_id) VALUES($1, $2, $3, $4) RETURNING id`
+
+	var trainerID int64
+	err := s.db.QueryRow(ctx, query, trainer.FullName, trainer.Phone, trainer.StaffID, gymID).Scan(&trainerID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, trainerError(err))
+	}
+
+	return trainerID, nil
+}
+
+func (s *Storage) UpdateTrainer(ctx context.Context, gymID int64, trainer models.Trainer, trainerID int64) error {
+	const op = "storage.postgres.UpdateTrainer"
+
+	query := `UPDATE trainers SET full_name = $1, phone = $2, staff_id = $3 WHERE id = $4 AND gym_id = $5`
+
+	result, err := s.db.Exec(ctx, query, trainer.FullName, trainer.Phone, trainer.StaffID, trainerID, gymID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, trainerError(err))
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrTrainerNotFound)
+	}
+
+	return nil
+}
+
+func (s *Storage) DeleteTrainer(ctx context.Context, gymID int64, trainerID int64) error {
+	const op = "storage.postgres.DeleteTrainer"
+
+	query := `DELETE FROM trainers WHERE id = $1 AND gym_id = $2`
+
+	result, err := s.db.Exec(ctx, query, trainerID, gymID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return fmt.Errorf("%s: %w", op, storage.ErrTrainerInUse)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrTrainerNotFound)
+	}
+
+	return nil
+}
+
+func (s *Storage) FindAllTrainers(ctx context.Context, gymID int64) ([]models.Trainer, error) {
+	const op = "storage.postgres.FindAllTrainers"
+
+	query := `SELECT id, full_name, phone, staff_id, gym_id FROM trainers WHERE gym_id = $1 ORDER BY full_name`
+
+	rows, err := s.db.Query(ctx, query, gymID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.Trainer])
+}
+
+func trainerError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505":
+			// staff_id is unique: one staff member can be only one trainer
+			return storage.ErrStaffExists
+		case "23503":
+			return storage.ErrStaffNotFound
+		}
+	}
+
+	return err
+}
+
+func (s *Storage) FindTrainerIDByStaffID(ctx context.Context, gymID, staffID int64) (int64, error) {
+	const op = "storage.postgres.FindTrainerIDByStaffID"
+
+	var trainerID int64
+	err := s.db.QueryRow(ctx,
+		`SELECT id FROM trainers WHERE staff_id = $1 AND gym_id = $2`,
+		staffID, gymID,
+	).Scan(&trainerID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrTrainerNotFound)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return trainerID, nil
+}
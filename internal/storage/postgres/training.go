@@ -0,0 +1,333 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gym_app/internal/models"
+	"gym_app/internal/storage"
+	"time"
+)
+
+func (s *Storage) SaveTrainingPackage(ctx context.Context, gymID int64, pkg models.TrainingPackage) (int64, error) {
+	const op = "storage.postgres.SaveTrainingPackage"
+
+	query := `
+		INSERT INTO training_packages (title, sessions, session_minutes, validity_days, price, gym_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+
+	var pkgID int64
+	err := s.db.QueryRow(ctx, query, pkg.Title, pkg.Sessions, pkg.SessionMinutes, pkg.ValidityDays, pkg.Price, gymID).Scan(&pkgID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pkgID, nil
+}
+
+func (s *Storage) UpdateTrainingPackage(ctx context.Context, gymID int64, pkg models.TrainingPackage, pkgID int64) error {
+	const op = "storage.postgres.UpdateTrainingPackage"
+
+	// Уже проданные пакеты не меняются: количество тренировок и срок копируются при продаже
+	query := `
+		UPDATE training_packages
+		SET title = $1, sessions = $2, session_minutes = $3, validity_days = $4, price = $5
+		WHERE id = $6 AND gym_id = $7
+	`
+
+	result, err := s.db.Exec(ctx, query, pkg.Title, pkg.Sessions, pkg.SessionMinutes, pkg.ValidityDays, pkg.Price, pkgID, gymID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrTrainingPkgNotFound)
+	}
+
+	return nil
+}
+
+func (s *Storage) DeleteTrainingPackage(ctx context.Context, gymID int64, pkgID int64) error {
+	const op = "storage.postgres.DeleteTrainingPackage"
+
+	result, err := s.db.Exec(ctx, `DELETE FROM training_packages WHERE id = $1 AND gym_id = $2`, pkgID, gymID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return fmt.Errorf("%s: %w", op, storage.ErrTrainingPkgInUse)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrTrainingPkgNotFound)
+	}
+
+	return nil
+}
+
+func (s *Storage) FindAllTrainingPackages(ctx context.Context, gymID int64) ([]models.TrainingPackage, error) {
+	const op = "storage.postgres.FindAllTrainingPackages"
+
+	query := `
+		SELECT id, title, sessions, session_minutes, validity_days, price, gym_id
+		FROM training_packages
+		WHERE gym_id = $1
+		ORDER BY title
+	`
+
+	rows, err := s.db.Query(ctx, query, gymID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.TrainingPackage])
+}
+
+// SellTrainingPackage copies the number of sessions and the validity period
+// of the package to the client, so later changes of the package do not
+// affect packages already sold.
+func (s *Storage) SellTrainingPackage(
+	ctx context.Context,
+	gymID, personID, pkgID, trainerID int64,
+	startDate time.Time,
+) (int64, error) {
+	const op = "storage.postgres.SellTrainingPackage"
+
+	query := `
+		INSERT INTO person_training_packages
+			(person_id, package_id, trainer_id, sessions_total, start_date, expiry_date, gym_id)
+		SELECT p.id, tp.id, t.id, tp.sessions, $4::date, $4::date + tp.validity_days, $5
+		FROM person p, training_packages tp, trainers t
+		WHERE p.id = $1 AND p.gym_id = $5
+		  AND tp.id = $2 AND tp.gym_id = $5
+		  AND t.id = $3 AND t.gym_id = $5
+		RETURNING id
+	`
+
+	var personPkgID int64
+	err := s.db.QueryRow(ctx, query, personID, pkgID, trainerID, startDate, gymID).Scan(&personPkgID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrTrainingPkgNotFound)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return personPkgID, nil
+}
+
+func (s *Storage) FindPersonTrainingPackages(ctx context.Context, gymID, personID int64) ([]models.PersonTrainingPackage, error) {
+	const op = "storage.postgres.FindPersonTrainingPackages"
+
+	query := `
+		SELECT id, person_id, package_id, trainer_id, sessions_total, sessions_used,
+			to_char(start_date, 'DD-MM-YYYY') AS start_date,
+			to_char(expiry_date, 'DD-MM-YYYY') AS expiry_date,
+			gym_id
+		FROM person_training_packages
+		WHERE person_id = $1 AND gym_id = $2
+		ORDER BY start_date DESC
+	`
+
+	rows, err := s.db.Query(ctx, query, personID, gymID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.PersonTrainingPackage])
+}
+
+// BookTrainingSession books a session from a client's package. Sessions
+// already booked count against the package until they are cancelled.
+func (s *Storage) BookTrainingSession(ctx context.Context, gymID, personPkgID int64, scheduledAt time.Time) (int64, error) {
+	const op = "storage.postgres.BookTrainingSession"
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var (
+		trainerID           int64
+		total, used, booked int
+		minutes             int
+		startDate           time.Time
+		expiryDate          time.Time
+	)
+	err = tx.QueryRow(ctx, `
+		SELECT ptp.trainer_id, ptp.sessions_total, ptp.sessions_used, ptp.start_date, ptp.expiry_date, tp.session_minutes,
+			(SELECT count(*) FROM training_sessions ts WHERE ts.person_package_id = ptp.id AND ts.status = 'booked')::int
+		FROM person_training_packages ptp
+		JOIN training_packages tp ON tp.id = ptp.package_id
+		WHERE ptp.id = $1 AND ptp.gym_id = $2
+		FOR UPDATE OF ptp`,
+		personPkgID, gymID,
+	).Scan(&trainerID, &total, &used, &startDate, &expiryDate, &minutes, &booked)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrPersonPkgNotFound)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	day := sessionDay(scheduledAt)
+
+	if day.Before(startDate) {
+		return 0, fmt.Errorf("%s: %w", op, storage.ErrPackageNotStarted)
+	}
+
+	if day.After(expiryDate) {
+		return 0, fmt.Errorf("%s: %w", op, storage.ErrPackageExpired)
+	}
+
+	if used+booked >= total {
+		return 0, fmt.Errorf("%s: %w", op, storage.ErrNoSessionsLeft)
+	}
+
+	// Бронирования к одному тренеру идут по очереди, иначе два клиента
+	// могут одновременно пройти проверку на пересечение
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM trainers WHERE id = $1 FOR UPDATE`, trainerID); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var busy bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM training_sessions ts
+			JOIN person_training_packages ptp ON ptp.id = ts.person_package_id
+			WHERE ptp.trainer_id = $1 AND ts.status = 'booked'
+			  AND ts.scheduled_at < $2::timestamptz + make_interval(mins => $3)
+			  AND ts.scheduled_at + make_interval(mins => ts.duration_minutes) > $2::timestamptz
+		)`,
+		trainerID, scheduledAt, minutes,
+	).Scan(&busy)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if busy {
+		return 0, fmt.Errorf("%s: %w", op, storage.ErrTrainerBusy)
+	}
+
+	var sessionID int64
+	err = tx.QueryRow(ctx,
+		`INSERT INTO training_sessions (person_package_id, scheduled_at, duration_minutes) VALUES ($1, $2, $3) RETURNING id`,
+		personPkgID, scheduledAt, minutes,
+	).Scan(&sessionID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return sessionID, nil
+}
+
+func (s *Storage) CancelTrainingSession(ctx context.Context, gymID, sessionID int64) error {
+	const op = "storage.postgres.CancelTrainingSession"
+
+	query := `
+		UPDATE training_sessions ts SET status = 'cancelled'
+		FROM person_training_packages ptp
+		WHERE ts.id = $1 AND ptp.id = ts.person_package_id AND ptp.gym_id = $2 AND ts.status = 'booked'
+	`
+
+	result, err := s.db.Exec(ctx, query, sessionID, gymID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrSessionNotFound)
+	}
+
+	return nil
+}
+
+// CompleteTrainingSession marks a session as held and consumes it from the package.
+func (s *Storage) CompleteTrainingSession(ctx context.Context, gymID, sessionID int64) error {
+	const op = "storage.postgres.CompleteTrainingSession"
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var personPkgID int64
+	err = tx.QueryRow(ctx, `
+		UPDATE training_sessions ts SET status = 'completed'
+		FROM person_training_packages ptp
+		WHERE ts.id = $1 AND ptp.id = ts.person_package_id AND ptp.gym_id = $2 AND ts.status = 'booked'
+		RETURNING ts.person_package_id`,
+		sessionID, gymID,
+	).Scan(&personPkgID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%s: %w", op, storage.ErrSessionNotFound)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE person_training_packages SET sessions_used = sessions_used + 1 WHERE id = $1`,
+		personPkgID,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23514" {
+			return fmt.Errorf("%s: %w", op, storage.ErrNoSessionsLeft)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// FindUpcomingTrainingSessions returns booked sessions starting from now,
+// for one trainer or for every trainer of the gym when trainerID is 0.
+func (s *Storage) FindUpcomingTrainingSessions(ctx context.Context, gymID, trainerID int64) ([]models.TrainingSession, error) {
+	const op = "storage.postgres.FindUpcomingTrainingSessions"
+
+	query := `
+		SELECT ts.id, ts.person_package_id, ptp.person_id, ptp.trainer_id, ts.scheduled_at, ts.duration_minutes, ts.status
+		FROM training_sessions ts
+		JOIN person_training_packages ptp ON ptp.id = ts.person_package_id
+		WHERE ptp.gym_id = $1 AND ($2 = 0 OR ptp.trainer_id = $2)
+		  AND ts.status = 'booked' AND ts.scheduled_at >= now()
+		ORDER BY ts.scheduled_at
+	`
+
+	rows, err := s.db.Query(ctx, query, gymID, trainerID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[models.TrainingSession])
+}
+
+// sessionDay returns the calendar date of a session in the offset it was
+// booked with, i.e. the local time of the branch, as a UTC midnight like
+// the DATE columns are scanned. Comparing the instant itself with those
+// midnights would shift the package bounds by the UTC offset.
+func sessionDay(scheduledAt time.Time) time.Time {
+	y, m, d := scheduledAt.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,31 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionDay(t *testing.T) {
+	vladivostok := time.FixedZone("UTC+10", 10*60*60)
+	newYork := time.FixedZone("UTC-5", -5*60*60)
+
+	tests := []struct {
+		name        string
+		scheduledAt time.Time
+		want        time.Time
+	}{
+		// 09:00 по местному времени — 23:00 UTC предыдущего дня
+		{"east of UTC", time.Date(2025, 3, 10, 9, 0, 0, 0, vladivostok), time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"west of UTC", time.Date(2025, 3, 10, 21, 0, 0, 0, newYork), time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"utc", time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"just before midnight", time.Date(2025, 3, 10, 23, 59, 0, 0, vladivostok), time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sessionDay(tt.scheduledAt); !got.Equal(tt.want) {
+				t.Errorf("sessionDay(%v) = %v, want %v", tt.scheduledAt, got, tt.want)
+			}
+		})
+	}
+}
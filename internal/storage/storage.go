@@ -7,5 +7,31 @@ var (
 	ErrSubscriptionExists   = errors.New("subscription with that number already exists")
 	ErrPersonNotFound       = errors.New("person not found")
 	ErrSubscriptionNotFound = errors.New("subscription not found")
+	ErrPlanNotFound         = errors.New("subscription plan not found")
+	ErrCardRevoked          = errors.New("card number has been revoked")
 	ErrAppNotFound          = errors.New("app not found")
+	ErrGymExists            = errors.New("gym already exists")
+	ErrGymNotFound          = errors.New("gym not found")
+	ErrGymInUse             = errors.New("gym has related records")
+	ErrStaffExists          = errors.New("staff member already exists")
+	ErrStaffNotFound        = errors.New("staff member not found")
+	ErrTrainerNotFound      = errors.New("trainer not found")
+	ErrTrainerInUse         = errors.New("trainer has scheduled classes")
+	ErrClassTypeExists      = errors.New("class type already exists")
+	ErrClassTypeNotFound    = errors.New("class type not found")
+	ErrClassTypeInUse       = errors.New("class type has scheduled classes")
+	ErrScheduleNotFound     = errors.New("scheduled class not found")
+	ErrWrongClassDate       = errors.New("class is not held on that date")
+	ErrNoActiveMembership   = errors.New("person has no active membership")
+	ErrClassFull            = errors.New("class is full")
+	ErrAlreadyBooked        = errors.New("person is already booked")
+	ErrBookingNotFound      = errors.New("booking not found")
+	ErrTrainingPkgNotFound  = errors.New("training package not found")
+	ErrTrainingPkgInUse     = errors.New("training package has been sold")
+	ErrPersonPkgNotFound    = errors.New("person training package not found")
+	ErrPackageExpired       = errors.New("training package expired")
+	ErrPackageNotStarted    = errors.New("training package has not started")
+	ErrNoSessionsLeft       = errors.New("no training sessions left")
+	ErrTrainerBusy          = errors.New("trainer is busy at that time")
+	ErrSessionNotFound      = errors.New("training session not found")
 )
@@ -0,0 +1,11 @@
+ALTER TABLE person_subscriptions DROP COLUMN IF EXISTS gym_id;
+
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS gym_id;
+
+ALTER TABLE person DROP CONSTRAINT IF EXISTS person_gym_id_full_name_phone_key;
+ALTER TABLE person DROP COLUMN IF EXISTS gym_id;
+ALTER TABLE person ADD CONSTRAINT person_full_name_phone_key UNIQUE (full_name, phone);
+
+DROP TABLE IF EXISTS gym_users CASCADE;
+
+DROP TABLE IF EXISTS gyms CASCADE;
@@ -0,0 +1,33 @@
+-- Таблица залов (филиалов)
+CREATE TABLE gyms (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    address TEXT NOT NULL DEFAULT ''
+);
+
+-- Существующие данные переносим в основной зал
+INSERT INTO gyms (name) VALUES ('Основной зал');
+
+-- Привязка пользователей SSO к залу
+CREATE TABLE gym_users (
+    user_id BIGINT PRIMARY KEY,
+    gym_id BIGINT NOT NULL REFERENCES gyms(id) ON DELETE CASCADE
+);
+
+-- Клиенты принадлежат залу
+ALTER TABLE person ADD COLUMN gym_id BIGINT REFERENCES gyms(id) ON DELETE RESTRICT;
+UPDATE person SET gym_id = (SELECT min(id) FROM gyms);
+ALTER TABLE person ALTER COLUMN gym_id SET NOT NULL;
+ALTER TABLE person DROP CONSTRAINT person_full_name_phone_key;
+ALTER TABLE person ADD CONSTRAINT person_gym_id_full_name_phone_key UNIQUE (gym_id, full_name, phone);
+
+-- Тариф без зала действует во всех филиалах
+ALTER TABLE subscriptions ADD COLUMN gym_id BIGINT REFERENCES gyms(id) ON DELETE RESTRICT;
+
+-- Абонементы клиентов оформляются в зале
+ALTER TABLE person_subscriptions ADD COLUMN gym_id BIGINT REFERENCES gyms(id) ON DELETE RESTRICT;
+UPDATE person_subscriptions ps SET gym_id = p.gym_id FROM person p WHERE p.id = ps.person_id;
+ALTER TABLE person_subscriptions ALTER COLUMN gym_id SET NOT NULL;
+
+CREATE INDEX person_gym_id_idx ON person (gym_id);
+CREATE INDEX person_subscriptions_gym_id_idx ON person_subscriptions (gym_id);
@@ -0,0 +1,8 @@
+CREATE TABLE gym_users (
+    user_id BIGINT PRIMARY KEY,
+    gym_id BIGINT NOT NULL REFERENCES gyms(id) ON DELETE CASCADE
+);
+
+INSERT INTO gym_users (user_id, gym_id) SELECT user_id, gym_id FROM staff;
+
+DROP TABLE IF EXISTS staff CASCADE;
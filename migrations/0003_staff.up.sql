@@ -0,0 +1,16 @@
+-- Сотрудники: пользователи SSO с ФИО, должностью и залом
+CREATE TABLE staff (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL UNIQUE,          -- ID пользователя в SSO
+    full_name TEXT NOT NULL,
+    position TEXT NOT NULL DEFAULT '',
+    gym_id BIGINT NOT NULL REFERENCES gyms(id) ON DELETE RESTRICT
+);
+
+-- Переносим существующие привязки пользователей к залам
+INSERT INTO staff (user_id, full_name, gym_id)
+SELECT user_id, 'user #' || user_id, gym_id FROM gym_users;
+
+DROP TABLE gym_users;
+
+CREATE INDEX staff_gym_id_idx ON staff (gym_id);
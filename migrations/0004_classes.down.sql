@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS class_bookings CASCADE;
+
+DROP TABLE IF EXISTS class_schedule CASCADE;
+
+DROP TABLE IF EXISTS class_types CASCADE;
+
+DROP TABLE IF EXISTS trainers CASCADE;
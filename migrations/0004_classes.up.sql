@@ -0,0 +1,42 @@
+-- Тренеры зала. Если тренер работает в приложении, он связан с сотрудником
+CREATE TABLE trainers (
+    id BIGSERIAL PRIMARY KEY,
+    gym_id BIGINT NOT NULL REFERENCES gyms(id) ON DELETE RESTRICT,
+    full_name TEXT NOT NULL,
+    phone VARCHAR(20) NOT NULL DEFAULT '',
+    staff_id BIGINT UNIQUE REFERENCES staff(id) ON DELETE SET NULL
+);
+
+-- Виды групповых занятий
+CREATE TABLE class_types (
+    id BIGSERIAL PRIMARY KEY,
+    gym_id BIGINT NOT NULL REFERENCES gyms(id) ON DELETE RESTRICT,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    duration_minutes INT NOT NULL,
+    UNIQUE (gym_id, title)
+);
+
+-- Еженедельное расписание занятий
+CREATE TABLE class_schedule (
+    id BIGSERIAL PRIMARY KEY,
+    gym_id BIGINT NOT NULL REFERENCES gyms(id) ON DELETE RESTRICT,
+    class_type_id BIGINT NOT NULL REFERENCES class_types(id) ON DELETE RESTRICT,
+    trainer_id BIGINT NOT NULL REFERENCES trainers(id) ON DELETE RESTRICT,
+    weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 1 AND 7), -- 1 - понедельник, 7 - воскресенье
+    start_time TIME NOT NULL,
+    capacity INT NOT NULL CHECK (capacity > 0)
+);
+
+-- Записи клиентов на занятия
+CREATE TABLE class_bookings (
+    id BIGSERIAL PRIMARY KEY,
+    schedule_id BIGINT NOT NULL REFERENCES class_schedule(id) ON DELETE CASCADE,
+    class_date DATE NOT NULL,
+    person_id BIGINT NOT NULL REFERENCES person(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'booked', -- booked / cancelled / attended
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (schedule_id, class_date, person_id)
+);
+
+CREATE INDEX class_bookings_date_idx ON class_bookings (class_date);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS training_sessions CASCADE;
+
+DROP TABLE IF EXISTS person_training_packages CASCADE;
+
+DROP TABLE IF EXISTS training_packages CASCADE;
@@ -0,0 +1,36 @@
+-- Пакеты персональных тренировок (товар, аналог тарифа абонемента)
+CREATE TABLE training_packages (
+    id BIGSERIAL PRIMARY KEY,
+    gym_id BIGINT NOT NULL REFERENCES gyms(id) ON DELETE RESTRICT,
+    title TEXT NOT NULL,
+    sessions INT NOT NULL CHECK (sessions > 0),           -- Количество тренировок
+    session_minutes INT NOT NULL DEFAULT 60,              -- Длительность тренировки
+    validity_days INT NOT NULL CHECK (validity_days > 0), -- Срок действия в днях
+    price NUMERIC(10, 2) NOT NULL
+);
+
+-- Пакеты, проданные клиентам
+CREATE TABLE person_training_packages (
+    id BIGSERIAL PRIMARY KEY,
+    gym_id BIGINT NOT NULL REFERENCES gyms(id) ON DELETE RESTRICT,
+    person_id BIGINT NOT NULL REFERENCES person(id) ON DELETE CASCADE,
+    package_id BIGINT NOT NULL REFERENCES training_packages(id) ON DELETE RESTRICT,
+    trainer_id BIGINT NOT NULL REFERENCES trainers(id) ON DELETE RESTRICT,
+    sessions_total INT NOT NULL,
+    sessions_used INT NOT NULL DEFAULT 0,
+    start_date DATE NOT NULL,
+    expiry_date DATE NOT NULL,
+    CHECK (sessions_used <= sessions_total)
+);
+
+-- Персональные тренировки
+CREATE TABLE training_sessions (
+    id BIGSERIAL PRIMARY KEY,
+    person_package_id BIGINT NOT NULL REFERENCES person_training_packages(id) ON DELETE CASCADE,
+    scheduled_at TIMESTAMPTZ NOT NULL,
+    duration_minutes INT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'booked' -- booked / cancelled / completed
+);
+
+CREATE INDEX person_training_packages_person_idx ON person_training_packages (person_id);
+CREATE INDEX training_sessions_scheduled_at_idx ON training_sessions (scheduled_at);
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS person_subscriptions_number_not_revoked ON person_subscriptions;
+
+DROP FUNCTION IF EXISTS check_card_number_not_revoked();
+
+DROP TABLE IF EXISTS card_number_history CASCADE;
@@ -0,0 +1,29 @@
+-- История номеров карт: отозванный номер указывает на текущий номер абонемента
+CREATE TABLE card_number_history (
+    number VARCHAR(32) PRIMARY KEY,                 -- Отозванный номер
+    current_number VARCHAR(32) NOT NULL
+        REFERENCES person_subscriptions(number) ON UPDATE CASCADE ON DELETE CASCADE,
+    gym_id BIGINT NOT NULL REFERENCES gyms(id) ON DELETE RESTRICT,
+    reason TEXT NOT NULL DEFAULT '',                -- Причина перевыпуска (утеря, порча)
+    revoked_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- Перевыпуск с тем же номером отозвал бы действующую карту
+    CONSTRAINT card_number_history_new_number_check CHECK (number <> current_number)
+);
+
+CREATE INDEX card_number_history_current_number_idx ON card_number_history (current_number);
+
+-- Отозванный номер нельзя выдать повторно
+CREATE FUNCTION check_card_number_not_revoked() RETURNS trigger AS $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM card_number_history WHERE number = NEW.number) THEN
+        RAISE EXCEPTION 'card number % has been revoked', NEW.number
+            USING ERRCODE = 'unique_violation';
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER person_subscriptions_number_not_revoked
+    BEFORE INSERT OR UPDATE OF number ON person_subscriptions
+    FOR EACH ROW EXECUTE FUNCTION check_card_number_not_revoked();
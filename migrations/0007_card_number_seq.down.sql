@@ -0,0 +1 @@
+DROP SEQUENCE IF EXISTS card_number_seq;
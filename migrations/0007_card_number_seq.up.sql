@@ -0,0 +1,2 @@
+-- Последовательность для автоматической генерации номеров карт
+CREATE SEQUENCE card_number_seq START 1;
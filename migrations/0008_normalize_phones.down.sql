@@ -0,0 +1,2 @@
+-- Исходный формат телефонов не сохраняется, откат не требуется
+SELECT 1;
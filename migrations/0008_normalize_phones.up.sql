@@ -0,0 +1,12 @@
+-- Приводим телефоны к формату 7XXXXXXXXXX, как это делает API
+UPDATE person p
+SET phone = '7' || substr(p.phone, 2)
+WHERE p.phone ~ '^8[0-9]{10}$'
+  AND NOT EXISTS (
+      SELECT 1 FROM person d
+      WHERE d.gym_id = p.gym_id AND d.full_name = p.full_name AND d.phone = '7' || substr(p.phone, 2)
+  );
+
+UPDATE trainers
+SET phone = '7' || substr(phone, 2)
+WHERE phone ~ '^8[0-9]{10}$';
@@ -0,0 +1,10 @@
+// Package migrations embeds the SQL migrations so that they ship with the
+// binaries.
+package migrations
+
+import "embed"
+
+// FS holds the NNNN_name.up.sql and NNNN_name.down.sql files.
+//
+//go:embed *.sql
+var FS embed.FS